@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"errors"
+	"grf/core/exceptions"
+	"grf/domain/auth/model"
+	"grf/domain/auth/repository"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// APIKeyLocal holds the *model.APIKey that authenticated the request.
+const APIKeyLocal = "api_key"
+
+// ScopesLocal holds the scopes the request credentials are limited to.
+// Credentials that don't set it, like a user's own JWT, aren't limited.
+// Only permission.HasScope reads the scopes; permission.IsAdmin and
+// permission.IsFirstParty refuse scoped credentials altogether.
+const ScopesLocal = "scopes"
+
+type APIKeyAuthBackend struct {
+	Repo *repository.APIKeyRepository
+}
+
+func NewAPIKeyAuthBackend(db *gorm.DB) *APIKeyAuthBackend {
+	return &APIKeyAuthBackend{Repo: repository.NewAPIKeyRepository(db)}
+}
+
+// Authenticate reads "Authorization: Api-Key <prefix>.<secret>".
+func (b *APIKeyAuthBackend) Authenticate(c *fiber.Ctx) (*model.User, error) {
+	authHeader := c.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Api-Key ") {
+		return nil, ErrCannotAuthenticate
+	}
+
+	prefix, secret, ok := strings.Cut(strings.TrimSpace(authHeader[8:]), ".")
+	if !ok || prefix == "" || secret == "" {
+		return nil, exceptions.NewUnauthorized("invalid_api_key", nil)
+	}
+	key, err := b.Repo.FindByPrefix(prefix)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, exceptions.NewUnauthorized("invalid_api_key", nil)
+		}
+		return nil, exceptions.NewInternal(err)
+	}
+	// A soft-deleted owner isn't preloaded.
+	if key.User == nil || !key.CheckSecret(secret) {
+		return nil, exceptions.NewUnauthorized("invalid_api_key", nil)
+	}
+
+	now := time.Now()
+	if key.RevokedAt != nil {
+		return nil, exceptions.NewUnauthorized("api_key_revoked", nil)
+	}
+	if key.Expired(now) {
+		return nil, exceptions.NewUnauthorized("api_key_expired", nil)
+	}
+	if err := b.Repo.TouchLastUsed(key, now); err != nil {
+		return nil, exceptions.NewInternal(err)
+	}
+
+	c.Locals(APIKeyLocal, key)
+	c.Locals(ScopesLocal, key.ScopeList())
+	return key.User, nil
+}
@@ -3,6 +3,7 @@ package auth
 import (
 	"grf/core/config"
 	"grf/core/exceptions"
+	"grf/core/keyring"
 	"grf/domain/auth/model"
 	"grf/domain/auth/service"
 	"strings"
@@ -15,9 +16,9 @@ type JWTAuthBackend struct {
 	TokenService *service.TokenService
 }
 
-func NewJWTAuthBackend(db *gorm.DB, config *config.Config) *JWTAuthBackend {
+func NewJWTAuthBackend(db *gorm.DB, config *config.Config, keys *keyring.Keyring) *JWTAuthBackend {
 	return &JWTAuthBackend{
-		TokenService: service.NewTokenService(db, config),
+		TokenService: service.NewTokenService(db, config, keys),
 	}
 }
 
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"errors"
+	"grf/core/config"
+	"grf/core/exceptions"
+	"grf/domain/auth/model"
+	oauth2model "grf/domain/oauth2/model"
+	"grf/domain/oauth2/service"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// OAuth2TokenLocal holds the *oauth2model.Token that authenticated the
+// request.
+const OAuth2TokenLocal = "oauth2_token"
+
+// OAuth2AuthBackend authenticates the bearer access tokens issued by the
+// OAuth2 server. They are told apart from JWTs by their prefix, so it must
+// come before JWTAuthBackend, which rejects any bearer token it can't parse.
+type OAuth2AuthBackend struct {
+	Service *service.OAuth2Service
+}
+
+func NewOAuth2AuthBackend(db *gorm.DB, config *config.Config) *OAuth2AuthBackend {
+	return &OAuth2AuthBackend{Service: service.NewOAuth2Service(db, config)}
+}
+
+func (b *OAuth2AuthBackend) Authenticate(c *fiber.Ctx) (*model.User, error) {
+	accessToken, ok := strings.CutPrefix(c.Get("Authorization"), "Bearer ")
+	if !ok || !strings.HasPrefix(accessToken, oauth2model.AccessTokenPrefix) {
+		return nil, ErrCannotAuthenticate
+	}
+
+	token, err := b.Service.ValidateAccessToken(accessToken)
+	if err != nil {
+		if errors.Is(err, service.ErrTokenInvalid) {
+			return nil, exceptions.NewUnauthorized("invalid_oauth2_token", err)
+		}
+		return nil, exceptions.NewInternal(err)
+	}
+
+	c.Locals(OAuth2TokenLocal, token)
+	c.Locals(ScopesLocal, token.ScopeList())
+	return token.User, nil
+}
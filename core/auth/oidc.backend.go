@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"errors"
+	"grf/core/config"
+	"grf/core/exceptions"
+	"grf/core/keyring"
+	"grf/domain/auth/model"
+	"grf/domain/auth/service"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// OIDCAuthBackend authenticates the bearer JWTs of an external OpenID
+// Connect provider. It only claims tokens whose iss is the provider, so it
+// must come before JWTAuthBackend, which rejects any bearer token it can't
+// verify.
+type OIDCAuthBackend struct {
+	Service *service.OIDCService
+}
+
+func NewOIDCAuthBackend(db *gorm.DB, config *config.Config, keys *keyring.JWKSource) *OIDCAuthBackend {
+	return &OIDCAuthBackend{Service: service.NewOIDCService(db, config, keys)}
+}
+
+func (b *OIDCAuthBackend) Authenticate(c *fiber.Ctx) (*model.User, error) {
+	tokenString, ok := strings.CutPrefix(c.Get("Authorization"), "Bearer ")
+	if !ok || !b.Service.Issues(tokenString) {
+		return nil, ErrCannotAuthenticate
+	}
+
+	user, err := b.Service.Authenticate(tokenString)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOIDCTokenInvalid):
+			return nil, exceptions.NewUnauthorized("invalid_oidc_token", err)
+		case errors.Is(err, service.ErrOIDCUserNotFound):
+			return nil, exceptions.NewUnauthorized("oidc_user_not_found", err)
+		}
+		return nil, exceptions.NewInternal(err)
+	}
+	return user, nil
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"errors"
+	"grf/core/config"
+	"grf/core/exceptions"
+	"grf/domain/auth/model"
+	"grf/domain/auth/service"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// SessionLocal holds the *model.Session that authenticated the request.
+const SessionLocal = "session"
+
+// SessionAuthBackend authenticates the session cookie. A cookie is sent by
+// the browser on its own, so unsafe methods must also carry the session's
+// CSRF token in a header; other backends are not subject to the check.
+type SessionAuthBackend struct {
+	Sessions   *service.SessionService
+	CookieName string
+	CSRFHeader string
+}
+
+func NewSessionAuthBackend(db *gorm.DB, config *config.Config) *SessionAuthBackend {
+	return &SessionAuthBackend{
+		Sessions:   service.NewSessionService(db, config),
+		CookieName: config.SessionCookieName,
+		CSRFHeader: config.CSRFHeaderName,
+	}
+}
+
+func (b *SessionAuthBackend) Authenticate(c *fiber.Ctx) (*model.User, error) {
+	key := c.Cookies(b.CookieName)
+	if key == "" {
+		return nil, ErrCannotAuthenticate
+	}
+
+	session, err := b.Sessions.Validate(key)
+	if err != nil {
+		if errors.Is(err, service.ErrSessionInvalid) {
+			return nil, exceptions.NewUnauthorized("invalid_session", err)
+		}
+		return nil, exceptions.NewInternal(err)
+	}
+	if !csrfSafeMethod(c.Method()) && !session.CheckCSRF(c.Get(b.CSRFHeader)) {
+		return nil, exceptions.NewForbidden("csrf_failed", nil)
+	}
+
+	c.Locals(SessionLocal, session)
+	return session.User, nil
+}
+
+// csrfSafeMethod lists the methods RFC 9110 defines as safe.
+func csrfSafeMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
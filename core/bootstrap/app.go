@@ -1,12 +1,16 @@
 package bootstrap
 
 import (
+	"errors"
 	"grf/core/auth"
 	"grf/core/config"
 	"grf/core/database"
 	"grf/core/exceptions"
 	"grf/core/i18n"
+	"grf/core/keyring"
 	"grf/core/middleware"
+	"grf/core/migrations"
+	coremodels "grf/core/models"
 	"grf/core/permission"
 	"grf/core/routes"
 	"grf/core/server"
@@ -18,11 +22,17 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/logger"
 )
 
-func NewApp(cfg config.Config, models []interface{}) (*server.App, error) {
+// NewApp builds the application on the database of cfg. userRevokers are the
+// UserRevoker of the registered modules.
+func NewApp(cfg config.Config, models []interface{}, migrationList []*migrations.Migration, userRevokers ...coremodels.UserRevoker) (*server.App, error) {
 	db, err := database.ConnectDB(&cfg)
 	if err != nil {
 		return nil, err
 	}
+	keys, err := keyring.FromConfig(&cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	app := fiber.New(fiber.Config{
 		AppName:      cfg.AppName,
@@ -36,44 +46,91 @@ func NewApp(cfg config.Config, models []interface{}) (*server.App, error) {
 
 	app.Use(logger.New())
 
-	jwtBackend := auth.NewJWTAuthBackend(db, &cfg)
+	oauth2Backend := auth.NewOAuth2AuthBackend(db, &cfg)
+	jwtBackend := auth.NewJWTAuthBackend(db, &cfg, keys)
+	apiKeyBackend := auth.NewAPIKeyAuthBackend(db)
 	basicBackend := auth.NewBasicAuthBackend(db)
+	sessionBackend := auth.NewSessionAuthBackend(db, &cfg)
 
 	i18nMw := middleware.NewI18NMiddleware(i18n.NewI18nService())
 
-	isAuthenticated := permission.NewIsAuthenticated(jwtBackend, basicBackend)
+	// Backends claiming bearer tokens by their issuer or prefix come before
+	// the JWT one. The session cookie comes last, so an explicit
+	// Authorization header wins over a cookie the browser sent along.
+	backends := []auth.IAuthBackend{oauth2Backend}
+	if cfg.OIDCIssuer != "" {
+		if cfg.OIDCAudience == "" {
+			return nil, errors.New("OIDC_ISSUER needs OIDC_AUDIENCE")
+		}
+		oidcKeys, err := keyring.JWKSourceFromConfig(&cfg)
+		if err != nil {
+			return nil, err
+		}
+		backends = append(backends, auth.NewOIDCAuthBackend(db, &cfg, oidcKeys))
+	}
+	backends = append(backends, jwtBackend, apiKeyBackend, basicBackend, sessionBackend)
+	isAuthenticated := permission.NewIsAuthenticated(backends...)
+	permissionResolver := permission.NewResolver(db, permission.NewLRUCache(
+		cfg.PermissionCacheSize,
+		time.Duration(cfg.PermissionCacheTTLSeconds)*time.Second,
+	))
 
 	var bootstrapedApp = &server.App{
 		FiberApp:  app,
 		DB:        db,
 		Validator: validator.GetValidator(),
+		Keys:      keys,
 		I18nMw:    i18nMw,
 		Config:    &cfg,
 		Models:    models,
 
+		Migrations: migrationList,
+
+		UserRevokers: userRevokers,
+
 		AllowAny:        &permission.AllowAny{},
 		IsAuthenticated: isAuthenticated,
 		IsAdmin:         &permission.IsAdmin{},
+		IsFirstParty:    &permission.IsFirstParty{},
 		IsAuthenticatedOrReadOnly: permission.NewOr(
 			&permission.IsReadOnly{},
 			isAuthenticated,
 		),
+
+		PermissionResolver: permissionResolver,
 	}
 
 	i18nMw.UseMiddleWare(
 		app,
 	)
 	database.RegisterMigrations(&database.MigrationOptions{
-		DB:     db,
-		Config: &cfg,
-		Models: models,
-	})
-	permission.RegisterPermissions(&permission.Options{
-		DB:     db,
-		Models: models,
+		DB:         db,
+		Config:     &cfg,
+		Models:     models,
+		Migrations: migrationList,
 	})
 	routes.RegisterRoutes(
 		bootstrapedApp,
 	)
+	if cfg.PermissionSync {
+		permission.RegisterPermissions(PermissionOptions(bootstrapedApp, cfg.PermissionPrune, false))
+	}
 	return bootstrapedApp, nil
 }
+
+// PermissionOptions describes the permissions declared by the models and
+// routes of app, for permission.SyncPermissions.
+func PermissionOptions(app *server.App, prune bool, dryRun bool) *permission.Options {
+	return &permission.Options{
+		DB:      app.DB,
+		Models:  app.Models,
+		Actions: app.Actions,
+
+		ModelActions: app.ModelActions,
+
+		Prune:  prune,
+		DryRun: dryRun,
+
+		Resolver: app.PermissionResolver,
+	}
+}
@@ -4,6 +4,14 @@ import (
 	"github.com/spf13/viper"
 )
 
+// DefaultJWTSecret is the development fallback for JWT_SECRET. The check
+// command reports it outside of development.
+const DefaultJWTSecret = "my_super_secret_key_insecure_do_not_use_it"
+
+// DefaultPasswordHashCost is the bcrypt cost used unless PASSWORD_HASH_COST
+// says otherwise.
+const DefaultPasswordHashCost = 12
+
 type Config struct {
 	AppName string `mapstructure:"APP_NAME"`
 
@@ -13,8 +21,10 @@ type Config struct {
 	DBUser               string `mapstructure:"DB_USER"`
 	DBPassword           string `mapstructure:"DB_PASSWORD"`
 	DBName               string `mapstructure:"DB_NAME"`
+	DBSSLMode            string `mapstructure:"DB_SSL_MODE"`
 	DBLogLevel           string `mapstructure:"DB_LOG_LEVEL"`
 	DBMigrate            bool   `mapstructure:"DB_MIGRATE"`
+	DBAutoMigrate        bool   `mapstructure:"DB_AUTO_MIGRATE"`
 	DBMaxIdle            int    `mapstructure:"DB_MAX_IDLE"`
 	DBMaxOpened          int    `mapstructure:"DB_MAX_OPENED"`
 	DBMaxLifeTimeSeconds uint   `mapstructure:"DB_MAX_LIFE_TIME_SECONDS"`
@@ -29,6 +39,51 @@ type Config struct {
 	JWTSecret               string `mapstructure:"JWT_SECRET"`
 	JWTExpiresInMinutes     int    `mapstructure:"JWT_EXPIRES_IN_MINUTES"`
 	JWTRefreshExpiresInDays int    `mapstructure:"JWT_REFRESH_EXPIRES_IN_DAYS"`
+	// JWTPrivateKeyFile switches signing from HS256 with JWTSecret to the
+	// PEM key's algorithm. JWTPublicKeyFiles are comma-separated PEM files of
+	// retired keys still accepted while their tokens expire.
+	JWTPrivateKeyFile string   `mapstructure:"JWT_PRIVATE_KEY_FILE"`
+	JWTPublicKeyFiles []string `mapstructure:"JWT_PUBLIC_KEY_FILES"`
+
+	// Session cookies authenticate the browser front-end. SameSite is Lax,
+	// Strict or None; None needs SESSION_COOKIE_SECURE.
+	SessionCookieName     string `mapstructure:"SESSION_COOKIE_NAME"`
+	SessionCookieAge      int    `mapstructure:"SESSION_COOKIE_AGE"`
+	SessionCookieSecure   bool   `mapstructure:"SESSION_COOKIE_SECURE"`
+	SessionCookieSameSite string `mapstructure:"SESSION_COOKIE_SAMESITE"`
+	CSRFCookieName        string `mapstructure:"CSRF_COOKIE_NAME"`
+	CSRFHeaderName        string `mapstructure:"CSRF_HEADER_NAME"`
+
+	// Lifetimes of what the OAuth2 server issues.
+	OAuth2CodeSeconds        int `mapstructure:"OAUTH2_CODE_SECONDS"`
+	OAuth2AccessTokenSeconds int `mapstructure:"OAUTH2_ACCESS_TOKEN_SECONDS"`
+	OAuth2RefreshTokenDays   int `mapstructure:"OAUTH2_REFRESH_TOKEN_DAYS"`
+
+	// OIDCIssuer enables JWTs of an external OpenID Connect provider, checked
+	// against OIDCAudience, which is then required, and the keys at
+	// OIDCJWKSURL or in OIDCJWKSFile. Users are matched by the token's sub,
+	// linked when OIDCCreateUsers creates them on their first request, named
+	// after OIDCUsernameClaim, or with the linkoidc command. With
+	// OIDCGroupsClaim set, the claim adds them to existing groups and removes
+	// them from those it added before.
+	OIDCIssuer           string `mapstructure:"OIDC_ISSUER"`
+	OIDCAudience         string `mapstructure:"OIDC_AUDIENCE"`
+	OIDCJWKSURL          string `mapstructure:"OIDC_JWKS_URL"`
+	OIDCJWKSFile         string `mapstructure:"OIDC_JWKS_FILE"`
+	OIDCJWKSCacheSeconds int    `mapstructure:"OIDC_JWKS_CACHE_SECONDS"`
+	OIDCUsernameClaim    string `mapstructure:"OIDC_USERNAME_CLAIM"`
+	OIDCCreateUsers      bool   `mapstructure:"OIDC_CREATE_USERS"`
+	OIDCGroupsClaim      string `mapstructure:"OIDC_GROUPS_CLAIM"`
+
+	// PasswordHashCost is the bcrypt cost of new password hashes. Tests
+	// lower it to bcrypt.MinCost; the check command reports anything below
+	// the default outside of development.
+	PasswordHashCost int `mapstructure:"PASSWORD_HASH_COST"`
+
+	PermissionSync            bool `mapstructure:"PERMISSION_SYNC"`
+	PermissionPrune           bool `mapstructure:"PERMISSION_PRUNE"`
+	PermissionCacheSize       int  `mapstructure:"PERMISSION_CACHE_SIZE"`
+	PermissionCacheTTLSeconds int  `mapstructure:"PERMISSION_CACHE_TTL_SECONDS"`
 }
 
 func LoadConfig(path string, configName string) (config Config, err error) {
@@ -36,8 +91,10 @@ func LoadConfig(path string, configName string) (config Config, err error) {
 	viper.SetDefault("AppName", "GRF")
 	viper.SetDefault("DB_VENDOR", "sqlite")
 	viper.SetDefault("DB_NAME", "grf")
+	viper.SetDefault("DB_SSL_MODE", "disable")
 	viper.SetDefault("DB_LOG_LEVEL", "info")
 	viper.SetDefault("DB_MIGRATE", true)
+	viper.SetDefault("DB_AUTO_MIGRATE", false)
 	viper.SetDefault("DB_MAX_IDLE", 10)
 	viper.SetDefault("DB_MAX_OPENED", 25)
 	viper.SetDefault("DB_MAX_LIFE_TIME_SECONDS", 60)
@@ -49,10 +106,38 @@ func LoadConfig(path string, configName string) (config Config, err error) {
 	viper.SetDefault("SERVER_READ_TIMEOUT", "3")
 	viper.SetDefault("SERVER_WRITE_TIMEOUT", "3")
 
-	viper.SetDefault("JWT_SECRET", "my_super_secret_key_insecure_do_not_use_it")
+	viper.SetDefault("JWT_SECRET", DefaultJWTSecret)
 	viper.SetDefault("JWT_EXPIRES_IN_MINUTES", 60*24)
 	viper.SetDefault("JWT_REFRESH_EXPIRES_IN_DAYS", 30)
 
+	viper.SetDefault("SESSION_COOKIE_NAME", "sessionid")
+	viper.SetDefault("SESSION_COOKIE_AGE", 60*60*24*14)
+	viper.SetDefault("SESSION_COOKIE_SECURE", false)
+	viper.SetDefault("SESSION_COOKIE_SAMESITE", "Lax")
+	viper.SetDefault("CSRF_COOKIE_NAME", "csrftoken")
+	viper.SetDefault("CSRF_HEADER_NAME", "X-CSRFToken")
+
+	viper.SetDefault("OAUTH2_CODE_SECONDS", 60)
+	viper.SetDefault("OAUTH2_ACCESS_TOKEN_SECONDS", 60*60)
+	viper.SetDefault("OAUTH2_REFRESH_TOKEN_DAYS", 30)
+
+	// Unset keys without a default aren't read from the environment.
+	viper.SetDefault("OIDC_ISSUER", "")
+	viper.SetDefault("OIDC_AUDIENCE", "")
+	viper.SetDefault("OIDC_JWKS_URL", "")
+	viper.SetDefault("OIDC_JWKS_FILE", "")
+	viper.SetDefault("OIDC_JWKS_CACHE_SECONDS", 60*5)
+	viper.SetDefault("OIDC_USERNAME_CLAIM", "preferred_username")
+	viper.SetDefault("OIDC_CREATE_USERS", false)
+	viper.SetDefault("OIDC_GROUPS_CLAIM", "")
+
+	viper.SetDefault("PASSWORD_HASH_COST", DefaultPasswordHashCost)
+
+	viper.SetDefault("PERMISSION_SYNC", true)
+	viper.SetDefault("PERMISSION_PRUNE", false)
+	viper.SetDefault("PERMISSION_CACHE_SIZE", 1024)
+	viper.SetDefault("PERMISSION_CACHE_TTL_SECONDS", 60)
+
 	viper.AddConfigPath(path)
 	viper.SetConfigType("env")
 	viper.SetConfigName(configName)
@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// Action is an extra route mounted next to the CRUD ones. Detail actions live
+// under "/:id", list actions under the collection. Codename is the permission
+// checked for it, e.g. "user.set_password"; its module must match the model's.
+type Action struct {
+	Method      string
+	Path        string
+	Detail      bool
+	Codename    string
+	Description string
+	Handler     fiber.Handler
+}
+
+type IActionController interface {
+	Actions() []Action
+}
+
+func (h *GenericController[T, C, U, P, R, F, ID]) Actions() []Action {
+	return h.ExtraActions
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"grf/core/exceptions"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+type IBulkController interface {
+	BulkCreate(c *fiber.Ctx) error
+	BulkPartialUpdate(c *fiber.Ctx) error
+	BulkDelete(c *fiber.Ctx) error
+}
+
+const BulkIDsParam = "id__in"
+
+func (h *GenericController[T, C, U, P, R, F, ID]) BulkCreate(c *fiber.Ctx) error {
+	selection, err := ParseFieldSelection[R](c)
+	if err != nil {
+		return err
+	}
+
+	var inputs []C
+	if err := json.Unmarshal(c.Body(), &inputs); err != nil {
+		return exceptions.NewBadRequest("invalid_payload", err)
+	}
+	if len(inputs) == 0 {
+		return exceptions.NewBadRequest("empty_bulk_payload", nil)
+	}
+
+	itemErrors := make(map[int]error)
+	for i, input := range inputs {
+		if err := h.Validator.Struct(input); err != nil {
+			itemErrors[i] = err
+		}
+	}
+	if len(itemErrors) > 0 {
+		return exceptions.NewBulkError(fiber.StatusUnprocessableEntity, itemErrors)
+	}
+
+	records, err := h.Service.BulkCreate(inputs)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(h.renderMany(selection, records))
+}
+
+func (h *GenericController[T, C, U, P, R, F, ID]) BulkPartialUpdate(c *fiber.Ctx) error {
+	selection, err := ParseFieldSelection[R](c)
+	if err != nil {
+		return err
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(c.Body(), &items); err != nil {
+		return exceptions.NewBadRequest("invalid_payload", err)
+	}
+	if len(items) == 0 {
+		return exceptions.NewBadRequest("empty_bulk_payload", nil)
+	}
+
+	ids := make([]ID, len(items))
+	inputs := make([]P, len(items))
+	itemErrors := make(map[int]error)
+	for i, item := range items {
+		var ref struct {
+			ID *ID `json:"id"`
+		}
+		if err := json.Unmarshal(item, &ref); err != nil || ref.ID == nil {
+			itemErrors[i] = exceptions.NewBadRequest("id_required", err)
+			continue
+		}
+		ids[i] = *ref.ID
+
+		inputs[i] = h.NewPatchDTO()
+		if err := json.Unmarshal(item, inputs[i]); err != nil {
+			itemErrors[i] = exceptions.NewBadRequest("invalid_payload", err)
+			continue
+		}
+		if err := h.Validator.Struct(inputs[i]); err != nil {
+			itemErrors[i] = err
+		}
+	}
+	if len(itemErrors) > 0 {
+		return exceptions.NewBulkError(fiber.StatusUnprocessableEntity, itemErrors)
+	}
+
+	records, err := h.service(c, nil).BulkPartialUpdate(ids, inputs)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(h.renderMany(selection, records))
+}
+
+func (h *GenericController[T, C, U, P, R, F, ID]) BulkDelete(c *fiber.Ctx) error {
+	raw := splitParam(c.Query(BulkIDsParam))
+	if len(raw) == 0 {
+		return exceptions.NewBadRequest("id_required", nil)
+	}
+
+	ids := make([]ID, len(raw))
+	for i, value := range raw {
+		id, err := h.ParseID(value)
+		if err != nil {
+			return exceptions.NewBulkError(fiber.StatusBadRequest, map[int]error{
+				i: exceptions.NewBadRequest("id_required", err),
+			})
+		}
+		ids[i] = id
+	}
+
+	if err := h.service(c, nil).BulkDelete(ids); err != nil {
+		return err
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *GenericController[T, C, U, P, R, F, ID]) renderMany(selection *FieldSelection, records []T) []interface{} {
+	responses := make([]interface{}, len(records))
+	for i, record := range records {
+		responses[i] = selection.Render(h.MapToResponse(record))
+	}
+	return responses
+}
@@ -7,6 +7,7 @@ import (
 	"grf/core/filterset"
 	"grf/core/models"
 	"grf/core/pagination"
+	"grf/core/permission"
 	"grf/core/service"
 
 	"github.com/go-playground/validator/v10"
@@ -26,25 +27,39 @@ type GenericController[T models.IModel, C any, U any, P dto.IPatchDTO, R any, F
 	Service   service.IService[T, C, U, P, R, F, ID]
 	Validator *validator.Validate
 	Paginator pagination.IPagination[T]
+	Ordering  *filterset.OrderingFilter
+
+	Expandable     map[string]string
+	MaxExpandDepth int
 
 	MapToResponse func(model T) R
 
-	NewFilterSet func() F
-	NewPatchDTO  func() P
+	NewFilterSet    func() F
+	NewSearchFilter func() filterset.IFilterSet
+	NewPatchDTO     func() P
 
 	ParseID func(s string) (ID, error)
+
+	ExtraActions []Action
 }
 
 type Config[T models.IModel, C any, U any, P dto.IPatchDTO, R any, F filterset.IFilterSet, ID comparable] struct {
 	Service   service.IService[T, C, U, P, R, F, ID]
 	Validator *validator.Validate
 	Paginator pagination.IPagination[T]
+	Ordering  *filterset.OrderingFilter
+
+	Expandable     map[string]string
+	MaxExpandDepth int
 
 	MapToResponse func(model T) R
 
-	NewFilterSet func() F
-	NewPatchDTO  func() P
-	ParseID      func(s string) (ID, error)
+	NewFilterSet    func() F
+	NewSearchFilter func() filterset.IFilterSet
+	NewPatchDTO     func() P
+	ParseID         func(s string) (ID, error)
+
+	ExtraActions []Action
 }
 
 func NewGenericController[T models.IModel, C any, U any, P dto.IPatchDTO, R any, F filterset.IFilterSet, ID comparable](
@@ -55,47 +70,91 @@ func NewGenericController[T models.IModel, C any, U any, P dto.IPatchDTO, R any,
 		panic("GenericController: Service, Validator, ParseID e MapToResponse are required")
 	}
 
+	maxExpandDepth := config.MaxExpandDepth
+	if maxExpandDepth <= 0 {
+		maxExpandDepth = DefaultMaxExpandDepth
+	}
+
 	return &GenericController[T, C, U, P, R, F, ID]{
-		Service:       config.Service,
-		Validator:     config.Validator,
-		Paginator:     config.Paginator,
-		MapToResponse: config.MapToResponse,
-		NewFilterSet:  config.NewFilterSet,
-		NewPatchDTO:   config.NewPatchDTO,
-		ParseID:       config.ParseID,
+		Service:         config.Service,
+		Validator:       config.Validator,
+		Paginator:       config.Paginator,
+		Ordering:        config.Ordering,
+		Expandable:      config.Expandable,
+		MaxExpandDepth:  maxExpandDepth,
+		MapToResponse:   config.MapToResponse,
+		NewFilterSet:    config.NewFilterSet,
+		NewSearchFilter: config.NewSearchFilter,
+		NewPatchDTO:     config.NewPatchDTO,
+		ParseID:         config.ParseID,
+		ExtraActions:    config.ExtraActions,
 	}
 }
 
 func (h *GenericController[T, C, U, P, R, F, ID]) List(c *fiber.Ctx) error {
-	filters := h.NewFilterSet()
+	selection, err := ParseFieldSelection[R](c)
+	if err != nil {
+		return err
+	}
+
+	preloads, err := parseExpand(c, h.Expandable, h.MaxExpandDepth)
+	if err != nil {
+		return err
+	}
+
+	var filters filterset.IFilterSet = h.NewFilterSet()
+	if h.NewSearchFilter != nil {
+		filters = filterset.Chain(filters, h.NewSearchFilter())
+	}
+	if len(selection.Fields) > 0 {
+		filters = filterset.Chain(filters, filterset.SelectColumns(selection.Fields...))
+	}
 	if err := filters.Bind(c); err != nil {
+		var appErr *exceptions.AppError
+		if errors.As(err, &appErr) {
+			return appErr
+		}
 		return exceptions.NewBadRequest("invalid_query_params", err)
 	}
 	if h.Paginator == nil {
 		return exceptions.NewInternal(errors.New("paginator_required"))
 	}
-	if err := h.Paginator.Bind(c); err != nil {
+	paginator := h.Paginator.Clone()
+	if err := paginator.Bind(c); err != nil {
 		return exceptions.NewBadRequest("invalid_pagination_params", err)
 	}
+	if h.Ordering != nil {
+		ordering, err := h.Ordering.Bind(c)
+		if err != nil {
+			return err
+		}
+		orderedPaginator, ok := paginator.(pagination.IOrderedPagination)
+		if !ok {
+			return exceptions.NewInternal(errors.New("paginator_does_not_support_ordering"))
+		}
+		orderedPaginator.SetOrdering(ordering)
+	}
 
-	paginatedResponse, err := h.Service.List(filters, h.Paginator)
+	paginatedResponse, err := h.service(c, preloads).List(filters, paginator)
 	if err != nil {
 		return err
 	}
 
-	responseDTOs := make([]R, len(paginatedResponse.Results))
-	for i, item := range paginatedResponse.Results {
-		responseDTOs[i] = h.MapToResponse(item)
-	}
-	finalResponse := pagination.Response[R]{
-		Results: responseDTOs,
-		HasNext: paginatedResponse.HasNext,
-		Count:   paginatedResponse.Count,
+	finalResponse := pagination.Response[interface{}]{
+		Results:    h.renderMany(selection, paginatedResponse.Results),
+		HasNext:    paginatedResponse.HasNext,
+		Count:      paginatedResponse.Count,
+		NextCursor: paginatedResponse.NextCursor,
 	}
 	return c.JSON(finalResponse)
 }
 
 func (h *GenericController[T, C, U, P, R, F, ID]) Create(c *fiber.Ctx) error {
+	selection, err := ParseFieldSelection[R](c)
+	if err != nil {
+		return err
+	}
+
 	var input C
 	if err := c.BodyParser(&input); err != nil {
 		return exceptions.NewBadRequest("invalid_payload", err)
@@ -110,25 +169,43 @@ func (h *GenericController[T, C, U, P, R, F, ID]) Create(c *fiber.Ctx) error {
 	}
 
 	response := h.MapToResponse(newRecord)
-	return c.Status(fiber.StatusCreated).JSON(response)
+	return c.Status(fiber.StatusCreated).JSON(selection.Render(response))
 }
 
 func (h *GenericController[T, C, U, P, R, F, ID]) Retrieve(c *fiber.Ctx) error {
+	selection, err := ParseFieldSelection[R](c)
+	if err != nil {
+		return err
+	}
+
+	preloads, err := parseExpand(c, h.Expandable, h.MaxExpandDepth)
+	if err != nil {
+		return err
+	}
+
 	id, err := h.ParseID(c.Params("id"))
 	if err != nil {
 		return exceptions.NewBadRequest("id_required", err)
 	}
 
-	record, err := h.Service.GetByID(id)
+	record, err := h.service(c, preloads).GetByID(id)
 	if err != nil {
 		return err
 	}
+	if err := permission.CheckObject(c, record); err != nil {
+		return err
+	}
 
 	response := h.MapToResponse(record)
-	return c.JSON(response)
+	return c.JSON(selection.Render(response))
 }
 
 func (h *GenericController[T, C, U, P, R, F, ID]) Update(c *fiber.Ctx) error {
+	selection, err := ParseFieldSelection[R](c)
+	if err != nil {
+		return err
+	}
+
 	id, err := h.ParseID(c.Params("id"))
 	if err != nil {
 		return exceptions.NewBadRequest("id_required", err)
@@ -142,16 +219,25 @@ func (h *GenericController[T, C, U, P, R, F, ID]) Update(c *fiber.Ctx) error {
 		return err
 	}
 
-	updatedRecord, err := h.Service.Update(id, input)
+	if err := h.checkObject(c, id); err != nil {
+		return err
+	}
+
+	updatedRecord, err := h.service(c, nil).Update(id, input)
 	if err != nil {
 		return err
 	}
 
 	response := h.MapToResponse(updatedRecord)
-	return c.JSON(response)
+	return c.JSON(selection.Render(response))
 }
 
 func (h *GenericController[T, C, U, P, R, F, ID]) PartialUpdate(c *fiber.Ctx) error {
+	selection, err := ParseFieldSelection[R](c)
+	if err != nil {
+		return err
+	}
+
 	id, err := h.ParseID(c.Params("id"))
 	if err != nil {
 		return exceptions.NewBadRequest("id_required", err)
@@ -165,13 +251,17 @@ func (h *GenericController[T, C, U, P, R, F, ID]) PartialUpdate(c *fiber.Ctx) er
 		return err
 	}
 
-	updatedRecord, err := h.Service.PartialUpdate(id, patchInput)
+	if err := h.checkObject(c, id); err != nil {
+		return err
+	}
+
+	updatedRecord, err := h.service(c, nil).PartialUpdate(id, patchInput)
 	if err != nil {
 		return err
 	}
 
 	response := h.MapToResponse(updatedRecord)
-	return c.JSON(response)
+	return c.JSON(selection.Render(response))
 }
 
 func (h *GenericController[T, C, U, P, R, F, ID]) Delete(c *fiber.Ctx) error {
@@ -180,8 +270,36 @@ func (h *GenericController[T, C, U, P, R, F, ID]) Delete(c *fiber.Ctx) error {
 		return exceptions.NewBadRequest("id_required", err)
 	}
 
-	if err := h.Service.Delete(id); err != nil {
+	if err := h.checkObject(c, id); err != nil {
+		return err
+	}
+
+	if err := h.service(c, nil).Delete(id); err != nil {
 		return err
 	}
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// checkObject loads the record for the object-level permission of the route.
+// Routes without one skip the extra query.
+func (h *GenericController[T, C, U, P, R, F, ID]) checkObject(c *fiber.Ctx, id ID) error {
+	if !permission.RequiresObjectCheck(c) {
+		return nil
+	}
+	record, err := h.service(c, nil).GetByID(id)
+	if err != nil {
+		return err
+	}
+	return permission.CheckObject(c, record)
+}
+
+// service returns the service narrowed to the request user, with the
+// requested relations preloaded.
+func (h *GenericController[T, C, U, P, R, F, ID]) service(c *fiber.Ctx, preloads []string) service.IService[T, C, U, P, R, F, ID] {
+	user, _ := c.Locals("user").(models.IUser)
+	svc := h.Service.ForUser(user)
+	if len(preloads) == 0 {
+		return svc
+	}
+	return svc.WithScopes(preloadScope(preloads))
+}
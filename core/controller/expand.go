@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"strings"
+
+	"grf/core/exceptions"
+	"grf/core/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	ExpandParam           = "expand"
+	DefaultMaxExpandDepth = 2
+)
+
+// parseExpand maps "?expand=groups,groups.permissions" to the preload paths
+// declared in Expandable. Expansions are dotted, one level per dot.
+func parseExpand(c *fiber.Ctx, expandable map[string]string, maxDepth int) ([]string, error) {
+	names := splitParam(c.Query(ExpandParam))
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	preloads := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.Count(name, ".")+1 > maxDepth {
+			return nil, exceptions.NewBadRequest("expand_too_deep", nil).
+				WithTemplateData(map[string]interface{}{"Field": name, "Max": maxDepth})
+		}
+		preload, ok := expandable[name]
+		if !ok {
+			return nil, exceptions.NewBadRequest("invalid_expand", nil).
+				WithTemplateData(map[string]interface{}{"Field": name})
+		}
+		preloads = append(preloads, preload)
+	}
+	return preloads, nil
+}
+
+func preloadScope(preloads []string) repository.Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, preload := range preloads {
+			db = db.Preload(preload)
+		}
+		return db
+	}
+}
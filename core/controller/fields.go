@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+
+	"grf/core/exceptions"
+
+	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	FieldsParam = "fields"
+	OmitParam   = "omit"
+)
+
+type jsonField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+var jsonFieldsCache sync.Map
+
+// FieldSelection is the sparse fieldset requested through ?fields= and ?omit=,
+// validated against the json tags of the response type.
+type FieldSelection struct {
+	Fields []string
+	Omit   []string
+
+	fields []jsonField
+}
+
+func (s *FieldSelection) IsEmpty() bool {
+	return s == nil || (len(s.Fields) == 0 && len(s.Omit) == 0)
+}
+
+func ParseFieldSelection[R any](c *fiber.Ctx) (*FieldSelection, error) {
+	selection := &FieldSelection{
+		Fields: splitParam(c.Query(FieldsParam)),
+		Omit:   splitParam(c.Query(OmitParam)),
+	}
+	if selection.IsEmpty() {
+		return selection, nil
+	}
+
+	fields, ok := jsonFieldsOf(reflect.TypeOf((*R)(nil)).Elem())
+	if !ok {
+		return selection, nil
+	}
+	known := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		known[f.name] = true
+	}
+	for _, name := range append(append([]string{}, selection.Fields...), selection.Omit...) {
+		if !known[name] {
+			return nil, exceptions.NewBadRequest("invalid_field_selection", nil).
+				WithTemplateData(map[string]interface{}{"Field": name})
+		}
+	}
+
+	selection.fields = fields
+	return selection, nil
+}
+
+// Render returns value unchanged when nothing was selected, otherwise an
+// object holding only the selected fields in declaration order.
+func (s *FieldSelection) Render(value interface{}) interface{} {
+	if s.IsEmpty() || s.fields == nil {
+		return value
+	}
+
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return value
+		}
+		v = v.Elem()
+	}
+
+	obj := sparseObject{}
+	for _, f := range s.fields {
+		if !s.includes(f.name) {
+			continue
+		}
+		fv, err := v.FieldByIndexErr(f.index)
+		if err != nil {
+			continue
+		}
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		obj.keys = append(obj.keys, f.name)
+		obj.values = append(obj.values, fv.Interface())
+	}
+	return obj
+}
+
+func (s *FieldSelection) includes(name string) bool {
+	for _, omitted := range s.Omit {
+		if omitted == name {
+			return false
+		}
+	}
+	if len(s.Fields) == 0 {
+		return true
+	}
+	for _, selected := range s.Fields {
+		if selected == name {
+			return true
+		}
+	}
+	return false
+}
+
+type sparseObject struct {
+	keys   []string
+	values []interface{}
+}
+
+func (o sparseObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valueJSON, err := json.Marshal(o.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(keyJSON)
+		buf.WriteByte(':')
+		buf.Write(valueJSON)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func splitParam(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func jsonFieldsOf(typ reflect.Type) ([]jsonField, bool) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, false
+	}
+	if cached, ok := jsonFieldsCache.Load(typ); ok {
+		return cached.([]jsonField), true
+	}
+
+	fields := collectJSONFields(typ, nil)
+	jsonFieldsCache.Store(typ, fields)
+	return fields, true
+}
+
+func collectJSONFields(typ reflect.Type, index []int) []jsonField {
+	var fields []jsonField
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fieldIndex := append(append([]int{}, index...), i)
+
+		if sf.Anonymous && name == "" && sf.Type.Kind() == reflect.Struct {
+			fields = append(fields, collectJSONFields(sf.Type, fieldIndex)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, jsonField{
+			name:      name,
+			index:     fieldIndex,
+			omitEmpty: strings.Contains(opts, "omitempty"),
+		})
+	}
+	return fields
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Struct:
+		return false
+	default:
+		return v.IsZero()
+	}
+}
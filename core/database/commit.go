@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// commitPool is the connection pool of ConnectDB. Its transactions keep the
+// functions registered with AfterCommit until they commit.
+type commitPool struct {
+	*sql.DB
+}
+
+func (p *commitPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	tx, err := p.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &commitTx{Tx: tx, db: p.DB}, nil
+}
+
+// GetDBConn lets gorm.DB.DB return the wrapped pool.
+func (p *commitPool) GetDBConn() (*sql.DB, error) {
+	return p.DB, nil
+}
+
+type commitTx struct {
+	*sql.Tx
+	db *sql.DB
+
+	mu    sync.Mutex
+	after []func()
+}
+
+func (t *commitTx) Commit() error {
+	if err := t.Tx.Commit(); err != nil {
+		return err
+	}
+	t.mu.Lock()
+	after := t.after
+	t.after = nil
+	t.mu.Unlock()
+
+	for _, fn := range after {
+		fn()
+	}
+	return nil
+}
+
+func (t *commitTx) GetDBConn() (*sql.DB, error) {
+	return t.db, nil
+}
+
+// WrapConnPool installs commitPool on db, so AfterCommit can hold work until
+// its transactions commit. ConnectDB calls it, and so does anything relying
+// on AfterCommit, since db may have been opened some other way. Call it
+// before sessions are derived from db: they keep the pool they were given.
+func WrapConnPool(db *gorm.DB) error {
+	switch pool := db.ConnPool.(type) {
+	case *commitPool:
+		return nil
+	case *sql.DB:
+		wrapped := &commitPool{DB: pool}
+		db.ConnPool = wrapped
+		db.Statement.ConnPool = wrapped
+		return nil
+	default:
+		return fmt.Errorf("can't defer work to commit on a %T connection pool", pool)
+	}
+}
+
+// AfterCommit runs fn once the transaction of the statement on db commits,
+// and drops it if the transaction rolls back. Outside a transaction the
+// statement is already committed, so fn runs right away. Transactions only
+// hold fn on pools installed by WrapConnPool.
+func AfterCommit(db *gorm.DB, fn func()) {
+	tx, ok := db.Statement.ConnPool.(*commitTx)
+	if !ok {
+		fn()
+		return
+	}
+	tx.mu.Lock()
+	tx.after = append(tx.after, fn)
+	tx.mu.Unlock()
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
@@ -22,8 +23,18 @@ func dialector(config *config.Config) gorm.Dialector {
 			config.DBName,
 		)
 		return mysql.Open(dsn)
+	} else if config.DBVendor == "postgres" {
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			config.DBHost,
+			config.DBPort,
+			config.DBUser,
+			config.DBPassword,
+			config.DBName,
+			config.DBSSLMode,
+		)
+		return postgres.Open(dsn)
 	} else if config.DBVendor == "sqlite" {
-		return sqlite.Open(config.DBName)
+		return sqlite.New(sqlite.Config{DriverName: sqliteDriverName, DSN: config.DBName})
 	} else {
 		log.Fatalf("Unsupported database vendor: %s", config.DBVendor)
 		return nil
@@ -44,8 +55,10 @@ func gormConfig(config *config.Config) *gorm.Config {
 	}
 }
 
+// PerformMigration runs AutoMigrate, which only adds tables, columns and
+// indexes. Schema changes that need history belong in versioned migrations.
 func PerformMigration(db *gorm.DB, config *config.Config, dst ...interface{}) error {
-	if config.DBMigrate {
+	if config.DBAutoMigrate {
 		log.Println("Running AutoMigrate ")
 
 		err := db.AutoMigrate(dst...)
@@ -65,6 +78,9 @@ func ConnectDB(config *config.Config) (*gorm.DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to DB: %w", err)
 	}
+	if err := WrapConnPool(db); err != nil {
+		return nil, err
+	}
 
 	sqlDB, err := db.DB()
 	if err != nil {
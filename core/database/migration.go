@@ -2,20 +2,32 @@ package database
 
 import (
 	"grf/core/config"
+	"grf/core/migrations"
 	"log"
 
 	"gorm.io/gorm"
 )
 
 type MigrationOptions struct {
-	DB     *gorm.DB
-	Config *config.Config
-	Models []interface{}
+	DB         *gorm.DB
+	Config     *config.Config
+	Models     []interface{}
+	Migrations []*migrations.Migration
 }
 
+// RegisterMigrations applies the pending versioned migrations when DBMigrate
+// is set, then runs AutoMigrate on the models when DBAutoMigrate is set.
 func RegisterMigrations(options *MigrationOptions) {
 	log.Println("starting database migrations")
 
+	if options.Config.DBMigrate {
+		applied, err := migrations.NewMigrator(options.DB, options.Migrations).Up()
+		if err != nil {
+			log.Fatalf("Failed to perform migrations: %v", err)
+		}
+		log.Printf("%d migrations applied", len(applied))
+	}
+
 	if err := PerformMigration(options.DB, options.Config, options.Models...); err != nil {
 		log.Fatalf("Failed to perform automigrations: %v", err)
 	}
@@ -0,0 +1,49 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriverName is the mattn driver with the functions SQLite lacks out of
+// the box registered on every connection, so lookups such as regex work the
+// same way they do on mysql and postgres.
+const sqliteDriverName = "sqlite3_grf"
+
+var sqliteRegexpCache sync.Map
+
+func init() {
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("regexp", sqliteRegexp, true)
+		},
+	})
+}
+
+func sqliteRegexp(pattern string, value interface{}) (bool, error) {
+	var subject string
+	switch v := value.(type) {
+	case nil:
+		return false, nil
+	case string:
+		subject = v
+	case []byte:
+		subject = string(v)
+	default:
+		subject = fmt.Sprint(v)
+	}
+
+	if cached, ok := sqliteRegexpCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp).MatchString(subject), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	sqliteRegexpCache.Store(pattern, re)
+	return re.MatchString(subject), nil
+}
@@ -1,13 +1,18 @@
 package exceptions
 
 import (
+	"errors"
+	"sort"
+
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type AppError struct {
-	StatusCode int    `json:"-"`
-	Message    string `json:"message"`
-	Err        error  `json:"-"`
+	StatusCode   int                    `json:"-"`
+	Message      string                 `json:"message"`
+	Err          error                  `json:"-"`
+	TemplateData map[string]interface{} `json:"-"`
 }
 
 func (e *AppError) Error() string {
@@ -22,6 +27,11 @@ func NewError(code int, message string, err error) *AppError {
 	}
 }
 
+func (e *AppError) WithTemplateData(data map[string]interface{}) *AppError {
+	e.TemplateData = data
+	return e
+}
+
 func NewUnauthorized(message string, err error) *AppError {
 	return NewError(fiber.StatusUnauthorized, message, err)
 }
@@ -34,6 +44,48 @@ func NewBadRequest(message string, err error) *AppError {
 	return NewError(fiber.StatusBadRequest, message, err)
 }
 
+func NewMethodNotAllowed(message string, err error) *AppError {
+	return NewError(fiber.StatusMethodNotAllowed, message, err)
+}
+
 func NewInternal(err error) *AppError {
 	return NewError(fiber.StatusInternalServerError, "unexpected_server_error", err)
 }
+
+// BulkError carries the errors of a bulk request keyed by the index of the
+// item that caused them.
+type BulkError struct {
+	StatusCode int
+	Items      map[int]error
+}
+
+func (e *BulkError) Error() string {
+	return "error_bulk"
+}
+
+func (e *BulkError) Indexes() []int {
+	indexes := make([]int, 0, len(e.Items))
+	for index := range e.Items {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+func NewBulkError(code int, items map[int]error) *BulkError {
+	return &BulkError{
+		StatusCode: code,
+		Items:      items,
+	}
+}
+
+func NewBulkItemError(index int, err error) *BulkError {
+	code := fiber.StatusInternalServerError
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		code = appErr.StatusCode
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		code = fiber.StatusNotFound
+	}
+	return NewBulkError(code, map[int]error{index: err})
+}
@@ -23,6 +23,25 @@ func GlobalErrorHandler(c *fiber.Ctx, err error) error {
 
 	localizer := getLocalizer(c)
 
+	var bulkErr *BulkError
+	if errors.As(err, &bulkErr) {
+		items := make([]fiber.Map, 0, len(bulkErr.Items))
+		for _, index := range bulkErr.Indexes() {
+			_, body := resolveError(bulkErr.Items[index], localizer)
+			body["index"] = index
+			items = append(items, body)
+		}
+
+		_, body := resolveError(NewError(bulkErr.StatusCode, "error_bulk", nil), localizer)
+		body["items"] = items
+		return c.Status(bulkErr.StatusCode).JSON(body)
+	}
+
+	code, body := resolveError(err, localizer)
+	return c.Status(code).JSON(body)
+}
+
+func resolveError(err error, localizer *i18n.Localizer) (int, fiber.Map) {
 	code := fiber.StatusInternalServerError
 	messageKey := "error_internal"
 
@@ -33,6 +52,7 @@ func GlobalErrorHandler(c *fiber.Ctx, err error) error {
 	if errors.As(err, &appErr) {
 		code = appErr.StatusCode
 		messageKey = appErr.Message
+		templateData = appErr.TemplateData
 
 		if appErr.Err != nil && code >= 500 {
 			log.Printf("Internal Error (AppError): %v", appErr.Err)
@@ -52,10 +72,10 @@ func GlobalErrorHandler(c *fiber.Ctx, err error) error {
 			translatedMessage = messageKey
 		}
 
-		return c.Status(code).JSON(fiber.Map{
+		return code, fiber.Map{
 			"error":  translatedMessage,
 			"fields": fieldErrors,
-		})
+		}
 	} else if errors.Is(err, gorm.ErrRecordNotFound) {
 		code = fiber.StatusNotFound
 		messageKey = "error_not_found"
@@ -74,9 +94,9 @@ func GlobalErrorHandler(c *fiber.Ctx, err error) error {
 		translatedMessage = messageKey
 	}
 
-	return c.Status(code).JSON(fiber.Map{
+	return code, fiber.Map{
 		"error": translatedMessage,
-	})
+	}
 }
 
 func formatValidationErrors(errs validator.ValidationErrors, localizer *i18n.Localizer) map[string]string {
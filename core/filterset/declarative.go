@@ -0,0 +1,158 @@
+package filterset
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FilterSet is a declarative IFilterSet driven by the `filter` struct tags of S.
+//
+//	type UserFilterFields struct {
+//		Username string `filter:"username,lookups=exact|icontains|in"`
+//		IsActive bool   `filter:"is_active"`
+//	}
+//	type UserFilterSet = filterset.FilterSet[UserFilterFields]
+//
+// The tag holds the query parameter name followed by the options
+// "lookups=a|b" (defaults to exact) and "column=name" (defaults to the
+// parameter name). The Go type of the field decides how values are parsed and
+// the dialect of the *gorm.DB decides the SQL each lookup emits, see ILookup.
+type FilterSet[S any] struct {
+	conditions []Condition
+}
+
+var _ IFilterSet = (*FilterSet[struct{}])(nil)
+
+type Condition struct {
+	Column string
+	Lookup string
+	Value  interface{}
+}
+
+type field struct {
+	name    string
+	column  string
+	typ     reflect.Type
+	lookups []string
+}
+
+var fieldsCache sync.Map
+
+func (f *FilterSet[S]) Bind(c *fiber.Ctx) error {
+	f.conditions = nil
+
+	for _, fd := range fieldsOf[S]() {
+		for _, name := range fd.lookups {
+			param := fd.name + LookupSeparator + name
+			raw := c.Query(param)
+			if raw == "" && name == Exact {
+				param = fd.name
+				raw = c.Query(param)
+			}
+			if raw == "" {
+				continue
+			}
+
+			l, _ := GetLookup(name)
+			value, err := l.Parse(fd.typ, raw)
+			if err != nil {
+				return newInvalidFilterValue(param, err)
+			}
+			f.conditions = append(f.conditions, Condition{Column: fd.column, Lookup: name, Value: value})
+		}
+	}
+	return nil
+}
+
+func (f *FilterSet[S]) Apply(db *gorm.DB) *gorm.DB {
+	query := db
+	dialect := db.Dialector.Name()
+	for _, cond := range f.conditions {
+		l, ok := GetLookup(cond.Lookup)
+		if !ok {
+			continue
+		}
+		query = query.Where(l.Build(dialect, clause.Column{Name: cond.Column}, cond.Value))
+	}
+	return query
+}
+
+func (f *FilterSet[S]) Conditions() []Condition {
+	return f.conditions
+}
+
+func fieldsOf[S any]() []field {
+	typ := reflect.TypeOf((*S)(nil)).Elem()
+	if cached, ok := fieldsCache.Load(typ); ok {
+		return cached.([]field)
+	}
+
+	fields := parseFields(typ)
+	fieldsCache.Store(typ, fields)
+	return fields
+}
+
+func parseFields(typ reflect.Type) []field {
+	if typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("FilterSet: %s must be a struct", typ))
+	}
+
+	var fields []field
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag, ok := sf.Tag.Lookup("filter")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		fd := field{
+			name: strings.TrimSpace(parts[0]),
+			typ:  sf.Type,
+		}
+		if fd.name == "" {
+			panic(fmt.Sprintf("FilterSet: field %s.%s has an empty filter name", typ.Name(), sf.Name))
+		}
+		if fd.typ.Kind() == reflect.Pointer {
+			fd.typ = fd.typ.Elem()
+		}
+		for _, opt := range parts[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+			switch key {
+			case "lookups":
+				for _, lookup := range strings.Split(value, "|") {
+					l, ok := GetLookup(lookup)
+					if !ok {
+						panic(fmt.Sprintf("FilterSet: field %s.%s has unknown lookup %q", typ.Name(), sf.Name, lookup))
+					}
+					if !l.Supports(fd.typ) {
+						panic(fmt.Sprintf("FilterSet: lookup %q does not support field %s.%s of type %s", lookup, typ.Name(), sf.Name, sf.Type))
+					}
+					fd.lookups = append(fd.lookups, lookup)
+				}
+			case "column":
+				fd.column = value
+			default:
+				panic(fmt.Sprintf("FilterSet: field %s.%s has unknown option %q", typ.Name(), sf.Name, key))
+			}
+		}
+
+		if fd.column == "" {
+			fd.column = fd.name
+		}
+		if len(fd.lookups) == 0 {
+			if l, _ := GetLookup(Exact); !l.Supports(fd.typ) {
+				panic(fmt.Sprintf("FilterSet: field %s.%s of type %s needs explicit lookups", typ.Name(), sf.Name, sf.Type))
+			}
+			fd.lookups = []string{Exact}
+		}
+		fields = append(fields, fd)
+	}
+	return fields
+}
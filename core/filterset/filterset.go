@@ -9,3 +9,27 @@ type IFilterSet interface {
 	Bind(c *fiber.Ctx) error
 	Apply(db *gorm.DB) *gorm.DB
 }
+
+type chain []IFilterSet
+
+// Chain combines filter sets so they are bound and applied one after another.
+func Chain(filters ...IFilterSet) IFilterSet {
+	return chain(filters)
+}
+
+func (c chain) Bind(ctx *fiber.Ctx) error {
+	for _, filter := range c {
+		if err := filter.Bind(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c chain) Apply(db *gorm.DB) *gorm.DB {
+	query := db
+	for _, filter := range c {
+		query = filter.Apply(query)
+	}
+	return query
+}
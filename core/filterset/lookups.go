@@ -0,0 +1,277 @@
+package filterset
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"grf/core/exceptions"
+
+	"gorm.io/gorm/clause"
+)
+
+const LookupSeparator = "__"
+
+const (
+	Exact       = "exact"
+	IExact      = "iexact"
+	Contains    = "contains"
+	IContains   = "icontains"
+	StartsWith  = "startswith"
+	IStartsWith = "istartswith"
+	In          = "in"
+	Gt          = "gt"
+	Gte         = "gte"
+	Lt          = "lt"
+	Lte         = "lte"
+	IsNull      = "isnull"
+	Range       = "range"
+	Regex       = "regex"
+	IRegex      = "iregex"
+	HasKey      = "has_key"
+)
+
+const (
+	DialectSQLite   = "sqlite"
+	DialectMySQL    = "mysql"
+	DialectPostgres = "postgres"
+)
+
+// ILookup turns a raw query value into a WHERE expression. Build receives the
+// name of the gorm dialector so each lookup can emit vendor specific SQL.
+type ILookup interface {
+	Supports(typ reflect.Type) bool
+	Parse(typ reflect.Type, raw string) (interface{}, error)
+	Build(dialect string, column clause.Column, value interface{}) clause.Expression
+}
+
+type lookup struct {
+	supports func(typ reflect.Type) bool
+	parse    func(typ reflect.Type, raw string) (interface{}, error)
+	build    func(dialect string, column clause.Column, value interface{}) clause.Expression
+}
+
+func (l *lookup) Supports(typ reflect.Type) bool { return l.supports(typ) }
+
+func (l *lookup) Parse(typ reflect.Type, raw string) (interface{}, error) { return l.parse(typ, raw) }
+
+func (l *lookup) Build(dialect string, column clause.Column, value interface{}) clause.Expression {
+	return l.build(dialect, column, value)
+}
+
+var (
+	lookupsMu sync.RWMutex
+	lookups   = map[string]ILookup{
+		Exact: &lookup{supports: isScalar, parse: parseScalar, build: func(_ string, column clause.Column, value interface{}) clause.Expression {
+			return clause.Eq{Column: column, Value: value}
+		}},
+		IExact: &lookup{supports: isString, parse: parseScalar, build: func(dialect string, column clause.Column, value interface{}) clause.Expression {
+			if dialect == DialectPostgres {
+				return clause.Expr{SQL: "? ILIKE ? ESCAPE '!'", Vars: []interface{}{column, escapeLike(value.(string))}}
+			}
+			return clause.Expr{SQL: "LOWER(?) = LOWER(?)", Vars: []interface{}{column, value}}
+		}},
+		Contains: &lookup{supports: isString, parse: parseScalar, build: func(dialect string, column clause.Column, value interface{}) clause.Expression {
+			pattern := "%" + escapeLike(value.(string)) + "%"
+			switch dialect {
+			case DialectMySQL:
+				return clause.Expr{SQL: "? LIKE BINARY ? ESCAPE '!'", Vars: []interface{}{column, pattern}}
+			case DialectSQLite:
+				return clause.Expr{SQL: "INSTR(?, ?) > 0", Vars: []interface{}{column, value}}
+			default:
+				return clause.Expr{SQL: "? LIKE ? ESCAPE '!'", Vars: []interface{}{column, pattern}}
+			}
+		}},
+		IContains: &lookup{supports: isString, parse: parseScalar, build: func(dialect string, column clause.Column, value interface{}) clause.Expression {
+			pattern := "%" + escapeLike(value.(string)) + "%"
+			if dialect == DialectPostgres {
+				return clause.Expr{SQL: "? ILIKE ? ESCAPE '!'", Vars: []interface{}{column, pattern}}
+			}
+			return clause.Expr{SQL: "LOWER(?) LIKE LOWER(?) ESCAPE '!'", Vars: []interface{}{column, pattern}}
+		}},
+		StartsWith: &lookup{supports: isString, parse: parseScalar, build: func(dialect string, column clause.Column, value interface{}) clause.Expression {
+			pattern := escapeLike(value.(string)) + "%"
+			switch dialect {
+			case DialectMySQL:
+				return clause.Expr{SQL: "? LIKE BINARY ? ESCAPE '!'", Vars: []interface{}{column, pattern}}
+			case DialectSQLite:
+				return clause.Expr{SQL: "SUBSTR(?, 1, LENGTH(?)) = ?", Vars: []interface{}{column, value, value}}
+			default:
+				return clause.Expr{SQL: "? LIKE ? ESCAPE '!'", Vars: []interface{}{column, pattern}}
+			}
+		}},
+		IStartsWith: &lookup{supports: isString, parse: parseScalar, build: func(dialect string, column clause.Column, value interface{}) clause.Expression {
+			pattern := escapeLike(value.(string)) + "%"
+			if dialect == DialectPostgres {
+				return clause.Expr{SQL: "? ILIKE ? ESCAPE '!'", Vars: []interface{}{column, pattern}}
+			}
+			return clause.Expr{SQL: "LOWER(?) LIKE LOWER(?) ESCAPE '!'", Vars: []interface{}{column, pattern}}
+		}},
+		In: &lookup{supports: isScalar, parse: parseList, build: func(_ string, column clause.Column, value interface{}) clause.Expression {
+			return clause.IN{Column: column, Values: value.([]interface{})}
+		}},
+		Gt: &lookup{supports: isScalar, parse: parseScalar, build: func(_ string, column clause.Column, value interface{}) clause.Expression {
+			return clause.Gt{Column: column, Value: value}
+		}},
+		Gte: &lookup{supports: isScalar, parse: parseScalar, build: func(_ string, column clause.Column, value interface{}) clause.Expression {
+			return clause.Gte{Column: column, Value: value}
+		}},
+		Lt: &lookup{supports: isScalar, parse: parseScalar, build: func(_ string, column clause.Column, value interface{}) clause.Expression {
+			return clause.Lt{Column: column, Value: value}
+		}},
+		Lte: &lookup{supports: isScalar, parse: parseScalar, build: func(_ string, column clause.Column, value interface{}) clause.Expression {
+			return clause.Lte{Column: column, Value: value}
+		}},
+		IsNull: &lookup{supports: isAny, parse: parseBool, build: func(_ string, column clause.Column, value interface{}) clause.Expression {
+			if value.(bool) {
+				return clause.Eq{Column: column, Value: nil}
+			}
+			return clause.Neq{Column: column, Value: nil}
+		}},
+		Range: &lookup{supports: isScalar, parse: parseRange, build: func(_ string, column clause.Column, value interface{}) clause.Expression {
+			bounds := value.([]interface{})
+			return clause.Expr{SQL: "? BETWEEN ? AND ?", Vars: []interface{}{column, bounds[0], bounds[1]}}
+		}},
+		Regex: &lookup{supports: isString, parse: parseScalar, build: func(dialect string, column clause.Column, value interface{}) clause.Expression {
+			switch dialect {
+			case DialectPostgres:
+				return clause.Expr{SQL: "? ~ ?", Vars: []interface{}{column, value}}
+			case DialectMySQL:
+				return clause.Expr{SQL: "REGEXP_LIKE(?, ?, 'c')", Vars: []interface{}{column, value}}
+			default:
+				return clause.Expr{SQL: "? REGEXP ?", Vars: []interface{}{column, value}}
+			}
+		}},
+		IRegex: &lookup{supports: isString, parse: parseScalar, build: func(dialect string, column clause.Column, value interface{}) clause.Expression {
+			switch dialect {
+			case DialectPostgres:
+				return clause.Expr{SQL: "? ~* ?", Vars: []interface{}{column, value}}
+			case DialectMySQL:
+				return clause.Expr{SQL: "REGEXP_LIKE(?, ?, 'i')", Vars: []interface{}{column, value}}
+			default:
+				return clause.Expr{SQL: "? REGEXP ?", Vars: []interface{}{column, "(?i)" + value.(string)}}
+			}
+		}},
+		HasKey: &lookup{supports: isAny, parse: parseString, build: func(dialect string, column clause.Column, value interface{}) clause.Expression {
+			switch dialect {
+			case DialectPostgres:
+				return clause.Expr{SQL: "jsonb_exists(CAST(? AS jsonb), ?)", Vars: []interface{}{column, value}}
+			case DialectMySQL:
+				return clause.Expr{SQL: "JSON_CONTAINS_PATH(?, 'one', ?)", Vars: []interface{}{column, jsonPath(value.(string))}}
+			default:
+				return clause.Expr{SQL: "json_type(?, ?) IS NOT NULL", Vars: []interface{}{column, jsonPath(value.(string))}}
+			}
+		}},
+	}
+)
+
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func RegisterLookup(name string, l ILookup) {
+	lookupsMu.Lock()
+	defer lookupsMu.Unlock()
+	lookups[name] = l
+}
+
+func GetLookup(name string) (ILookup, bool) {
+	lookupsMu.RLock()
+	defer lookupsMu.RUnlock()
+	l, ok := lookups[name]
+	return l, ok
+}
+
+func newInvalidFilterValue(param string, err error) *exceptions.AppError {
+	return exceptions.NewBadRequest("invalid_filter_value", err).
+		WithTemplateData(map[string]interface{}{"Param": param})
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(value)
+}
+
+func jsonPath(key string) string {
+	return `$."` + strings.ReplaceAll(key, `"`, `\"`) + `"`
+}
+
+func isAny(_ reflect.Type) bool { return true }
+
+func isString(typ reflect.Type) bool { return typ.Kind() == reflect.String }
+
+func isScalar(typ reflect.Type) bool {
+	if typ == timeType {
+		return true
+	}
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func parseString(_ reflect.Type, raw string) (interface{}, error) {
+	return raw, nil
+}
+
+func parseBool(_ reflect.Type, raw string) (interface{}, error) {
+	return strconv.ParseBool(raw)
+}
+
+func parseRange(typ reflect.Type, raw string) (interface{}, error) {
+	values, err := parseList(typ, raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(values.([]interface{})) != 2 {
+		return nil, errors.New("range expects exactly two comma-separated values")
+	}
+	return values, nil
+}
+
+func parseList(typ reflect.Type, raw string) (interface{}, error) {
+	parts := strings.Split(raw, ",")
+	values := make([]interface{}, 0, len(parts))
+	for _, part := range parts {
+		value, err := parseScalar(typ, strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func parseScalar(typ reflect.Type, raw string) (interface{}, error) {
+	if typ == timeType {
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, raw); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("invalid time %q", raw)
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(raw, 10, typ.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(raw, 10, typ.Bits())
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, typ.Bits())
+	default:
+		return nil, fmt.Errorf("unsupported type %s", typ)
+	}
+}
@@ -0,0 +1,78 @@
+package filterset
+
+import (
+	"fmt"
+	"strings"
+
+	"grf/core/exceptions"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm/clause"
+)
+
+const OrderingParam = "ordering"
+
+type OrderBy struct {
+	Column string
+	Desc   bool
+}
+
+func (o OrderBy) Clause() clause.OrderByColumn {
+	return clause.OrderByColumn{Column: clause.Column{Name: o.Column}, Desc: o.Desc}
+}
+
+// OrderingFilter parses "?ordering=-created_at,username" against a whitelist
+// of sortable fields. It holds no request state and is safe to share.
+type OrderingFilter struct {
+	Param   string
+	Fields  map[string]string
+	Default []OrderBy
+}
+
+// NewOrderingFilter allows each field to be sorted by the column of the same
+// name. defaultOrdering uses the query syntax, e.g. "-created_at,id".
+func NewOrderingFilter(fields []string, defaultOrdering string) *OrderingFilter {
+	f := &OrderingFilter{
+		Param:  OrderingParam,
+		Fields: make(map[string]string, len(fields)),
+	}
+	for _, field := range fields {
+		f.Fields[field] = field
+	}
+
+	if defaultOrdering != "" {
+		ordering, err := f.parse(defaultOrdering)
+		if err != nil {
+			panic(fmt.Sprintf("OrderingFilter: invalid default ordering %q", defaultOrdering))
+		}
+		f.Default = ordering
+	}
+	return f
+}
+
+func (f *OrderingFilter) Bind(c *fiber.Ctx) ([]OrderBy, error) {
+	raw := c.Query(f.Param)
+	if raw == "" {
+		return f.Default, nil
+	}
+	return f.parse(raw)
+}
+
+func (f *OrderingFilter) parse(raw string) ([]OrderBy, error) {
+	var ordering []OrderBy
+	for _, term := range strings.Split(raw, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		name := strings.TrimPrefix(term, "-")
+		column, ok := f.Fields[name]
+		if !ok {
+			return nil, exceptions.NewBadRequest("invalid_ordering_field", nil).
+				WithTemplateData(map[string]interface{}{"Field": name})
+		}
+		ordering = append(ordering, OrderBy{Column: column, Desc: strings.HasPrefix(term, "-")})
+	}
+	return ordering, nil
+}
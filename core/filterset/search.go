@@ -0,0 +1,78 @@
+package filterset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const SearchParam = "search"
+
+type SearchField struct {
+	Column string
+	Lookup string
+}
+
+// SearchFilter implements "?search=" over several columns. Every term must
+// match (AND) at least one of the fields (OR). Fields are declared DRF style:
+// "^name" for prefix, "=name" for exact and "name" for contains, all case
+// insensitive.
+type SearchFilter struct {
+	Param  string
+	Fields []SearchField
+
+	terms []string
+}
+
+var _ IFilterSet = (*SearchFilter)(nil)
+
+func NewSearchFilter(fields ...string) *SearchFilter {
+	if len(fields) == 0 {
+		panic("SearchFilter: at least one field is required")
+	}
+
+	f := &SearchFilter{Param: SearchParam}
+	for _, field := range fields {
+		lookup := IContains
+		switch {
+		case strings.HasPrefix(field, "^"):
+			lookup = IStartsWith
+		case strings.HasPrefix(field, "="):
+			lookup = IExact
+		}
+
+		column := strings.TrimLeft(field, "^=")
+		if column == "" {
+			panic(fmt.Sprintf("SearchFilter: invalid field %q", field))
+		}
+		f.Fields = append(f.Fields, SearchField{Column: column, Lookup: lookup})
+	}
+	return f
+}
+
+func (f *SearchFilter) Bind(c *fiber.Ctx) error {
+	f.terms = strings.FieldsFunc(c.Query(f.Param), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	return nil
+}
+
+func (f *SearchFilter) Apply(db *gorm.DB) *gorm.DB {
+	query := db
+	dialect := db.Dialector.Name()
+	for _, term := range f.terms {
+		matches := make([]clause.Expression, 0, len(f.Fields))
+		for _, field := range f.Fields {
+			l, ok := GetLookup(field.Lookup)
+			if !ok {
+				continue
+			}
+			matches = append(matches, l.Build(dialect, clause.Column{Name: field.Column}, term))
+		}
+		query = query.Where(clause.Or(matches...))
+	}
+	return query
+}
@@ -0,0 +1,43 @@
+package filterset
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type columnSelection struct {
+	fields []string
+}
+
+// SelectColumns narrows the SELECT to the given fields, plus the primary key.
+// It only does so when every field maps to a column of the model, otherwise
+// the query is left untouched so derived response fields keep their data.
+func SelectColumns(fields ...string) IFilterSet {
+	return &columnSelection{fields: fields}
+}
+
+func (s *columnSelection) Bind(_ *fiber.Ctx) error {
+	return nil
+}
+
+func (s *columnSelection) Apply(db *gorm.DB) *gorm.DB {
+	if len(s.fields) == 0 || db.Statement.Model == nil {
+		return db
+	}
+	if err := db.Statement.Parse(db.Statement.Model); err != nil {
+		return db
+	}
+
+	sch := db.Statement.Schema
+	columns := append([]string{}, sch.PrimaryFieldDBNames...)
+	for _, name := range s.fields {
+		field := sch.LookUpField(name)
+		if field == nil || field.DBName == "" {
+			return db
+		}
+		if !field.PrimaryKey {
+			columns = append(columns, field.DBName)
+		}
+	}
+	return db.Select(columns)
+}
@@ -0,0 +1,160 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"grf/core/config"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/sync/singleflight"
+)
+
+// minRefreshInterval limits how often an unknown kid reloads the set, so
+// forged tokens can't make every request fetch it.
+const minRefreshInterval = 10 * time.Second
+
+// maxJWKSSize bounds the JWK Set read from a URL.
+const maxJWKSSize = 1 << 20
+
+// JWKSource verifies the tokens of another issuer with the keys of its JWK
+// Set, read from URL or, in tests and air-gapped setups, from File. The set
+// is cached for TTL and reloaded early when a token names an unknown kid, as
+// happens when the issuer rotates its keys.
+type JWKSource struct {
+	URL    string
+	File   string
+	TTL    time.Duration
+	Client *http.Client
+
+	mu          sync.Mutex
+	keyring     *Keyring
+	loadedAt    time.Time
+	attemptedAt time.Time
+
+	// reloads shares a fetch between the requests waiting for it.
+	reloads singleflight.Group
+}
+
+func NewJWKSource(url string, file string, ttl time.Duration) *JWKSource {
+	if (url == "") == (file == "") {
+		panic("JWKSource requer exatamente uma URL ou um arquivo")
+	}
+	return &JWKSource{
+		URL:    url,
+		File:   file,
+		TTL:    ttl,
+		Client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// JWKSourceFromConfig returns the JWK Set of the OIDC provider, from
+// OIDC_JWKS_URL or OIDC_JWKS_FILE. It isn't read until first used.
+func JWKSourceFromConfig(cfg *config.Config) (*JWKSource, error) {
+	if (cfg.OIDCJWKSURL == "") == (cfg.OIDCJWKSFile == "") {
+		return nil, errors.New("OIDC_ISSUER needs exactly one of OIDC_JWKS_URL and OIDC_JWKS_FILE")
+	}
+	return NewJWKSource(cfg.OIDCJWKSURL, cfg.OIDCJWKSFile, time.Duration(cfg.OIDCJWKSCacheSeconds)*time.Second), nil
+}
+
+// Keyfunc is the jwt.Keyfunc picking the verification key by kid. As with
+// Keyring.Keyfunc, the algorithm must be the key's own.
+func (s *JWKSource) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	keyring, err := s.keyringFor(kid)
+	if err != nil {
+		return nil, err
+	}
+	return keyring.Keyfunc(token)
+}
+
+// keyringFor returns the cached set, reloading it when it expired or lacks
+// kid. The set is fetched without holding the lock: requests go on with the
+// cached set meanwhile, and only those needing a key it lacks wait. A failed
+// reload keeps serving the previous set.
+func (s *JWKSource) keyringFor(kid string) (*Keyring, error) {
+	s.mu.Lock()
+	now := time.Now()
+	current := s.keyring
+	stale := current == nil || now.Sub(s.loadedAt) >= s.TTL
+	if !stale && current.keys[kid] != nil {
+		s.mu.Unlock()
+		return current, nil
+	}
+	if current != nil && now.Sub(s.attemptedAt) < minRefreshInterval {
+		s.mu.Unlock()
+		return current, nil
+	}
+	s.attemptedAt = now
+	s.mu.Unlock()
+
+	if current != nil && current.keys[kid] != nil {
+		go s.reloads.Do("", s.reload)
+		return current, nil
+	}
+	keyring, err, _ := s.reloads.Do("", s.reload)
+	if err != nil {
+		return nil, err
+	}
+	return keyring.(*Keyring), nil
+}
+
+func (s *JWKSource) reload() (interface{}, error) {
+	keys, err := s.load()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		if s.keyring != nil {
+			return s.keyring, nil
+		}
+		return nil, err
+	}
+	s.keyring = New(keys[0], keys[1:]...)
+	s.loadedAt = time.Now()
+	return s.keyring, nil
+}
+
+func (s *JWKSource) load() ([]*Key, error) {
+	var data []byte
+	var err error
+	if s.File != "" {
+		data, err = os.ReadFile(s.File)
+	} else {
+		data, err = s.fetch()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+func (s *JWKSource) fetch() ([]byte, error) {
+	resp, err := s.Client.Get(s.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: unexpected status %d", s.URL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxJWKSSize {
+		return nil, errors.New(s.URL + ": JWK Set too large")
+	}
+	return data, nil
+}
+
+// Load reads the set now, so a misconfiguration shows at startup or in the
+// check command rather than on the first request.
+func (s *JWKSource) Load() error {
+	_, err := s.keyringFor("")
+	return err
+}
@@ -0,0 +1,222 @@
+package keyring
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"grf/core/config"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is a signing or verification key. Keys loaded from PEM files are
+// identified by the thumbprint of their public part, so the same file always
+// yields the same kid.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	// signKey is nil for keys that only verify.
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func (k *Key) CanSign() bool { return k.signKey != nil }
+
+// Keyring signs tokens with its active key and verifies them with any of its
+// keys, picked by the kid header. Rotating means making a new key active
+// while the old one stays for verification until its tokens expire.
+type Keyring struct {
+	Active *Key
+
+	keys  map[string]*Key
+	order []*Key
+}
+
+// FromConfig loads JWT_PRIVATE_KEY_FILE as the active key and
+// JWT_PUBLIC_KEY_FILES as verification keys. Without a private key the
+// keyring falls back to HS256 with JWT_SECRET, whose tokens carry no kid.
+func FromConfig(cfg *config.Config) (*Keyring, error) {
+	if cfg.JWTPrivateKeyFile == "" {
+		if len(cfg.JWTPublicKeyFiles) > 0 {
+			return nil, errors.New("JWT_PUBLIC_KEY_FILES needs JWT_PRIVATE_KEY_FILE")
+		}
+		return NewHMAC(cfg.JWTSecret), nil
+	}
+
+	active, err := LoadFile(cfg.JWTPrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if !active.CanSign() {
+		return nil, fmt.Errorf("%s: a private key is required to sign", cfg.JWTPrivateKeyFile)
+	}
+	keys := []*Key{active}
+	for _, path := range cfg.JWTPublicKeyFiles {
+		key, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return New(active, keys...), nil
+}
+
+// NewHMAC returns a keyring signing and verifying with a shared secret.
+func NewHMAC(secret string) *Keyring {
+	return New(&Key{
+		Method:    jwt.SigningMethodHS256,
+		signKey:   []byte(secret),
+		verifyKey: []byte(secret),
+	})
+}
+
+// New builds a keyring signing with active. Keys repeated by kid are kept
+// once, so the active key may also be listed among the others.
+func New(active *Key, others ...*Key) *Keyring {
+	k := &Keyring{Active: active, keys: make(map[string]*Key)}
+	for _, key := range append([]*Key{active}, others...) {
+		if _, ok := k.keys[key.ID]; ok {
+			continue
+		}
+		k.keys[key.ID] = key
+		k.order = append(k.order, key)
+	}
+	return k
+}
+
+func (k *Keyring) Keys() []*Key {
+	return k.order
+}
+
+// Sign signs claims with the active key and sets its kid header.
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.Active.Method, claims)
+	if k.Active.ID != "" {
+		token.Header["kid"] = k.Active.ID
+	}
+	return token.SignedString(k.Active.signKey)
+}
+
+// Keyfunc is the jwt.Keyfunc picking the verification key by kid. The
+// algorithm must be the key's own, so a public key can't be used as an HMAC
+// secret.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signature method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+// Methods lists the algorithms of the keyring, for jwt.WithValidMethods.
+func (k *Keyring) Methods() []string {
+	var methods []string
+	seen := make(map[string]bool)
+	for _, key := range k.order {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// LoadFile reads a PEM key. Private keys may be PKCS#8, PKCS#1 or SEC 1,
+// public keys PKIX or PKCS#1; a certificate yields its public key.
+func LoadFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParsePEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+func ParsePEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			parsed = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(parsed)
+}
+
+// NewKey wraps an RSA, ECDSA or Ed25519 key, private or public. RSA signs
+// with RS256, ECDSA with the ES algorithm of its curve and Ed25519 with EdDSA.
+func NewKey(raw interface{}) (*Key, error) {
+	key := &Key{}
+	if signer, ok := raw.(crypto.Signer); ok {
+		key.signKey = raw
+		raw = signer.Public()
+	}
+
+	switch pub := raw.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must have at least 2048 bits")
+		}
+		key.Method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		switch pub.Curve {
+		case elliptic.P256():
+			key.Method = jwt.SigningMethodES256
+		case elliptic.P384():
+			key.Method = jwt.SigningMethodES384
+		case elliptic.P521():
+			key.Method = jwt.SigningMethodES512
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", pub.Curve.Params().Name)
+		}
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", raw)
+	}
+	key.verifyKey = raw
+
+	jwk, err := publicJWK(key)
+	if err != nil {
+		return nil, err
+	}
+	key.ID = thumbprint(jwk)
+	return key, nil
+}
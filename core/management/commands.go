@@ -0,0 +1,356 @@
+package management
+
+import (
+	"errors"
+	"fmt"
+	"grf/core/bootstrap"
+	"grf/core/config"
+	"grf/core/keyring"
+	"grf/core/migrations"
+	"grf/core/permission"
+	"io"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func builtinCommands() []*Command {
+	return []*Command{
+		{
+			Name:        "serve",
+			Description: "Start the HTTP server",
+			Run:         serve,
+		},
+		{
+			Name:        "migrate",
+			Args:        "[up | rollback [-steps N] | status | unlock]",
+			Description: "Apply, roll back or list the versioned migrations",
+			Run:         migrate,
+		},
+		{
+			Name:        "makemigrations",
+			Args:        "[-module auth] [-name auto] [-dir path] [-dry-run]",
+			Description: "Write the next migration of a module from its model changes",
+			Run:         makeMigrations,
+		},
+		{
+			Name:        "syncpermissions",
+			Args:        "[-prune] [-dry-run]",
+			Description: "Create and update the declared permissions",
+			Run:         syncPermissions,
+		},
+		{
+			Name:        "showurls",
+			Description: "List the routes with their names and permissions",
+			Run:         showURLs,
+		},
+		{
+			Name:        "check",
+			Description: "Report configuration, migration and permission problems",
+			Run:         check,
+		},
+	}
+}
+
+func serve(ctx *Context, args []string) error {
+	if err := ctx.FlagSet("serve").Parse(args); err != nil {
+		return err
+	}
+	app, err := ctx.App()
+	if err != nil {
+		return err
+	}
+	return app.Start()
+}
+
+func migrate(ctx *Context, args []string) error {
+	command := "up"
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		command, args = args[0], args[1:]
+	}
+	flags := ctx.FlagSet("migrate " + command)
+	steps := flags.Int("steps", 1, "number of migrations to roll back")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+	migrator := migrations.NewMigrator(db, ctx.Migrations())
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(ctx.Out, "%d migrations applied.\n", len(applied))
+	case "rollback":
+		reverted, err := migrator.Rollback(*steps)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(ctx.Out, "%d migrations rolled back.\n", len(reverted))
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		migrations.PrintStatus(ctx.Out, statuses)
+	case "unlock":
+		return migrator.Unlock()
+	default:
+		return fmt.Errorf("unknown migrate command %q", command)
+	}
+	return nil
+}
+
+func makeMigrations(ctx *Context, args []string) error {
+	flags := ctx.FlagSet("makemigrations")
+	moduleName := flags.String("module", "auth", "domain module to generate the migration for")
+	name := flags.String("name", "auto", "migration name")
+	dir := flags.String("dir", "", "output directory (default domain/<module>/migrations)")
+	dryRun := flags.Bool("dry-run", false, "print the migration without writing it")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	module, err := ctx.Module(*moduleName)
+	if err != nil {
+		return err
+	}
+	if *dir == "" {
+		*dir = filepath.Join("domain", module.Name, "migrations")
+	}
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+	_, err = migrations.MakeMigrations(&migrations.MakeOptions{
+		DB:         db,
+		Module:     module.Name,
+		Models:     module.Models,
+		Migrations: module.Migrations,
+		Dir:        *dir,
+		Name:       *name,
+		DryRun:     *dryRun,
+		Out:        ctx.Out,
+	})
+	return err
+}
+
+func syncPermissions(ctx *Context, args []string) error {
+	flags := ctx.FlagSet("syncpermissions")
+	prune := flags.Bool("prune", false, "delete permissions no longer declared")
+	dryRun := flags.Bool("dry-run", false, "print the planned changes without applying them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx.Config.PermissionSync = false
+	app, err := ctx.App()
+	if err != nil {
+		return err
+	}
+
+	options := bootstrap.PermissionOptions(app, *prune, *dryRun)
+	options.Out = ctx.Out
+	report, err := permission.SyncPermissions(options)
+	if err != nil {
+		return err
+	}
+	if !*dryRun {
+		report.Print(ctx.Out)
+	}
+	return nil
+}
+
+var methodOrder = []string{
+	fiber.MethodGet, fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete,
+}
+
+// showURLs prints the route table. HEAD routes, which Fiber adds for every
+// GET, are left out.
+func showURLs(ctx *Context, args []string) error {
+	if err := ctx.FlagSet("showurls").Parse(args); err != nil {
+		return err
+	}
+	ctx.Config.DBMigrate = false
+	ctx.Config.DBAutoMigrate = false
+	ctx.Config.PermissionSync = false
+	app, err := ctx.App()
+	if err != nil {
+		return err
+	}
+
+	routes := app.FiberApp.GetRoutes(true)
+	routes = slices.DeleteFunc(routes, func(route fiber.Route) bool {
+		return route.Method == fiber.MethodHead
+	})
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return slices.Index(methodOrder, routes[i].Method) < slices.Index(methodOrder, routes[j].Method)
+	})
+
+	w := tabwriter.NewWriter(ctx.Out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tNAME\tPERMISSION")
+	for _, route := range routes {
+		name, perm := "-", "-"
+		if route.Name != "" {
+			name = route.Name
+			if routePerm, ok := app.RoutePermissions[route.Name]; ok {
+				perm = permission.Describe(routePerm)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", route.Method, route.Path, name, perm)
+	}
+	return w.Flush()
+}
+
+type checkIssue struct {
+	level   string
+	message string
+}
+
+// checkOIDC validates the provider settings and loads its JWK Set.
+func checkOIDC(cfg *config.Config, errorf func(format string, a ...interface{})) {
+	source, err := keyring.JWKSourceFromConfig(cfg)
+	if err != nil {
+		errorf("%v", err)
+		return
+	}
+	if cfg.OIDCAudience == "" {
+		errorf("OIDC_AUDIENCE is empty, tokens issued to any client would be accepted")
+	}
+	if cfg.OIDCCreateUsers && cfg.OIDCUsernameClaim == "" {
+		errorf("OIDC_USERNAME_CLAIM is empty")
+	}
+	if strings.HasPrefix(cfg.OIDCJWKSURL, "http://") && cfg.Env != "development" {
+		errorf("OIDC_JWKS_URL must use https outside of development")
+	}
+	if err := source.Load(); err != nil {
+		errorf("OIDC JWK Set: %v", err)
+	}
+}
+
+// check reports problems without applying migrations or syncing
+// permissions.
+func check(ctx *Context, args []string) error {
+	if err := ctx.FlagSet("check").Parse(args); err != nil {
+		return err
+	}
+
+	var issues []checkIssue
+	errorf := func(format string, a ...interface{}) {
+		issues = append(issues, checkIssue{"ERROR", fmt.Sprintf(format, a...)})
+	}
+	warnf := func(format string, a ...interface{}) {
+		issues = append(issues, checkIssue{"WARNING", fmt.Sprintf(format, a...)})
+	}
+
+	cfg := ctx.Config
+	switch cfg.DBVendor {
+	case "sqlite", "mysql", "postgres":
+	default:
+		errorf("DB_VENDOR %q is not supported", cfg.DBVendor)
+		return reportIssues(ctx.Out, issues)
+	}
+	if cfg.JWTPrivateKeyFile != "" {
+		if _, err := keyring.FromConfig(&cfg); err != nil {
+			errorf("JWT keys: %v", err)
+		}
+	} else if cfg.JWTSecret == config.DefaultJWTSecret && cfg.Env != "development" {
+		errorf("JWT_SECRET uses the insecure default outside of development")
+	} else if len(cfg.JWTSecret) < 32 {
+		warnf("JWT_SECRET is shorter than 32 characters")
+	}
+	switch cfg.SessionCookieSameSite {
+	case "Lax", "Strict":
+	case "None":
+		if !cfg.SessionCookieSecure {
+			errorf("SESSION_COOKIE_SAMESITE=None needs SESSION_COOKIE_SECURE")
+		}
+	default:
+		errorf("SESSION_COOKIE_SAMESITE %q must be Lax, Strict or None", cfg.SessionCookieSameSite)
+	}
+	if !cfg.SessionCookieSecure && cfg.Env != "development" {
+		warnf("SESSION_COOKIE_SECURE is off outside of development")
+	}
+	if cfg.PasswordHashCost != 0 && cfg.PasswordHashCost < config.DefaultPasswordHashCost && cfg.Env != "development" {
+		errorf("PASSWORD_HASH_COST is below %d outside of development", config.DefaultPasswordHashCost)
+	}
+	if cfg.OIDCIssuer != "" {
+		checkOIDC(&cfg, errorf)
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		errorf("database: %v", err)
+		return reportIssues(ctx.Out, issues)
+	}
+	statuses, err := migrations.NewMigrator(db, ctx.Migrations()).Status()
+	if err != nil {
+		errorf("migrations: %v", err)
+		return reportIssues(ctx.Out, issues)
+	}
+	pending := false
+	for _, status := range statuses {
+		if !status.Unknown && !status.Migration.Supports(db.Dialector.Name()) {
+			errorf("migration %s has no script for %s", status.Migration.ID(), db.Dialector.Name())
+		}
+		if status.Unknown {
+			warnf("migration %s is applied but not registered", status.Migration.ID())
+		} else if status.AppliedAt == nil {
+			warnf("migration %s is pending, run migrate", status.Migration.ID())
+			pending = true
+		}
+	}
+	// Permissions can't be compared before the schema is up to date.
+	if pending {
+		return reportIssues(ctx.Out, issues)
+	}
+
+	ctx.Config.DBMigrate = false
+	ctx.Config.DBAutoMigrate = false
+	ctx.Config.PermissionSync = false
+	app, err := ctx.App()
+	if err != nil {
+		errorf("bootstrap: %v", err)
+		return reportIssues(ctx.Out, issues)
+	}
+	options := bootstrap.PermissionOptions(app, false, true)
+	options.Out = io.Discard
+	report, err := permission.SyncPermissions(options)
+	if err != nil {
+		errorf("permissions: %v", err)
+	} else if !report.Empty() {
+		warnf("%d permissions are missing or outdated, run syncpermissions", len(report.Created)+len(report.Updated))
+	}
+
+	return reportIssues(ctx.Out, issues)
+}
+
+func reportIssues(w io.Writer, issues []checkIssue) error {
+	failed := false
+	for _, issue := range issues {
+		fmt.Fprintf(w, "%s: %s\n", issue.level, issue.message)
+		failed = failed || issue.level == "ERROR"
+	}
+	if len(issues) == 0 {
+		fmt.Fprintln(w, "System check identified no issues.")
+		return nil
+	}
+	fmt.Fprintf(w, "System check identified %d issue(s).\n", len(issues))
+	if failed {
+		return errors.New("system check failed")
+	}
+	return nil
+}
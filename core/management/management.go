@@ -0,0 +1,235 @@
+package management
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"grf/core/bootstrap"
+	"grf/core/config"
+	"grf/core/database"
+	"grf/core/migrations"
+	"grf/core/models"
+	"grf/core/server"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"golang.org/x/term"
+	"gorm.io/gorm"
+)
+
+// Module is what a domain module contributes to the application: its models,
+// migrations and management commands. UserRevoker, when set, revokes the
+// credentials the module issues to a user along with the auth ones.
+type Module struct {
+	Name       string
+	Models     []interface{}
+	Migrations []*migrations.Migration
+	Commands   []*Command
+
+	UserRevoker models.UserRevoker
+}
+
+// Command is a management subcommand, run as "grf <name> [args]".
+type Command struct {
+	Name string
+	// Args documents the arguments, e.g. "<username>" or "[-prune]".
+	Args        string
+	Description string
+	Run         func(ctx *Context, args []string) error
+}
+
+// Context is shared by the commands of a run. The app and database
+// connection are created on first use.
+type Context struct {
+	Config  config.Config
+	Modules []*Module
+
+	In  io.Reader
+	Out io.Writer
+	Err io.Writer
+
+	app    *server.App
+	db     *gorm.DB
+	reader *bufio.Reader
+}
+
+func (c *Context) Models() []interface{} {
+	var models []interface{}
+	for _, module := range c.Modules {
+		models = append(models, module.Models...)
+	}
+	return models
+}
+
+func (c *Context) Migrations() []*migrations.Migration {
+	var list []*migrations.Migration
+	for _, module := range c.Modules {
+		list = append(list, module.Migrations...)
+	}
+	return list
+}
+
+func (c *Context) UserRevokers() []models.UserRevoker {
+	var revokers []models.UserRevoker
+	for _, module := range c.Modules {
+		if module.UserRevoker != nil {
+			revokers = append(revokers, module.UserRevoker)
+		}
+	}
+	return revokers
+}
+
+func (c *Context) Module(name string) (*Module, error) {
+	for _, module := range c.Modules {
+		if module.Name == name {
+			return module, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown module %q", name)
+}
+
+// App bootstraps the application with Config, which runs migrations and
+// syncs permissions as configured. Commands that must not touch the schema
+// turn those off in Config before calling it.
+func (c *Context) App() (*server.App, error) {
+	if c.app != nil {
+		return c.app, nil
+	}
+	app, err := bootstrap.NewApp(c.Config, c.Models(), c.Migrations(), c.UserRevokers()...)
+	if err != nil {
+		return nil, err
+	}
+	c.SetApp(app)
+	return app, nil
+}
+
+// SetApp reuses an app built elsewhere, e.g. by a test suite.
+func (c *Context) SetApp(app *server.App) {
+	c.app = app
+	c.db = app.DB
+}
+
+// DB connects to the database without bootstrapping the app.
+func (c *Context) DB() (*gorm.DB, error) {
+	if c.db != nil {
+		return c.db, nil
+	}
+	db, err := database.ConnectDB(&c.Config)
+	if err != nil {
+		return nil, err
+	}
+	c.db = db
+	return db, nil
+}
+
+// FlagSet returns a flag set that reports errors instead of exiting.
+func (c *Context) FlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.Err)
+	return flags
+}
+
+// Prompt prints label and reads a line from In.
+func (c *Context) Prompt(label string) (string, error) {
+	fmt.Fprint(c.Out, label)
+	if c.reader == nil {
+		c.reader = bufio.NewReader(c.In)
+	}
+	line, err := c.reader.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// PromptPassword is Prompt without echo when In is a terminal.
+func (c *Context) PromptPassword(label string) (string, error) {
+	file, ok := c.In.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return c.Prompt(label)
+	}
+	fmt.Fprint(c.Out, label)
+	password, err := term.ReadPassword(int(file.Fd()))
+	fmt.Fprintln(c.Out)
+	return string(password), err
+}
+
+// CLI dispatches the command line to the built-in and module commands.
+type CLI struct {
+	Context *Context
+
+	commands map[string]*Command
+}
+
+func New(cfg config.Config, modules ...*Module) *CLI {
+	cli := &CLI{
+		Context: &Context{
+			Config:  cfg,
+			Modules: modules,
+			In:      os.Stdin,
+			Out:     os.Stdout,
+			Err:     os.Stderr,
+		},
+		commands: make(map[string]*Command),
+	}
+	cli.Register(builtinCommands()...)
+	for _, module := range modules {
+		cli.Register(module.Commands...)
+	}
+	return cli
+}
+
+func (c *CLI) Register(commands ...*Command) {
+	for _, command := range commands {
+		if command.Name == "" || command.Run == nil {
+			panic("management: Name e Run são obrigatórios nos comandos")
+		}
+		if _, ok := c.commands[command.Name]; ok {
+			panic("management: comando duplicado " + command.Name)
+		}
+		c.commands[command.Name] = command
+	}
+}
+
+// Run executes the command named by args[0], "serve" when args is empty.
+func (c *CLI) Run(args []string) error {
+	name := "serve"
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+	switch name {
+	case "help", "-h", "-help", "--help":
+		c.Usage(c.Context.Out)
+		return nil
+	}
+
+	command, ok := c.commands[name]
+	if !ok {
+		c.Usage(c.Context.Err)
+		return fmt.Errorf("unknown command %q", name)
+	}
+	if err := command.Run(c.Context, args); err != nil && !errors.Is(err, flag.ErrHelp) {
+		return err
+	}
+	return nil
+}
+
+func (c *CLI) Usage(w io.Writer) {
+	names := make([]string, 0, len(c.commands))
+	for name := range c.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "Usage: %s <command> [args]\n\nCommands:\n", os.Args[0])
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		command := c.commands[name]
+		fmt.Fprintf(tw, "  %s\t%s\n", strings.TrimSpace(name+" "+command.Args), command.Description)
+	}
+	_ = tw.Flush()
+}
@@ -11,6 +11,14 @@ func Check(perm permission.IPermission) fiber.Handler {
 		if err := perm.Check(c); err != nil {
 			return err
 		}
+		c.Locals(permission.PermissionLocal, perm)
+		return c.Next()
+	}
+}
+
+func SetAction(action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(permission.ActionLocal, action)
 		return c.Next()
 	}
 }
@@ -0,0 +1,433 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// MakeOptions configures MakeMigrations for one domain module.
+type MakeOptions struct {
+	// DB is the database the models are compared with. It must have every
+	// migration of the module applied.
+	DB         *gorm.DB
+	Module     string
+	Models     []interface{}
+	Migrations []*Migration
+
+	// Dir receives the new files, e.g. "domain/auth/migrations".
+	Dir  string
+	Name string
+
+	// DryRun prints the up script to Out and writes nothing.
+	DryRun bool
+	Out    io.Writer
+}
+
+// Change is a single schema difference. Changes with a Review note, such as
+// renames and drops, are written commented out for a person to decide.
+type Change struct {
+	Description string
+	Up          []string
+	Down        []string
+	Review      string
+}
+
+type MakeResult struct {
+	Changes  []*Change
+	UpFile   string
+	DownFile string
+}
+
+var migrationName = regexp.MustCompile(`^\w+$`)
+
+// MakeMigrations compares the GORM schema of the models with the database
+// and writes the next numbered migration of the module for its dialect.
+//
+// It detects new tables, join tables, columns and indexes. Columns and
+// indexes missing from the models are flagged, a column removed alongside a
+// new one is flagged as a possible rename, and tables prefixed with the
+// module name (e.g. "auth_") that no model declares are flagged for
+// dropping. Column type changes aren't detected.
+func MakeMigrations(options *MakeOptions) (*MakeResult, error) {
+	if options.DB == nil || options.Module == "" {
+		return nil, errors.New("makemigrations needs DB and Module")
+	}
+	name := options.Name
+	if name == "" {
+		name = "auto"
+	}
+	if !migrationName.MatchString(name) {
+		return nil, fmt.Errorf("invalid migration name %q", name)
+	}
+
+	statuses, err := NewMigrator(options.DB, options.Migrations).Status()
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			return nil, fmt.Errorf("migration %s is pending, apply it before making new ones", status.Migration.ID())
+		}
+	}
+
+	changes, err := diffSchema(options.DB, options.Module, options.Models)
+	if err != nil {
+		return nil, err
+	}
+	result := &MakeResult{Changes: changes}
+
+	out := options.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "No changes detected.")
+		return result, nil
+	}
+
+	up, down := renderScripts(changes)
+	if options.DryRun {
+		fmt.Fprint(out, up)
+		return result, nil
+	}
+
+	version, err := nextVersion(options.Dir, options.Migrations)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(options.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	dialect := options.DB.Dialector.Name()
+	base := fmt.Sprintf("%04d_%s", version, name)
+	result.UpFile = filepath.Join(options.Dir, base+".up."+dialect+".sql")
+	result.DownFile = filepath.Join(options.Dir, base+".down."+dialect+".sql")
+	if err := os.WriteFile(result.UpFile, []byte(up), 0o644); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(result.DownFile, []byte(down), 0o644); err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		if change.Review != "" {
+			fmt.Fprintf(out, "REVIEW %s: %s\n", change.Description, change.Review)
+		} else {
+			fmt.Fprintf(out, "%s\n", change.Description)
+		}
+	}
+	fmt.Fprintf(out, "Created %s\n", result.UpFile)
+	others := slices.DeleteFunc(slices.Clone(Dialects), func(d string) bool { return d == dialect })
+	fmt.Fprintf(out, "REVIEW %s only runs on %s: write its %s scripts, or drop .%s from both file names if the SQL is portable.\n",
+		base, dialect, strings.Join(others, " and "), dialect)
+	return result, nil
+}
+
+func nextVersion(dir string, registered []*Migration) (uint, error) {
+	var version uint
+	for _, migration := range registered {
+		version = max(version, migration.Version)
+	}
+	existing, err := FromFS("", os.DirFS(dir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, err
+	}
+	for _, migration := range existing {
+		version = max(version, migration.Version)
+	}
+	return version + 1, nil
+}
+
+// renderScripts writes the up script in change order and the down script in
+// reverse, commenting out the statements of changes under review.
+func renderScripts(changes []*Change) (string, string) {
+	var up, down strings.Builder
+	write := func(b *strings.Builder, change *Change, statements []string) {
+		if len(statements) == 0 {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "-- %s\n", change.Description)
+		prefix := ""
+		if change.Review != "" {
+			fmt.Fprintf(b, "-- REVIEW: %s\n", change.Review)
+			prefix = "-- "
+		}
+		for _, statement := range statements {
+			fmt.Fprintf(b, "%s%s;\n", prefix, statement)
+		}
+	}
+
+	for _, change := range changes {
+		write(&up, change, change.Up)
+	}
+	for i := len(changes) - 1; i >= 0; i-- {
+		write(&down, changes[i], changes[i].Down)
+	}
+	return up.String(), down.String()
+}
+
+type diffTarget struct {
+	table string
+	value interface{}
+}
+
+func diffSchema(db *gorm.DB, module string, models []interface{}) ([]*Change, error) {
+	var changes []*Change
+	declared := make(map[string]bool)
+
+	for _, value := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(value); err != nil {
+			return nil, err
+		}
+
+		targets := []diffTarget{{table: stmt.Schema.Table, value: value}}
+		relations := make([]string, 0, len(stmt.Schema.Relationships.Relations))
+		for name := range stmt.Schema.Relationships.Relations {
+			relations = append(relations, name)
+		}
+		sort.Strings(relations)
+		for _, name := range relations {
+			rel := stmt.Schema.Relationships.Relations[name]
+			if rel.JoinTable != nil {
+				targets = append(targets, diffTarget{
+					table: rel.JoinTable.Table,
+					value: reflect.New(rel.JoinTable.ModelType).Interface(),
+				})
+			}
+		}
+
+		for _, target := range targets {
+			if declared[target.table] {
+				continue
+			}
+			declared[target.table] = true
+			tableChanges, err := diffTable(db, target)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", target.table, err)
+			}
+			changes = append(changes, tableChanges...)
+		}
+	}
+
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		if declared[table] || !strings.HasPrefix(table, module+"_") {
+			continue
+		}
+		changes = append(changes, &Change{
+			Description: "Drop table " + table,
+			Up:          []string{"DROP TABLE " + quote(db, table)},
+			Review:      "no model declares " + table + " anymore; dropping it deletes its data",
+		})
+	}
+	return changes, nil
+}
+
+func diffTable(db *gorm.DB, target diffTarget) ([]*Change, error) {
+	migrator := db.Table(target.table).Migrator()
+	if !migrator.HasTable(target.table) {
+		up, err := captureSQL(db, func(tx *gorm.DB) error {
+			return tx.Table(target.table).Migrator().CreateTable(target.value)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []*Change{{
+			Description: "Create table " + target.table,
+			Up:          up,
+			Down:        []string{"DROP TABLE " + quote(db, target.table)},
+		}}, nil
+	}
+
+	stmt := &gorm.Statement{DB: db, Table: target.table}
+	if err := stmt.Parse(target.value); err != nil {
+		return nil, err
+	}
+	columnTypes, err := migrator.ColumnTypes(target.value)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]bool, len(columnTypes))
+	var removed []string
+	for _, column := range columnTypes {
+		existing[column.Name()] = true
+		if field := stmt.Schema.LookUpField(column.Name()); field == nil || field.IgnoreMigration {
+			removed = append(removed, column.Name())
+		}
+	}
+	var added []*schema.Field
+	for _, dbName := range stmt.Schema.DBNames {
+		field := stmt.Schema.FieldsByDBName[dbName]
+		if !existing[dbName] && !field.IgnoreMigration {
+			added = append(added, field)
+		}
+	}
+
+	var changes []*Change
+	table := quote(db, target.table)
+	// A column dropped alongside a new one is likely a rename, which a diff
+	// can't tell apart from a drop and an add.
+	for len(added) > 0 && len(removed) > 0 {
+		from, to := removed[0], added[0].DBName
+		removed, added = removed[1:], added[1:]
+		changes = append(changes, &Change{
+			Description: fmt.Sprintf("Rename column %s.%s to %s", target.table, from, to),
+			Up:          []string{fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, quote(db, from), quote(db, to))},
+			Down:        []string{fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, quote(db, to), quote(db, from))},
+			Review:      fmt.Sprintf("%s was removed and %s added; keep the rename or replace it with a drop and an add", from, to),
+		})
+	}
+	for _, field := range added {
+		up, err := captureSQL(db, func(tx *gorm.DB) error {
+			return tx.Table(target.table).Migrator().AddColumn(target.value, field.Name)
+		})
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, &Change{
+			Description: fmt.Sprintf("Add column %s.%s", target.table, field.DBName),
+			Up:          up,
+			Down:        []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, quote(db, field.DBName))},
+		})
+	}
+	for _, column := range removed {
+		changes = append(changes, &Change{
+			Description: fmt.Sprintf("Drop column %s.%s", target.table, column),
+			Up:          []string{fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, quote(db, column))},
+			Review:      "no field maps to " + column + " anymore; dropping it deletes its data",
+		})
+	}
+
+	indexChanges, err := diffIndexes(db, target, stmt.Schema)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, indexChanges...), nil
+}
+
+func diffIndexes(db *gorm.DB, target diffTarget, s *schema.Schema) ([]*Change, error) {
+	migrator := db.Table(target.table).Migrator()
+
+	var changes []*Change
+	declared := make(map[string]bool)
+	for _, index := range s.ParseIndexes() {
+		declared[index.Name] = true
+		if migrator.HasIndex(target.value, index.Name) {
+			continue
+		}
+		up, err := captureSQL(db, func(tx *gorm.DB) error {
+			return tx.Table(target.table).Migrator().CreateIndex(target.value, index.Name)
+		})
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, &Change{
+			Description: fmt.Sprintf("Create index %s on %s", index.Name, target.table),
+			Up:          up,
+			Down:        []string{dropIndexSQL(db, target.table, index.Name)},
+		})
+	}
+
+	existing, err := migrator.GetIndexes(target.value)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range existing {
+		if declared[index.Name()] || implicitIndex(s, index) {
+			continue
+		}
+		changes = append(changes, &Change{
+			Description: fmt.Sprintf("Drop index %s on %s", index.Name(), target.table),
+			Up:          []string{dropIndexSQL(db, target.table, index.Name())},
+			Review:      "no model declares the index " + index.Name() + " anymore",
+		})
+	}
+	return changes, nil
+}
+
+// implicitIndex reports the indexes databases create on their own for
+// primary keys, unique constraints and foreign keys.
+func implicitIndex(s *schema.Schema, index gorm.Index) bool {
+	if primary, ok := index.PrimaryKey(); ok && primary {
+		return true
+	}
+	name := index.Name()
+	if name == "PRIMARY" || strings.HasPrefix(name, "sqlite_autoindex_") || name == s.Table+"_pkey" {
+		return true
+	}
+	for _, rel := range s.Relationships.Relations {
+		if constraint := rel.ParseConstraint(); constraint != nil && constraint.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func dropIndexSQL(db *gorm.DB, table string, name string) string {
+	if db.Dialector.Name() == "mysql" {
+		return fmt.Sprintf("DROP INDEX %s ON %s", quote(db, name), quote(db, table))
+	}
+	return "DROP INDEX " + quote(db, name)
+}
+
+func quote(db *gorm.DB, name string) string {
+	var b strings.Builder
+	db.Dialector.QuoteTo(&b, name)
+	return b.String()
+}
+
+// sqlRecorder collects the statements of a dry-run session instead of
+// logging them.
+type sqlRecorder struct {
+	statements []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+
+func (r *sqlRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	sql = strings.TrimSpace(sql)
+	// Migrators may look the schema up while building DDL; only keep DDL.
+	upper := strings.ToUpper(sql)
+	for _, prefix := range []string{"SELECT", "PRAGMA", "SHOW"} {
+		if strings.HasPrefix(upper, prefix) {
+			return
+		}
+	}
+	r.statements = append(r.statements, sql)
+}
+
+// captureSQL runs fn on a dry-run session and returns the SQL it built.
+func captureSQL(db *gorm.DB, fn func(tx *gorm.DB) error) ([]string, error) {
+	recorder := &sqlRecorder{}
+	if err := fn(db.Session(&gorm.Session{DryRun: true, Logger: recorder})); err != nil {
+		return nil, err
+	}
+	return recorder.statements, nil
+}
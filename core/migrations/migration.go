@@ -0,0 +1,213 @@
+package migrations
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a numbered schema change of a domain module. Up and Down run
+// inside a transaction; on mysql DDL statements commit implicitly, so a
+// failing migration may leave part of its changes applied.
+type Migration struct {
+	Module  string
+	Version uint
+	Name    string
+
+	Up func(tx *gorm.DB) error
+	// Down reverts Up. Migrations without it can't be rolled back.
+	Down func(tx *gorm.DB) error
+
+	// Dialects lists the GORM dialects Up has a script for, when it was
+	// loaded from dialect files only. Empty means it runs on any.
+	Dialects []string
+}
+
+// ID identifies the migration in logs and status output, e.g.
+// "auth.0001_initial".
+func (m *Migration) ID() string {
+	return fmt.Sprintf("%s.%04d_%s", m.Module, m.Version, m.Name)
+}
+
+// Dialects are the GORM dialects migrations may target.
+var Dialects = []string{"sqlite", "mysql", "postgres"}
+
+// Supports tells whether Up can run on the GORM dialect, e.g. "mysql".
+func (m *Migration) Supports(dialect string) bool {
+	return len(m.Dialects) == 0 || slices.Contains(m.Dialects, dialect)
+}
+
+// sqlFileName matches "0001_initial.up.sql" and its dialect variants such as
+// "0001_initial.up.mysql.sql".
+var sqlFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)(?:\.(\w+))?\.sql$`)
+
+// FromFS loads the .sql migrations at the root of fsys, usually an embed.FS
+// narrowed with fs.Sub. Each version needs an up file and may have a down
+// file. A file named after the GORM dialect (sqlite, mysql, postgres) takes
+// precedence over the generic one.
+func FromFS(module string, fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	type scripts struct {
+		name string
+		up   map[string]string
+		down map[string]string
+	}
+	byVersion := make(map[uint]*scripts)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := sqlFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid version: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		s, ok := byVersion[uint(version)]
+		if !ok {
+			s = &scripts{name: match[2], up: map[string]string{}, down: map[string]string{}}
+			byVersion[uint(version)] = s
+		} else if s.name != match[2] {
+			return nil, fmt.Errorf("%s: version %d is already named %s", entry.Name(), version, s.name)
+		}
+		if match[3] == "up" {
+			s.up[match[4]] = string(content)
+		} else {
+			s.down[match[4]] = string(content)
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for version, s := range byVersion {
+		migration := &Migration{Module: module, Version: version, Name: s.name}
+		if len(s.up) == 0 {
+			return nil, fmt.Errorf("%s has no up script", migration.ID())
+		}
+		migration.Up = runSQL(migration.ID(), s.up)
+		if _, ok := s.up[""]; !ok {
+			for dialect := range s.up {
+				migration.Dialects = append(migration.Dialects, dialect)
+			}
+			sort.Strings(migration.Dialects)
+		}
+		if len(s.down) > 0 {
+			migration.Down = runSQL(migration.ID(), s.down)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// MustFromFS is FromFS for embedded files, which can only fail on a
+// programming error.
+func MustFromFS(module string, fsys fs.FS) []*Migration {
+	migrations, err := FromFS(module, fsys)
+	if err != nil {
+		panic("migrations: falha ao carregar " + module + ": " + err.Error())
+	}
+	return migrations
+}
+
+func runSQL(id string, byDialect map[string]string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		dialect := tx.Dialector.Name()
+		script, ok := byDialect[dialect]
+		if !ok {
+			script, ok = byDialect[""]
+		}
+		if !ok {
+			return fmt.Errorf("%s has no script for %s", id, dialect)
+		}
+		for _, statement := range splitStatements(script) {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// splitStatements splits a script on the semicolons that end statements,
+// skipping those inside quotes and comments. The mysql driver rejects
+// several statements in a single Exec.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote rune
+	lineComment, blockComment := false, false
+
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	runes := []rune(script)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case lineComment:
+			if r == '\n' {
+				lineComment = false
+				current.WriteRune(r)
+			}
+			continue
+		case blockComment:
+			if r == '*' && next == '/' {
+				blockComment = false
+				i++
+			}
+			continue
+		case quote != 0:
+			current.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch {
+		case r == '-' && next == '-':
+			lineComment = true
+			i++
+		case r == '/' && next == '*':
+			blockComment = true
+			i++
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+			current.WriteRune(r)
+		case r == ';':
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return statements
+}
@@ -0,0 +1,302 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const DefaultLockTimeout = time.Minute
+
+const lockPollInterval = 200 * time.Millisecond
+
+// ErrLocked is returned when another process holds the migration lock past
+// the Migrator's LockTimeout.
+var ErrLocked = errors.New("migrations are locked by another process")
+
+// SchemaMigration is the history row of an applied migration.
+type SchemaMigration struct {
+	ID        uint64    `gorm:"primarykey"`
+	Module    string    `gorm:"size:100;not null;uniqueIndex:idx_schema_migrations_version"`
+	Version   uint      `gorm:"not null;uniqueIndex:idx_schema_migrations_version"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string { return "schema_migrations" }
+
+// schemaMigrationLock holds a single row while a process migrates. Inserting
+// it is the lock; the primary key makes concurrent inserts fail.
+type schemaMigrationLock struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement:false"`
+	Owner    string `gorm:"size:255;not null"`
+	LockedAt time.Time
+}
+
+func (schemaMigrationLock) TableName() string { return "schema_migrations_lock" }
+
+// Status is a migration and when it was applied. Unknown marks rows of
+// schema_migrations that no registered migration matches.
+type Status struct {
+	Migration *Migration
+	AppliedAt *time.Time
+	Unknown   bool
+}
+
+// Migrator applies and rolls back Migrations, recording them in the
+// schema_migrations table. Modules run in the order they first appear in
+// Migrations, each by ascending version.
+type Migrator struct {
+	DB         *gorm.DB
+	Migrations []*Migration
+
+	LockTimeout time.Duration
+}
+
+func NewMigrator(db *gorm.DB, migrations []*Migration) *Migrator {
+	moduleOrder := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, migration := range migrations {
+		if migration.Module == "" || migration.Name == "" || migration.Up == nil {
+			panic("NewMigrator: Module, Name e Up são obrigatórios nas migrations")
+		}
+		if seen[key(migration.Module, migration.Version)] {
+			panic("NewMigrator: versão duplicada em " + migration.ID())
+		}
+		seen[key(migration.Module, migration.Version)] = true
+		if _, ok := moduleOrder[migration.Module]; !ok {
+			moduleOrder[migration.Module] = len(moduleOrder)
+		}
+	}
+
+	ordered := append([]*Migration{}, migrations...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Module != ordered[j].Module {
+			return moduleOrder[ordered[i].Module] < moduleOrder[ordered[j].Module]
+		}
+		return ordered[i].Version < ordered[j].Version
+	})
+
+	return &Migrator{
+		DB:          db,
+		Migrations:  ordered,
+		LockTimeout: DefaultLockTimeout,
+	}
+}
+
+// Up applies the pending migrations and returns them.
+func (m *Migrator) Up() ([]*Migration, error) {
+	var applied []*Migration
+	err := m.withLock(func() error {
+		done, err := m.appliedRows()
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.Migrations {
+			if _, ok := done[key(migration.Module, migration.Version)]; ok {
+				continue
+			}
+			log.Printf("Applying migration %s", migration.ID())
+			err := m.DB.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{
+					Module:    migration.Module,
+					Version:   migration.Version,
+					Name:      migration.Name,
+					AppliedAt: time.Now(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %s: %w", migration.ID(), err)
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Rollback reverts the last steps applied migrations, newest first.
+func (m *Migrator) Rollback(steps int) ([]*Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("rollback needs at least 1 step, got %d", steps)
+	}
+
+	var reverted []*Migration
+	err := m.withLock(func() error {
+		var rows []*SchemaMigration
+		if err := m.DB.Order("id DESC").Limit(steps).Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			migration := m.find(row.Module, row.Version)
+			if migration == nil {
+				return fmt.Errorf("migration %s.%04d_%s is not registered", row.Module, row.Version, row.Name)
+			}
+			if migration.Down == nil {
+				return fmt.Errorf("migration %s can't be rolled back", migration.ID())
+			}
+			log.Printf("Rolling back migration %s", migration.ID())
+			err := m.DB.Transaction(func(tx *gorm.DB) error {
+				if err := migration.Down(tx); err != nil {
+					return err
+				}
+				return tx.Delete(row).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %s: %w", migration.ID(), err)
+			}
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists the registered migrations in apply order, then the applied
+// ones that aren't registered anymore.
+func (m *Migrator) Status() ([]*Status, error) {
+	if err := m.ensureTables(); err != nil {
+		return nil, err
+	}
+	done, err := m.appliedRows()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]*Status, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		status := &Status{Migration: migration}
+		if row, ok := done[key(migration.Module, migration.Version)]; ok {
+			status.AppliedAt = &row.AppliedAt
+			delete(done, key(migration.Module, migration.Version))
+		}
+		statuses = append(statuses, status)
+	}
+
+	unknown := make([]*SchemaMigration, 0, len(done))
+	for _, row := range done {
+		unknown = append(unknown, row)
+	}
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].ID < unknown[j].ID })
+	for _, row := range unknown {
+		statuses = append(statuses, &Status{
+			Migration: &Migration{Module: row.Module, Version: row.Version, Name: row.Name},
+			AppliedAt: &row.AppliedAt,
+			Unknown:   true,
+		})
+	}
+	return statuses, nil
+}
+
+// Unlock releases a lock left behind by a process that died mid-migration.
+func (m *Migrator) Unlock() error {
+	if err := m.ensureTables(); err != nil {
+		return err
+	}
+	return m.DB.Where("id = ?", 1).Delete(&schemaMigrationLock{}).Error
+}
+
+func PrintStatus(w io.Writer, statuses []*Status) {
+	if len(statuses) == 0 {
+		fmt.Fprintln(w, "No migrations.")
+		return
+	}
+	for _, status := range statuses {
+		switch {
+		case status.Unknown:
+			fmt.Fprintf(w, "[?] %s (applied %s, not registered)\n",
+				status.Migration.ID(), status.AppliedAt.Format(time.RFC3339))
+		case status.AppliedAt != nil:
+			fmt.Fprintf(w, "[X] %s (applied %s)\n",
+				status.Migration.ID(), status.AppliedAt.Format(time.RFC3339))
+		default:
+			fmt.Fprintf(w, "[ ] %s\n", status.Migration.ID())
+		}
+	}
+}
+
+func (m *Migrator) find(module string, version uint) *Migration {
+	for _, migration := range m.Migrations {
+		if migration.Module == module && migration.Version == version {
+			return migration
+		}
+	}
+	return nil
+}
+
+func key(module string, version uint) string {
+	return fmt.Sprintf("%s.%d", module, version)
+}
+
+func (m *Migrator) appliedRows() (map[string]*SchemaMigration, error) {
+	var rows []*SchemaMigration
+	if err := m.DB.Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[string]*SchemaMigration, len(rows))
+	for _, row := range rows {
+		done[key(row.Module, row.Version)] = row
+	}
+	return done, nil
+}
+
+// ensureTables creates the bookkeeping tables. A concurrent boot may create
+// them first, so a failure only counts if the table is still missing.
+func (m *Migrator) ensureTables() error {
+	for _, table := range []interface{}{&SchemaMigration{}, &schemaMigrationLock{}} {
+		migrator := m.DB.Migrator()
+		if migrator.HasTable(table) {
+			continue
+		}
+		if err := migrator.CreateTable(table); err != nil && !migrator.HasTable(table) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) withLock(fn func() error) error {
+	if err := m.ensureTables(); err != nil {
+		return err
+	}
+
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s:%d:%d", hostname, os.Getpid(), time.Now().UnixNano())
+	// Failed inserts are expected while waiting; keep them out of the log.
+	quiet := m.DB.Session(&gorm.Session{Logger: m.DB.Logger.LogMode(logger.Silent)})
+
+	deadline := time.Now().Add(m.LockTimeout)
+	for {
+		lock := &schemaMigrationLock{ID: 1, Owner: owner, LockedAt: time.Now()}
+		if err := quiet.Create(lock).Error; err == nil {
+			break
+		}
+
+		var holder schemaMigrationLock
+		err := quiet.Where("id = ?", 1).Limit(1).Find(&holder).Error
+		if err != nil {
+			return err
+		}
+		if holder.ID == 0 {
+			// Released between the insert and the lookup.
+			continue
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w: held by %s since %s", ErrLocked, holder.Owner, holder.LockedAt.Format(time.RFC3339))
+		}
+		time.Sleep(lockPollInterval)
+	}
+
+	defer m.DB.Where("id = ? AND owner = ?", 1, owner).Delete(&schemaMigrationLock{})
+	return fn()
+}
@@ -6,3 +6,6 @@ const CreateAction = "create"
 const UpdateAction = "update"
 const PartialUpdateAction = "partialupdate"
 const DeleteAction = "delete"
+
+var CRUDActions = []string{ListAction, DetailAction, CreateAction, UpdateAction, PartialUpdateAction, DeleteAction}
+var ReadOnlyActions = []string{ListAction, DetailAction}
@@ -1,6 +1,10 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type IUser interface {
 	Active() bool
@@ -9,3 +13,36 @@ type IUser interface {
 
 	HasPerm(db *gorm.DB, module string, action string) bool
 }
+
+// IObjectPermUser is implemented by users that can hold per-object grants.
+type IObjectPermUser interface {
+	HasObjectPerm(db *gorm.DB, module string, action string, obj IModel) bool
+}
+
+// IPermissionSetUser is implemented by users whose effective permissions can
+// be loaded at once and cached under PermissionCacheKey.
+type IPermissionSetUser interface {
+	IUser
+
+	PermissionCacheKey() string
+	EffectivePermissions(db *gorm.DB) (*PermissionSet, error)
+}
+
+// UserRevoker revokes the credentials a module issued to a user whose own
+// are revoked, e.g. the OAuth2 tokens after a password change.
+type UserRevoker func(db *gorm.DB, userID uint64, now time.Time) error
+
+// CustomPermission is an extra permission of a model beyond the CRUD
+// actions, e.g. {Action: "export"} for the codename "user.export".
+type CustomPermission struct {
+	Action      string
+	Description string
+}
+
+// ICustomPermissionsModel is implemented by models that declare permissions
+// besides the CRUD ones. They are registered with the model's permissions.
+type ICustomPermissionsModel interface {
+	IModel
+
+	CustomPermissions() []CustomPermission
+}
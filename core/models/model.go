@@ -1,7 +1,33 @@
 package models
 
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
 type IModel interface {
 	TableName() string
 
 	ModuleName() string
 }
+
+// PrimaryKey returns the primary key of obj as a string, the form used to
+// store object-level grants.
+func PrimaryKey(db *gorm.DB, obj IModel) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(obj); err != nil {
+		return "", err
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return "", fmt.Errorf("%s não possui chave primária", obj.TableName())
+	}
+	value, isZero := field.ValueOf(context.Background(), reflect.ValueOf(obj))
+	if isZero {
+		return "", fmt.Errorf("%s sem chave primária definida", obj.TableName())
+	}
+	return fmt.Sprint(value), nil
+}
@@ -0,0 +1,24 @@
+package models
+
+// PermissionSet is the effective set of "module.action" codenames of a user.
+// All is set for superusers.
+type PermissionSet struct {
+	All       bool
+	Codenames map[string]struct{}
+}
+
+func NewPermissionSet(codenames ...string) *PermissionSet {
+	set := &PermissionSet{Codenames: make(map[string]struct{}, len(codenames))}
+	for _, codename := range codenames {
+		set.Codenames[codename] = struct{}{}
+	}
+	return set
+}
+
+func (s *PermissionSet) Has(module string, action string) bool {
+	if s.All {
+		return true
+	}
+	_, ok := s.Codenames[module+"."+action]
+	return ok
+}
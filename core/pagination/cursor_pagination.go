@@ -1,11 +1,21 @@
 package pagination
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"grf/core/exceptions"
+	"grf/core/filterset"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 type CursorPagination[T any] struct {
@@ -14,8 +24,9 @@ type CursorPagination[T any] struct {
 	OrderByColumn  string
 	OrderDirection string
 
-	limit  int
-	cursor string
+	limit    int
+	cursor   string
+	ordering []filterset.OrderBy
 }
 
 func NewCursorPagination[T any](defaultLimit, maxLimit int, column, direction string) *CursorPagination[T] {
@@ -39,6 +50,15 @@ func NewCursorPagination[T any](defaultLimit, maxLimit int, column, direction st
 	}
 }
 
+func (p *CursorPagination[T]) Clone() IPagination[T] {
+	return &CursorPagination[T]{
+		DefaultLimit:   p.DefaultLimit,
+		MaxLimit:       p.MaxLimit,
+		OrderByColumn:  p.OrderByColumn,
+		OrderDirection: p.OrderDirection,
+	}
+}
+
 func (p *CursorPagination[T]) Bind(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", strconv.Itoa(p.DefaultLimit)))
 	cursor := c.Query("cursor", "")
@@ -55,19 +75,36 @@ func (p *CursorPagination[T]) Bind(c *fiber.Ctx) error {
 	return nil
 }
 
-func (p *CursorPagination[T]) Paginate(db *gorm.DB) (*Response[T], error) {
-	direction := "asc"
-	comparison := ">"
-	if p.OrderDirection == "DESC" {
-		direction = "desc"
-		comparison = "<"
-	}
+// SetOrdering replaces OrderByColumn with the requested fields.
+func (p *CursorPagination[T]) SetOrdering(ordering []filterset.OrderBy) {
+	p.ordering = ordering
+}
 
+// Paginate orders by the requested columns and then the primary key, so the
+// order is total even on columns with repeated values. The cursor holds the
+// values of all of them in the last row, and the next page starts after that
+// row. A narrowed SELECT gets the ordering columns back, since the cursor is
+// read from them.
+func (p *CursorPagination[T]) Paginate(db *gorm.DB) (*Response[T], error) {
 	var results []T
-	query := db.Order(p.OrderByColumn + " " + direction)
+	ordering, fields, err := p.resolveOrdering(db, &results)
+	if err != nil {
+		return nil, err
+	}
 
+	query := db
+	if len(db.Statement.Selects) > 0 {
+		query = query.Select(selectWith(db.Statement.Selects, fields))
+	}
+	for _, order := range ordering {
+		query = query.Order(order.Clause())
+	}
 	if p.cursor != "" {
-		query = query.Where(p.OrderByColumn+" "+comparison+" ?", p.cursor)
+		values, err := decodeCursor(p.cursor, fields)
+		if err != nil {
+			return nil, exceptions.NewBadRequest("invalid_cursor", err)
+		}
+		query = query.Where(after(ordering, values))
 	}
 
 	if err := query.Limit(p.limit + 1).Find(&results).Error; err != nil {
@@ -79,6 +116,10 @@ func (p *CursorPagination[T]) Paginate(db *gorm.DB) (*Response[T], error) {
 	if len(results) > p.limit {
 		resp.HasNext = true
 		resp.Results = results[:p.limit]
+		resp.NextCursor, err = encodeCursor(db, fields, resp.Results[p.limit-1])
+		if err != nil {
+			return nil, err
+		}
 	} else {
 		resp.HasNext = false
 		resp.Results = results
@@ -86,3 +127,103 @@ func (p *CursorPagination[T]) Paginate(db *gorm.DB) (*Response[T], error) {
 
 	return resp, nil
 }
+
+// resolveOrdering appends the primary key to the ordering, unless already
+// there, and finds the model field of each column.
+func (p *CursorPagination[T]) resolveOrdering(db *gorm.DB, model interface{}) ([]filterset.OrderBy, []*schema.Field, error) {
+	ordering := p.ordering
+	if len(ordering) == 0 {
+		ordering = []filterset.OrderBy{{Column: p.OrderByColumn, Desc: p.OrderDirection == "DESC"}}
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, nil, err
+	}
+	if pk := stmt.Schema.PrioritizedPrimaryField; pk != nil {
+		ordered := false
+		for _, order := range ordering {
+			ordered = ordered || order.Column == pk.DBName
+		}
+		if !ordered {
+			ordering = append(ordering[:len(ordering):len(ordering)], filterset.OrderBy{Column: pk.DBName, Desc: ordering[0].Desc})
+		}
+	}
+
+	fields := make([]*schema.Field, len(ordering))
+	for i, order := range ordering {
+		if fields[i] = stmt.Schema.LookUpField(order.Column); fields[i] == nil {
+			return nil, nil, fmt.Errorf("cursor pagination: %s has no column %q", stmt.Schema.Name, order.Column)
+		}
+	}
+	return ordering, fields, nil
+}
+
+// selectWith adds the columns of fields missing from selects.
+func selectWith(selects []string, fields []*schema.Field) []string {
+	columns := append([]string{}, selects...)
+	for _, field := range fields {
+		if !slices.Contains(columns, field.DBName) {
+			columns = append(columns, field.DBName)
+		}
+	}
+	return columns
+}
+
+// after matches the rows that come after values in ordering:
+// c1 > v1 OR (c1 = v1 AND c2 > v2) OR ..., with < for descending columns.
+func after(ordering []filterset.OrderBy, values []interface{}) clause.Expression {
+	branches := make([]clause.Expression, len(ordering))
+	for i, order := range ordering {
+		conditions := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, clause.Eq{Column: clause.Column{Name: ordering[j].Column}, Value: values[j]})
+		}
+		column := clause.Column{Name: order.Column}
+		if order.Desc {
+			conditions = append(conditions, clause.Lt{Column: column, Value: values[i]})
+		} else {
+			conditions = append(conditions, clause.Gt{Column: column, Value: values[i]})
+		}
+		branches[i] = clause.And(conditions...)
+	}
+	return clause.Or(branches...)
+}
+
+func encodeCursor(db *gorm.DB, fields []*schema.Field, last interface{}) (string, error) {
+	row := reflect.Indirect(reflect.ValueOf(last))
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		values[i], _ = field.ValueOf(db.Statement.Context, row)
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor reads each value back into the type of its field, so times
+// and numbers are compared as such.
+func decodeCursor(cursor string, fields []*schema.Field) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	if len(raw) != len(fields) {
+		return nil, errors.New("the cursor belongs to another ordering")
+	}
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		value := reflect.New(field.FieldType)
+		if err := json.Unmarshal(raw[i], value.Interface()); err != nil {
+			return nil, err
+		}
+		values[i] = value.Elem().Interface()
+	}
+	return values, nil
+}
@@ -1,6 +1,7 @@
 package pagination
 
 import (
+	"grf/core/filterset"
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,8 +12,9 @@ type LimitOffsetPagination[T any] struct {
 	DefaultLimit int
 	MaxLimit     int
 
-	limit  int
-	offset int
+	limit    int
+	offset   int
+	ordering []filterset.OrderBy
 }
 
 func NewLimitOffsetPagination[T any](defaultLimit, maxLimit int) *LimitOffsetPagination[T] {
@@ -28,6 +30,13 @@ func NewLimitOffsetPagination[T any](defaultLimit, maxLimit int) *LimitOffsetPag
 	}
 }
 
+func (p *LimitOffsetPagination[T]) Clone() IPagination[T] {
+	return &LimitOffsetPagination[T]{
+		DefaultLimit: p.DefaultLimit,
+		MaxLimit:     p.MaxLimit,
+	}
+}
+
 func (p *LimitOffsetPagination[T]) Bind(c *fiber.Ctx) error {
 	limit, _ := strconv.Atoi(c.Query("limit", strconv.Itoa(p.DefaultLimit)))
 	offset, _ := strconv.Atoi(c.Query("offset", "0"))
@@ -47,6 +56,10 @@ func (p *LimitOffsetPagination[T]) Bind(c *fiber.Ctx) error {
 	return nil
 }
 
+func (p *LimitOffsetPagination[T]) SetOrdering(ordering []filterset.OrderBy) {
+	p.ordering = ordering
+}
+
 func (p *LimitOffsetPagination[T]) Paginate(db *gorm.DB) (*Response[T], error) {
 	resp := &Response[T]{}
 	var results []T
@@ -58,7 +71,12 @@ func (p *LimitOffsetPagination[T]) Paginate(db *gorm.DB) (*Response[T], error) {
 	countUint := uint(totalCount)
 	resp.Count = &countUint
 
-	if err := db.Limit(p.limit).Offset(p.offset).Find(&results).Error; err != nil {
+	query := db
+	for _, order := range p.ordering {
+		query = query.Order(order.Clause())
+	}
+
+	if err := query.Limit(p.limit).Offset(p.offset).Find(&results).Error; err != nil {
 		return nil, err
 	}
 
@@ -1,6 +1,8 @@
 package pagination
 
 import (
+	"grf/core/filterset"
+
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
@@ -9,10 +11,22 @@ type Response[T any] struct {
 	Results []T   `json:"results"`
 	HasNext bool  `json:"has_next"`
 	Count   *uint `json:"count"`
+	// NextCursor is set by CursorPagination when HasNext, to be sent back as
+	// ?cursor= for the next page.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
+// IPagination holds the state of one request. Controllers bind a Clone of
+// their paginator per request, as concurrent requests would race on it.
 type IPagination[T any] interface {
+	Clone() IPagination[T]
 	Bind(c *fiber.Ctx) error
 
 	Paginate(db *gorm.DB) (*Response[T], error)
 }
+
+// IOrderedPagination is implemented by paginators that apply the ordering
+// requested through a filterset.OrderingFilter themselves.
+type IOrderedPagination interface {
+	SetOrdering(ordering []filterset.OrderBy)
+}
@@ -0,0 +1,111 @@
+package permission
+
+import (
+	"container/list"
+	"grf/core/models"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultCacheSize = 1024
+	DefaultCacheTTL  = time.Minute
+)
+
+// ICache stores effective permission sets by PermissionCacheKey. Swap the
+// in-memory LRUCache for a shared one when running several instances.
+type ICache interface {
+	Get(key string) (*models.PermissionSet, bool)
+	Set(key string, set *models.PermissionSet)
+	Delete(key string)
+	Clear()
+}
+
+type cacheEntry struct {
+	key       string
+	set       *models.PermissionSet
+	expiresAt time.Time
+}
+
+// LRUCache keeps up to Size sets, each for at most TTL.
+type LRUCache struct {
+	Size int
+	TTL  time.Duration
+
+	mu    sync.Mutex
+	order *list.List
+	items map[string]*list.Element
+}
+
+func NewLRUCache(size int, ttl time.Duration) *LRUCache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &LRUCache{
+		Size:  size,
+		TTL:   ttl,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUCache) Get(key string) (*models.PermissionSet, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.removeElement(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.set, true
+}
+
+func (c *LRUCache) Set(key string, set *models.PermissionSet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.TTL)
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		entry.set = set
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, set: set, expiresAt: expiresAt})
+	for c.order.Len() > c.Size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+func (c *LRUCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+func (c *LRUCache) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*cacheEntry).key)
+}
@@ -2,6 +2,7 @@ package permission
 
 import (
 	"grf/core/exceptions"
+	"grf/core/models"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -49,9 +50,45 @@ func NewNot(perm IPermission) IPermission {
 	return &Not{Perm: perm}
 }
 
+// Check negates Perm. When Perm checks records, the decision waits for
+// CheckObject instead.
 func (n *Not) Check(c *fiber.Ctx) error {
-	err := n.Perm.Check(c)
-	if err == nil {
+	if requiresObjectCheck(n.Perm) {
+		return nil
+	}
+	if err := n.Perm.Check(c); err == nil {
+		return exceptions.NewForbidden("permission_denied", nil)
+	}
+	return nil
+}
+
+func (a *And) CheckObject(c *fiber.Ctx, obj models.IModel) error {
+	for _, perm := range a.Perms {
+		if err := checkObject(perm, c, obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckObject passes when a single member passes both Check and CheckObject.
+func (o *Or) CheckObject(c *fiber.Ctx, obj models.IModel) error {
+	for _, perm := range o.Perms {
+		if perm.Check(c) == nil && checkObject(perm, c, obj) == nil {
+			return nil
+		}
+	}
+
+	return exceptions.NewForbidden("permission_denied", nil)
+}
+
+// CheckObject denies the records Perm allows, Check included. Permissions
+// that don't check records were already negated by Check.
+func (n *Not) CheckObject(c *fiber.Ctx, obj models.IModel) error {
+	if !requiresObjectCheck(n.Perm) {
+		return nil
+	}
+	if n.Perm.Check(c) == nil && checkObject(n.Perm, c, obj) == nil {
 		return exceptions.NewForbidden("permission_denied", nil)
 	}
 	return nil
@@ -0,0 +1,54 @@
+package permission
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Describe renders a permission tree for humans, e.g.
+// "IsAuthenticated & ModelPermissions(user)". Nil means no check.
+func Describe(perm IPermission) string {
+	switch p := perm.(type) {
+	case nil:
+		return "AllowAny"
+	case *And:
+		return describeAll(p.Perms, " & ")
+	case *Or:
+		return describeAll(p.Perms, " | ")
+	case *Not:
+		return "!" + describeNested(p.Perm)
+	case *ModelPermissions:
+		return "ModelPermissions(" + p.Model.ModuleName() + ")"
+	case *ObjectPermissions:
+		return "ObjectPermissions(" + p.Model.ModuleName() + ")"
+	case *HasPermission:
+		return "HasPermission(" + p.Module + "." + p.Action + ")"
+	case *HasScope:
+		return "HasScope(" + strings.Join(p.Scopes, ", ") + ")"
+	case *IsOwner:
+		return "IsOwner(" + p.OwnerField + ")"
+	}
+
+	t := reflect.TypeOf(perm)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+func describeAll(perms []IPermission, separator string) string {
+	parts := make([]string, len(perms))
+	for i, perm := range perms {
+		parts[i] = describeNested(perm)
+	}
+	return strings.Join(parts, separator)
+}
+
+// describeNested wraps composites in parentheses so precedence stays visible.
+func describeNested(perm IPermission) string {
+	switch perm.(type) {
+	case *And, *Or:
+		return "(" + Describe(perm) + ")"
+	}
+	return Describe(perm)
+}
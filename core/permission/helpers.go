@@ -35,29 +35,19 @@ func IsReadOnlyMethod(method string) bool {
 	}
 }
 
-func getActionForContext(c *fiber.Ctx) (string, error) {
-	method := c.Method()
-	hasId := c.Params("id", "") != ""
+// ActionLocal holds the action of the matched route, set by
+// middleware.SetAction when the route is mounted.
+const ActionLocal = "action"
 
-	switch method {
+func getActionForContext(c *fiber.Ctx) (string, error) {
+	switch c.Method() {
 	case fiber.MethodHead, fiber.MethodOptions:
 		return "", nil
-	case fiber.MethodGet:
-		{
-			if hasId {
-				return models.DetailAction, nil
-			}
-			return models.ListAction, nil
-		}
-	case fiber.MethodPost:
-		return models.CreateAction, nil
-	case fiber.MethodPut:
-		return models.UpdateAction, nil
-	case fiber.MethodPatch:
-		return models.PartialUpdateAction, nil
-	case fiber.MethodDelete:
-		return models.DeleteAction, nil
-	default:
-		return "", errors.New("método HTTP não suportado: " + method)
 	}
+
+	action, ok := c.Locals(ActionLocal).(string)
+	if !ok || action == "" {
+		return "", errors.New("ação não definida para a rota " + c.Method() + " " + c.Route().Path)
+	}
+	return action, nil
 }
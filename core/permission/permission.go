@@ -1,9 +1,72 @@
 package permission
 
 import (
+	"grf/core/models"
+
 	"github.com/gofiber/fiber/v2"
 )
 
 type IPermission interface {
 	Check(c *fiber.Ctx) error
 }
+
+// IObjectPermission is implemented by permissions that also check the loaded
+// record. GenericController calls it after GetByID on detail routes.
+type IObjectPermission interface {
+	CheckObject(c *fiber.Ctx, obj models.IModel) error
+}
+
+// PermissionLocal holds the permission that guarded the matched route.
+const PermissionLocal = "permission"
+
+// CheckObject runs the object check of the route permission, if it has one.
+func CheckObject(c *fiber.Ctx, obj models.IModel) error {
+	perm, ok := c.Locals(PermissionLocal).(IPermission)
+	if !ok {
+		return nil
+	}
+	return checkObject(perm, c, obj)
+}
+
+// RequiresObjectCheck reports whether the route permission checks records,
+// so callers can skip loading one when it doesn't.
+func RequiresObjectCheck(c *fiber.Ctx) bool {
+	perm, ok := c.Locals(PermissionLocal).(IPermission)
+	return ok && requiresObjectCheck(perm)
+}
+
+// HasObjectCheck reports whether perm checks records, for routes that must
+// refuse permissions they can't apply, such as bulk ones.
+func HasObjectCheck(perm IPermission) bool {
+	return requiresObjectCheck(perm)
+}
+
+func checkObject(perm IPermission, c *fiber.Ctx, obj models.IModel) error {
+	objectPerm, ok := perm.(IObjectPermission)
+	if !ok {
+		return nil
+	}
+	return objectPerm.CheckObject(c, obj)
+}
+
+func requiresObjectCheck(perm IPermission) bool {
+	switch p := perm.(type) {
+	case *And:
+		return anyRequiresObjectCheck(p.Perms)
+	case *Or:
+		return anyRequiresObjectCheck(p.Perms)
+	case *Not:
+		return requiresObjectCheck(p.Perm)
+	}
+	_, ok := perm.(IObjectPermission)
+	return ok
+}
+
+func anyRequiresObjectCheck(perms []IPermission) bool {
+	for _, perm := range perms {
+		if requiresObjectCheck(perm) {
+			return true
+		}
+	}
+	return false
+}
@@ -6,6 +6,9 @@ import (
 	"grf/core/auth"
 	"grf/core/exceptions"
 	"grf/core/models"
+	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -63,6 +66,9 @@ func (p *IsAuthenticated) Check(c *fiber.Ctx) error {
 	return exceptions.NewUnauthorized("auth_invalid_or_not_provided", nil)
 }
 
+// IsAdmin requires an admin user signed in with first-party credentials.
+// API keys and OAuth2 tokens never act as admin, whatever their scopes, so
+// a leaked key can't manage users, permissions or other credentials.
 type IsAdmin struct {
 }
 
@@ -71,15 +77,42 @@ func (p *IsAdmin) Check(c *fiber.Ctx) error {
 	if err != nil {
 		return err
 	}
+	if err := checkFirstParty(c); err != nil {
+		return err
+	}
 	if !user.Admin() {
 		return exceptions.NewForbidden("admin_required", nil) // 403 Forbidden
 	}
 	return nil
 }
 
+// IsFirstParty requires an authenticated user signed in with their own
+// credentials, such as a session or JWT. It guards the routes that manage
+// credentials, which scoped ones like API keys and OAuth2 tokens must not
+// reach.
+type IsFirstParty struct{}
+
+func (p *IsFirstParty) Check(c *fiber.Ctx) error {
+	if _, err := GetUser(c); err != nil {
+		return err
+	}
+	return checkFirstParty(c)
+}
+
+func checkFirstParty(c *fiber.Ctx) error {
+	if c.Locals(auth.ScopesLocal) != nil || c.Locals(auth.APIKeyLocal) != nil || c.Locals(auth.OAuth2TokenLocal) != nil {
+		return exceptions.NewForbidden("scoped_credentials_not_allowed", nil)
+	}
+	return nil
+}
+
 type ModelPermissions struct {
 	DB    *gorm.DB
 	Model models.IModel
+
+	// Resolver caches the user's permissions; without it every check
+	// queries the database.
+	Resolver *Resolver
 }
 
 func NewModelPermissions(db *gorm.DB, model models.IModel) *ModelPermissions {
@@ -102,9 +135,224 @@ func (p *ModelPermissions) Check(c *fiber.Ctx) error {
 	if action == "" {
 		return nil
 	}
-	if !user.HasPerm(p.DB, p.Model.ModuleName(), action) {
+	if !hasPerm(c, p.Resolver, p.DB, user, p.Model.ModuleName(), action) {
+		permKey := p.Model.ModuleName() + "." + action
+		return exceptions.NewForbidden(fmt.Sprintf("error_auth_permission_denied %s", permKey), nil)
+	}
+	return nil
+}
+
+// HasPermission requires a single codename, such as a custom model
+// permission like "user.export", whatever the route action is.
+type HasPermission struct {
+	DB     *gorm.DB
+	Module string
+	Action string
+
+	Resolver *Resolver
+}
+
+func NewHasPermission(db *gorm.DB, codename string) *HasPermission {
+	module, action, ok := strings.Cut(codename, ".")
+	if !ok || module == "" || action == "" {
+		panic("HasPermission: codename inválido " + codename)
+	}
+	return &HasPermission{
+		DB:     db,
+		Module: module,
+		Action: action,
+	}
+}
+
+func (p *HasPermission) Check(c *fiber.Ctx) error {
+	user, err := GetUser(c)
+	if err != nil {
+		return err
+	}
+	if !hasPerm(c, p.Resolver, p.DB, user, p.Module, p.Action) {
+		permKey := p.Module + "." + p.Action
+		return exceptions.NewForbidden(fmt.Sprintf("error_auth_permission_denied %s", permKey), nil)
+	}
+	return nil
+}
+
+// HasScope requires an authenticated user whose credentials grant every
+// scope. Only credentials limited to scopes, such as API keys, are checked;
+// a user's own session may do whatever the user may. Routes without it
+// accept scoped credentials as if they were the user's own, except those
+// guarded by IsAdmin or IsFirstParty.
+type HasScope struct {
+	Scopes []string
+}
+
+func NewHasScope(scopes ...string) *HasScope {
+	if len(scopes) == 0 {
+		panic("HasScope requer pelo menos um escopo")
+	}
+	return &HasScope{Scopes: scopes}
+}
+
+func (p *HasScope) Check(c *fiber.Ctx) error {
+	if _, err := GetUser(c); err != nil {
+		return err
+	}
+	granted, ok := c.Locals(auth.ScopesLocal).([]string)
+	if !ok {
+		return nil
+	}
+	for _, scope := range p.Scopes {
+		if !slices.Contains(granted, scope) {
+			return exceptions.NewForbidden("insufficient_scope", nil).
+				WithTemplateData(map[string]interface{}{"Scope": scope})
+		}
+	}
+	return nil
+}
+
+// IsOwner allows access to records whose OwnerField matches the UserField of
+// the authenticated user, e.g. NewIsOwner("ID") on users or "AuthorID" on posts.
+type IsOwner struct {
+	OwnerField string
+	UserField  string
+}
+
+func NewIsOwner(ownerField string) *IsOwner {
+	if ownerField == "" {
+		panic("IsOwner requer OwnerField")
+	}
+	return &IsOwner{OwnerField: ownerField, UserField: "ID"}
+}
+
+func (p *IsOwner) Check(c *fiber.Ctx) error {
+	_, err := GetUser(c)
+	return err
+}
+
+func (p *IsOwner) CheckObject(c *fiber.Ctx, obj models.IModel) error {
+	user, err := GetUser(c)
+	if err != nil {
+		return err
+	}
+
+	owner, err := fieldValue(obj, p.OwnerField)
+	if err != nil {
+		return exceptions.NewInternal(err)
+	}
+	userID, err := fieldValue(user, p.UserField)
+	if err != nil {
+		return exceptions.NewInternal(err)
+	}
+
+	if !owner.IsValid() || !userID.IsValid() {
+		return exceptions.NewForbidden("permission_denied", nil)
+	}
+	if owner.Type() != userID.Type() {
+		if !owner.CanConvert(userID.Type()) {
+			return exceptions.NewForbidden("permission_denied", nil)
+		}
+		owner = owner.Convert(userID.Type())
+	}
+	if !owner.Equal(userID) {
+		return exceptions.NewForbidden("permission_denied", nil)
+	}
+	return nil
+}
+
+// fieldValue returns the named struct field, dereferencing pointers. A nil
+// pointer gives the zero reflect.Value.
+func fieldValue(obj interface{}, name string) (reflect.Value, error) {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return reflect.Value{}, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%T não é uma struct", obj)
+	}
+
+	field := v.FieldByName(name)
+	if !field.IsValid() {
+		return reflect.Value{}, fmt.Errorf("%T não possui o campo %s", obj, name)
+	}
+	for field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return reflect.Value{}, nil
+		}
+		field = field.Elem()
+	}
+	return field, nil
+}
+
+// ObjectPermissions allows an action when the user holds the model-wide
+// permission or, on the built-in detail routes, a grant on the record itself.
+type ObjectPermissions struct {
+	DB    *gorm.DB
+	Model models.IModel
+
+	Resolver *Resolver
+}
+
+// objectActions are the detail actions GenericController checks with
+// CheckObject.
+var objectActions = []string{models.DetailAction, models.UpdateAction, models.PartialUpdateAction, models.DeleteAction}
+
+func NewObjectPermissions(db *gorm.DB, model models.IModel) *ObjectPermissions {
+	return &ObjectPermissions{
+		DB:    db,
+		Model: model,
+	}
+}
+
+func (p *ObjectPermissions) Check(c *fiber.Ctx) error {
+	user, err := GetUser(c)
+	if err != nil {
+		return err
+	}
+
+	action, err := getActionForContext(c)
+	if err != nil {
+		return exceptions.NewInternal(err)
+	}
+	// The built-in detail routes are decided by CheckObject once the record
+	// is loaded. Custom actions need the model-wide permission, as their
+	// handlers may never load one.
+	if action == "" || (c.Params("id") != "" && slices.Contains(objectActions, action)) {
+		return nil
+	}
+	if !hasPerm(c, p.Resolver, p.DB, user, p.Model.ModuleName(), action) {
 		permKey := p.Model.ModuleName() + "." + action
 		return exceptions.NewForbidden(fmt.Sprintf("error_auth_permission_denied %s", permKey), nil)
 	}
 	return nil
 }
+
+func (p *ObjectPermissions) CheckObject(c *fiber.Ctx, obj models.IModel) error {
+	user, err := GetUser(c)
+	if err != nil {
+		return err
+	}
+
+	action, err := getActionForContext(c)
+	if err != nil {
+		return exceptions.NewInternal(err)
+	}
+	if action == "" || hasPerm(c, p.Resolver, p.DB, user, p.Model.ModuleName(), action) {
+		return nil
+	}
+	if objectUser, ok := user.(models.IObjectPermUser); ok &&
+		objectUser.HasObjectPerm(p.DB, p.Model.ModuleName(), action, obj) {
+		return nil
+	}
+
+	permKey := p.Model.ModuleName() + "." + action
+	return exceptions.NewForbidden(fmt.Sprintf("error_auth_permission_denied %s", permKey), nil)
+}
+
+func hasPerm(c *fiber.Ctx, resolver *Resolver, db *gorm.DB, user models.IUser, module string, action string) bool {
+	if resolver != nil {
+		return resolver.HasPerm(c, user, module, action)
+	}
+	return user.HasPerm(db, module, action)
+}
@@ -1,34 +1,211 @@
 package permission
 
 import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
 	basemodels "grf/core/models"
-	"grf/core/repository"
 	"grf/domain/auth/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Options struct {
-	DB     *gorm.DB
-	Models []interface{}
+	DB      *gorm.DB
+	Models  []interface{}
+	Actions []ActionDefinition
+
+	ModelActions map[string][]string
+
+	// Prune deletes permissions no model or action declares anymore,
+	// detaching them from groups, users and object grants first.
+	Prune bool
+	// DryRun prints the planned changes to Out and writes nothing.
+	DryRun bool
+	Out    io.Writer
+
+	// Resolver is cleared after the sync, which detaches pruned
+	// permissions with raw SQL its hooks don't see.
+	Resolver *Resolver
+}
+
+// ActionDefinition is the permission row of a custom controller action.
+type ActionDefinition struct {
+	Module      string
+	Action      string
+	Description string
+}
+
+// SyncReport lists the changes made, or planned on a dry run, by
+// SyncPermissions.
+type SyncReport struct {
+	Created []*model.Permission
+	Updated []*model.Permission
+	Pruned  []*model.Permission
+}
+
+func (r *SyncReport) Empty() bool {
+	return len(r.Created) == 0 && len(r.Updated) == 0 && len(r.Pruned) == 0
+}
+
+func (r *SyncReport) Print(w io.Writer) {
+	if r.Empty() {
+		fmt.Fprintln(w, "Permissions are up to date.")
+		return
+	}
+	for _, perm := range r.Created {
+		fmt.Fprintf(w, "+ %s.%s: %s\n", perm.Module, perm.Action, perm.Description)
+	}
+	for _, perm := range r.Updated {
+		fmt.Fprintf(w, "~ %s.%s: %s\n", perm.Module, perm.Action, perm.Description)
+	}
+	for _, perm := range r.Pruned {
+		fmt.Fprintf(w, "- %s.%s\n", perm.Module, perm.Action)
+	}
 }
 
+// RegisterPermissions syncs the permissions at startup and panics on failure.
 func RegisterPermissions(options *Options) {
-	repo := repository.NewGenericRepository[*model.Permission, int64](&repository.Config[*model.Permission, int64]{
-		DB:       options.DB,
-		NewModel: func() *model.Permission { return new(model.Permission) },
+	report, err := SyncPermissions(options)
+	if err != nil {
+		panic(err)
+	}
+	if !options.DryRun && !report.Empty() {
+		log.Printf("Permissions synced: %d created, %d updated, %d pruned",
+			len(report.Created), len(report.Updated), len(report.Pruned))
+	}
+}
+
+// SyncPermissions upserts the permissions declared by Models and Actions and
+// updates changed descriptions. Running it again is a no-op.
+func SyncPermissions(options *Options) (*SyncReport, error) {
+	desired := declaredPermissions(options)
+
+	var existing []*model.Permission
+	if err := options.DB.Order("module, action").Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	current := make(map[string]*model.Permission, len(existing))
+	for _, perm := range existing {
+		current[perm.Module+"."+perm.Action] = perm
+	}
+
+	report := &SyncReport{}
+	declared := make(map[string]bool, len(desired))
+	for _, perm := range desired {
+		codename := perm.Module + "." + perm.Action
+		declared[codename] = true
+
+		found, ok := current[codename]
+		if !ok {
+			report.Created = append(report.Created, perm)
+		} else if found.Description != perm.Description {
+			found.Description = perm.Description
+			report.Updated = append(report.Updated, found)
+		}
+	}
+	if options.Prune {
+		for _, perm := range existing {
+			if !declared[perm.Module+"."+perm.Action] {
+				report.Pruned = append(report.Pruned, perm)
+			}
+		}
+	}
+
+	if options.DryRun {
+		out := options.Out
+		if out == nil {
+			out = os.Stdout
+		}
+		report.Print(out)
+		return report, nil
+	}
+	if report.Empty() {
+		return report, nil
+	}
+
+	err := options.DB.Transaction(func(tx *gorm.DB) error {
+		if len(report.Created) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "module"}, {Name: "action"}},
+				DoUpdates: clause.AssignmentColumns([]string{"description", "updated_at"}),
+			}).Create(&report.Created).Error
+			if err != nil {
+				return err
+			}
+		}
+		for _, perm := range report.Updated {
+			if err := tx.Model(perm).Update("description", perm.Description).Error; err != nil {
+				return err
+			}
+		}
+		if len(report.Pruned) > 0 {
+			return prunePermissions(tx, report.Pruned)
+		}
+		return nil
 	})
-	var perms []*model.Permission
+	if err != nil {
+		return nil, err
+	}
+	if options.Resolver != nil {
+		options.Resolver.Invalidate()
+	}
+	return report, nil
+}
+
+func prunePermissions(tx *gorm.DB, perms []*model.Permission) error {
+	ids := make([]uint64, len(perms))
+	for i, perm := range perms {
+		ids[i] = perm.ID
+	}
+	for _, table := range []string{"auth_group_permissions", "auth_user_permissions"} {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE permission_id IN ?", ids).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Where("permission_id IN ?", ids).Delete(&model.ObjectPermission{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&model.Permission{}, ids).Error
+}
+
+// declaredPermissions returns the permissions of Models and Actions, one per
+// codename, sorted by codename.
+func declaredPermissions(options *Options) []*model.Permission {
+	byCodename := make(map[string]*model.Permission)
 	for _, dstOpt := range options.Models {
-		perms = append(perms, generateModelPermissions(dstOpt)...)
+		for _, perm := range generateModelPermissions(dstOpt, options.ModelActions) {
+			byCodename[perm.Module+"."+perm.Action] = perm
+		}
 	}
-	err := repo.CreateMany(perms)
-	if err != nil {
-		panic(err)
+	for _, action := range options.Actions {
+		byCodename[action.Module+"."+action.Action] = &model.Permission{
+			Module:      action.Module,
+			Action:      action.Action,
+			Description: action.Description,
+		}
+	}
+
+	codenames := make([]string, 0, len(byCodename))
+	for codename := range byCodename {
+		codenames = append(codenames, codename)
+	}
+	sort.Strings(codenames)
+
+	perms := make([]*model.Permission, len(codenames))
+	for i, codename := range codenames {
+		perms[i] = byCodename[codename]
 	}
+	return perms
 }
 
-func generateModelPermissions(dst interface{}) []*model.Permission {
+func generateModelPermissions(dst interface{}, modelActions map[string][]string) []*model.Permission {
 	var permissions []*model.Permission
 	newDst, ok := dst.(basemodels.IModel)
 	if ok {
@@ -63,6 +240,36 @@ func generateModelPermissions(dst interface{}) []*model.Permission {
 			Description: "Permission to delete " + newDst.TableName() + " record.",
 		})
 	}
-	return permissions
+	if len(permissions) == 0 {
+		return permissions
+	}
+
+	if exposed, ok := modelActions[permissions[0].Module]; ok {
+		filtered := permissions[:0]
+		for _, perm := range permissions {
+			if slices.Contains(exposed, perm.Action) {
+				filtered = append(filtered, perm)
+			}
+		}
+		permissions = filtered
+	}
 
+	// Custom permissions aren't tied to a route, so they are always created.
+	if customModel, ok := dst.(basemodels.ICustomPermissionsModel); ok {
+		for _, custom := range customModel.CustomPermissions() {
+			if custom.Action == "" || strings.Contains(custom.Action, ".") {
+				panic("permissão customizada inválida em " + customModel.ModuleName() + ": " + custom.Action)
+			}
+			description := custom.Description
+			if description == "" {
+				description = "Permission to " + strings.ReplaceAll(custom.Action, "_", " ") + " " + customModel.TableName() + " records."
+			}
+			permissions = append(permissions, &model.Permission{
+				Module:      customModel.ModuleName(),
+				Action:      custom.Action,
+				Description: description,
+			})
+		}
+	}
+	return permissions
 }
@@ -0,0 +1,109 @@
+package permission
+
+import (
+	"grf/core/database"
+	"grf/core/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// PermissionSetLocal holds the effective permissions of the request user.
+const PermissionSetLocal = "permission_set"
+
+const (
+	createCallback = "grf:permission_cache_create"
+	updateCallback = "grf:permission_cache_update"
+	deleteCallback = "grf:permission_cache_delete"
+)
+
+// watchedTables are the tables whose writes change effective permissions.
+var watchedTables = map[string]bool{
+	"auth_permission":        true,
+	"auth_group":             true,
+	"auth_group_permissions": true,
+	"auth_user_groups":       true,
+	"auth_user_permissions":  true,
+}
+
+// Resolver loads the effective permissions of a user once per request,
+// backed by Cache. The cache is cleared once a GORM write to one of the
+// permission tables commits, e.g. when GroupService syncs permissions or a
+// user's groups change. Raw SQL isn't seen: callers of Exec on those tables
+// call Invalidate themselves, as SyncPermissions does. NewResolver installs
+// database.WrapConnPool on DB, which it needs to hold the clearing until the
+// commit.
+type Resolver struct {
+	DB    *gorm.DB
+	Cache ICache
+}
+
+func NewResolver(db *gorm.DB, cache ICache) *Resolver {
+	if cache == nil {
+		cache = NewLRUCache(DefaultCacheSize, DefaultCacheTTL)
+	}
+	if err := database.WrapConnPool(db); err != nil {
+		panic("NewResolver: " + err.Error())
+	}
+	r := &Resolver{DB: db, Cache: cache}
+	r.watch()
+	return r
+}
+
+// HasPerm falls back to user.HasPerm for users that can't load their set.
+func (r *Resolver) HasPerm(c *fiber.Ctx, user models.IUser, module string, action string) bool {
+	setUser, ok := user.(models.IPermissionSetUser)
+	if !ok || !user.Active() {
+		return user.HasPerm(r.DB, module, action)
+	}
+	set, err := r.Permissions(c, setUser)
+	if err != nil {
+		return false
+	}
+	return set.Has(module, action)
+}
+
+func (r *Resolver) Permissions(c *fiber.Ctx, user models.IPermissionSetUser) (*models.PermissionSet, error) {
+	if set, ok := c.Locals(PermissionSetLocal).(*models.PermissionSet); ok {
+		return set, nil
+	}
+
+	key := user.PermissionCacheKey()
+	set, ok := r.Cache.Get(key)
+	if !ok {
+		var err error
+		set, err = user.EffectivePermissions(r.DB)
+		if err != nil {
+			return nil, err
+		}
+		r.Cache.Set(key, set)
+	}
+	c.Locals(PermissionSetLocal, set)
+	return set, nil
+}
+
+func (r *Resolver) Invalidate() {
+	r.Cache.Clear()
+}
+
+func (r *Resolver) watch() {
+	// Clearing before the commit would let a concurrent request cache the
+	// rows about to change.
+	invalidate := func(db *gorm.DB) {
+		if db.Error == nil && watchedTables[db.Statement.Table] {
+			database.AfterCommit(db, r.Invalidate)
+		}
+	}
+
+	// Re-registering replaces the hooks of a previous resolver on this DB.
+	callbacks := r.DB.Callback()
+	if callbacks.Create().Get(createCallback) != nil {
+		_ = callbacks.Create().Replace(createCallback, invalidate)
+		_ = callbacks.Update().Replace(updateCallback, invalidate)
+		_ = callbacks.Delete().Replace(deleteCallback, invalidate)
+		return
+	}
+	_ = callbacks.Create().After("gorm:create").Register(createCallback, invalidate)
+	_ = callbacks.Update().After("gorm:update").Register(updateCallback, invalidate)
+	_ = callbacks.Delete().After("gorm:delete").Register(deleteCallback, invalidate)
+}
@@ -9,6 +9,8 @@ import (
 	"gorm.io/gorm"
 )
 
+type Scope func(db *gorm.DB) *gorm.DB
+
 type IRepository[T models.IModel, ID comparable] interface {
 	FindPaginated(filter filterset.IFilterSet, pagination pagination.IPagination[T]) (*pagination.Response[T], error)
 	FindById(id ID) (T, error)
@@ -18,6 +20,9 @@ type IRepository[T models.IModel, ID comparable] interface {
 	Update(entity T) error
 	PartialUpdate(entity T, updates map[string]interface{}) error
 	Delete(id ID) error
+
+	WithScopes(scopes ...Scope) IRepository[T, ID]
+	Transaction(fn func(repo IRepository[T, ID]) error) error
 }
 
 type GenericRepository[T models.IModel, ID comparable] struct {
@@ -96,6 +101,31 @@ func (r *GenericRepository[T, ID]) Delete(id ID) error {
 	return handleTx(r.DB.Delete(record, id))
 }
 
+// WithScopes returns a copy of the repository whose queries all go through
+// the given scopes, e.g. preloads or per-request restrictions.
+func (r *GenericRepository[T, ID]) WithScopes(scopes ...Scope) IRepository[T, ID] {
+	if len(scopes) == 0 {
+		return r
+	}
+	db := r.DB
+	for _, scope := range scopes {
+		db = scope(db)
+	}
+	return &GenericRepository[T, ID]{
+		DB:       db.Session(&gorm.Session{}),
+		NewModel: r.NewModel,
+	}
+}
+
+func (r *GenericRepository[T, ID]) Transaction(fn func(repo IRepository[T, ID]) error) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		return fn(&GenericRepository[T, ID]{
+			DB:       tx,
+			NewModel: r.NewModel,
+		})
+	})
+}
+
 func handleTx(tx *gorm.DB) error {
 	if tx.Error != nil {
 		return tx.Error
@@ -1,7 +1,7 @@
 package routes
 
 import (
-	"grf/core/middleware"
+	"grf/core/models"
 	"grf/core/permission"
 	"grf/core/server"
 	"grf/domain/auth/controller"
@@ -14,20 +14,29 @@ func RegisterAuthRoutes(
 	router fiber.Router,
 	app *server.App,
 ) {
-	Check := middleware.Check
 	IsAuthenticated := app.IsAuthenticated
 	IsAdmin := app.IsAdmin
+	// Credential management needs the user's own credentials, not a key or
+	// token limited to scopes.
+	IsFirstParty := permission.NewAnd(IsAuthenticated, app.IsFirstParty)
 
-	userController := controller.NewDefaultUserController(app.DB, app.Validator)
+	userController := controller.NewDefaultUserController(app.DB, app.Config, app.Validator, app.UserRevokers...)
 	groupController := controller.NewDefaultGroupController(app.DB, app.Validator)
 	permissionController := controller.NewDefaultPermissionController(app.DB, app.Validator)
-	authController := controller.NewAuthController(app.DB, app.Config, app.Validator)
+	apiKeyController := controller.NewDefaultAPIKeyController(app.DB, app.Validator)
+	authController := controller.NewAuthController(app.DB, app.Config, app.Keys, app.Validator, app.UserRevokers...)
+
+	handle(app, app.FiberApp, fiber.MethodGet, "/.well-known/jwks.json", "auth.jwks", nil, authController.JWKS)
 
 	authRoutes := router.Group("/auth")
-	authRoutes.Post("/token", authController.ObtainToken)
-	authRoutes.Post("/refresh", authController.ObtainTokenRefresh)
-	authRoutes.Get("/me", Check(IsAuthenticated), authController.GetMe)
-	authRoutes.Post("/change-password", Check(IsAuthenticated), authController.ChangePassword)
+	handle(app, authRoutes, fiber.MethodPost, "/token", "auth.token", nil, authController.ObtainToken)
+	handle(app, authRoutes, fiber.MethodPost, "/refresh", "auth.refresh", nil, authController.ObtainTokenRefresh)
+	handle(app, authRoutes, fiber.MethodPost, "/logout", "auth.logout", nil, authController.Logout)
+	handle(app, authRoutes, fiber.MethodPost, "/logout-all", "auth.logout_all", IsFirstParty, authController.LogoutAll)
+	handle(app, authRoutes, fiber.MethodPost, "/session/login", "auth.session_login", nil, authController.SessionLogin)
+	handle(app, authRoutes, fiber.MethodPost, "/session/logout", "auth.session_logout", IsAuthenticated, authController.SessionLogout)
+	handle(app, authRoutes, fiber.MethodGet, "/me", "auth.me", IsAuthenticated, authController.GetMe)
+	handle(app, authRoutes, fiber.MethodPost, "/change-password", "auth.change_password", IsFirstParty, authController.ChangePassword)
 
 	RegisterModelController(&RegisterModelOptions{
 		App:        app,
@@ -35,6 +44,7 @@ func RegisterAuthRoutes(
 		Path:       "/users",
 		Model:      new(model.User),
 		Controller: userController,
+		Bulk:       true,
 	})
 
 	RegisterModelController(&RegisterModelOptions{
@@ -53,5 +63,18 @@ func RegisterAuthRoutes(
 		Model:      new(model.Permission),
 		Controller: permissionController,
 		Permission: adminOnlyPerm,
+		// Permissions are synced from the code; only reading them makes sense.
+		Actions: models.ReadOnlyActions,
+	})
+
+	RegisterModelController(&RegisterModelOptions{
+		App:        app,
+		Router:     router,
+		Path:       "/api-keys",
+		Model:      new(model.APIKey),
+		Controller: apiKeyController,
+		Permission: adminOnlyPerm,
+		// Keys are revoked rather than deleted, so their use stays traceable.
+		ExcludeActions: []string{models.DeleteAction},
 	})
 }
@@ -1,11 +1,15 @@
 package routes
 
 import (
+	"bytes"
 	"grf/core/controller"
+	"grf/core/exceptions"
 	"grf/core/middleware"
 	"grf/core/models"
 	"grf/core/permission"
 	"grf/core/server"
+	"slices"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,9 +23,30 @@ type RegisterModelOptions struct {
 
 	Model models.IModel
 
-	Permission permission.IPermission
+	// Permission is the default check for every route. ActionPermissions
+	// overrides it per action, keyed by the models.*Action constants or the
+	// action part of a custom action codename (e.g. "set_password").
+	Permission        permission.IPermission
+	ActionPermissions map[string]permission.IPermission
+
+	// Bulk mounts POST (array body), PATCH (array of {id, ...}) and
+	// DELETE (?id__in=) on the collection. The controller must implement
+	// controller.IBulkController. They reuse the create, partialupdate and
+	// delete permissions, which can't check records: registering panics
+	// when one of them does, e.g. with IsOwner or ObjectPermissions.
+	Bulk bool
+
+	// Actions limits the CRUD actions mounted, e.g. models.ReadOnlyActions.
+	// Empty mounts all of them; ExcludeActions is removed from the result.
+	// Disabled methods answer 405 and get no permission rows.
+	Actions        []string
+	ExcludeActions []string
 }
 
+// RegisterModelController mounts the CRUD routes of a model. When the
+// controller implements controller.IActionController its custom actions are
+// mounted first, each checked against its own codename. Routes are named
+// after the path and action, e.g. "users.list" or "users.set_password".
 func RegisterModelController(opts *RegisterModelOptions) {
 
 	if opts.App == nil || opts.Router == nil || opts.Controller == nil || opts.Path == "" {
@@ -36,25 +61,199 @@ func RegisterModelController(opts *RegisterModelOptions) {
 		if opts.Model == nil {
 			panic("RegisterModelController: Model é obrigatório se a permissão customizada não for fornecida")
 		}
-		perm = permission.NewAnd(
-			opts.App.IsAuthenticated,
-			permission.NewModelPermissions(opts.App.DB, opts.Model),
-		)
+		modelPermissions := permission.NewModelPermissions(opts.App.DB, opts.Model)
+		modelPermissions.Resolver = opts.App.PermissionResolver
+		perm = permission.NewAnd(opts.App.IsAuthenticated, modelPermissions)
 	}
 
 	routes := opts.Router.Group(opts.Path)
-	routes.Use(middleware.Check(perm))
-	RegisterCRUDController(routes, opts.Controller)
+	routeName := strings.Trim(opts.Path, "/") + "."
+	known := append([]string{}, models.CRUDActions...)
+	permFor := func(action string) permission.IPermission {
+		if actionPerm, ok := opts.ActionPermissions[action]; ok {
+			return actionPerm
+		}
+		return perm
+	}
+	mountNamed := func(method, path, action, name string, handler fiber.Handler) {
+		actionPerm := permFor(action)
+		add(routes, method, path, middleware.SetAction(action), middleware.Check(actionPerm), handler)
+		opts.App.NameRoute(routes, routeName+name, actionPerm)
+	}
+	mount := func(method, path, action string, handler fiber.Handler) {
+		mountNamed(method, path, action, action, handler)
+	}
+
+	if actionController, ok := opts.Controller.(controller.IActionController); ok {
+		for _, action := range actionController.Actions() {
+			name := registerAction(opts, action)
+			known = append(known, name)
+			path := action.Path
+			if action.Detail {
+				path = "/:id" + path
+			}
+			mount(action.Method, path, name, action.Handler)
+		}
+	}
+
+	for action := range opts.ActionPermissions {
+		if !slices.Contains(known, action) {
+			panic("RegisterModelController: permissão para action desconhecida " + action)
+		}
+	}
+
+	exposed := exposedActions(opts)
+	allowed := make(map[string][]string)
+	mountCRUD := func(method, path, action, name string, handler fiber.Handler) {
+		if !slices.Contains(exposed, action) {
+			return
+		}
+		mountNamed(method, path, action, name, handler)
+		allowed[path] = append(allowed[path], method)
+	}
+
+	create := opts.Controller.Create
+	if opts.Bulk {
+		bulkController, ok := opts.Controller.(controller.IBulkController)
+		if !ok {
+			panic("RegisterModelController: Controller não implementa controller.IBulkController")
+		}
+		for _, action := range []string{models.CreateAction, models.PartialUpdateAction, models.DeleteAction} {
+			if slices.Contains(exposed, action) && permission.HasObjectCheck(permFor(action)) {
+				panic("RegisterModelController: rotas em massa não verificam permissões por objeto (" + action + ")")
+			}
+		}
+		create = func(c *fiber.Ctx) error {
+			if isJSONArray(c.Body()) {
+				return bulkController.BulkCreate(c)
+			}
+			return opts.Controller.Create(c)
+		}
+		mountCRUD(fiber.MethodPatch, "/", models.PartialUpdateAction, "bulk_"+models.PartialUpdateAction, bulkController.BulkPartialUpdate)
+		mountCRUD(fiber.MethodDelete, "/", models.DeleteAction, "bulk_"+models.DeleteAction, bulkController.BulkDelete)
+	}
+
+	mountCRUD(fiber.MethodGet, "/", models.ListAction, models.ListAction, opts.Controller.List)
+	mountCRUD(fiber.MethodPost, "/", models.CreateAction, models.CreateAction, create)
+	mountCRUD(fiber.MethodGet, "/:id", models.DetailAction, models.DetailAction, opts.Controller.Retrieve)
+	mountCRUD(fiber.MethodPut, "/:id", models.UpdateAction, models.UpdateAction, opts.Controller.Update)
+	mountCRUD(fiber.MethodPatch, "/:id", models.PartialUpdateAction, models.PartialUpdateAction, opts.Controller.PartialUpdate)
+	mountCRUD(fiber.MethodDelete, "/:id", models.DeleteAction, models.DeleteAction, opts.Controller.Delete)
+
+	for _, path := range []string{"/", "/:id"} {
+		methods := allowed[path]
+		if len(methods) == 0 {
+			continue
+		}
+		if slices.Contains(methods, fiber.MethodGet) {
+			methods = append(methods, fiber.MethodHead)
+		}
+		for _, method := range crudMethods {
+			if !slices.Contains(methods, method) {
+				routes.Add(method, path, methodNotAllowed(methods))
+			}
+		}
+	}
+}
+
+var crudMethods = []string{
+	fiber.MethodGet, fiber.MethodHead, fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete,
 }
 
-func RegisterCRUDController(
+// exposedActions resolves Actions and ExcludeActions and records the result
+// on the App so only those permissions are generated.
+func exposedActions(opts *RegisterModelOptions) []string {
+	actions := opts.Actions
+	if len(actions) == 0 {
+		actions = models.CRUDActions
+	}
+
+	exposed := make([]string, 0, len(actions))
+	for _, action := range actions {
+		if !slices.Contains(models.CRUDActions, action) {
+			panic("RegisterModelController: action desconhecida " + action)
+		}
+		if !slices.Contains(opts.ExcludeActions, action) {
+			exposed = append(exposed, action)
+		}
+	}
+
+	if opts.Model != nil {
+		if opts.App.ModelActions == nil {
+			opts.App.ModelActions = make(map[string][]string)
+		}
+		module := opts.Model.ModuleName()
+		for _, action := range exposed {
+			if !slices.Contains(opts.App.ModelActions[module], action) {
+				opts.App.ModelActions[module] = append(opts.App.ModelActions[module], action)
+			}
+		}
+	}
+	return exposed
+}
+
+func methodNotAllowed(methods []string) fiber.Handler {
+	allow := strings.Join(methods, ", ")
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderAllow, allow)
+		return exceptions.NewMethodNotAllowed("method_not_allowed", nil)
+	}
+}
+
+// registerAction validates the action codename against the model and records
+// its permission on the App. It returns the action part of the codename.
+func registerAction(opts *RegisterModelOptions, action controller.Action) string {
+	if action.Method == "" || action.Handler == nil || action.Codename == "" {
+		panic("RegisterModelController: Method, Handler e Codename são obrigatórios nas actions")
+	}
+	module, name, ok := strings.Cut(action.Codename, ".")
+	if !ok || name == "" {
+		panic("RegisterModelController: codename inválido " + action.Codename)
+	}
+	if opts.Model != nil && module != opts.Model.ModuleName() {
+		panic("RegisterModelController: codename " + action.Codename + " não pertence ao módulo " + opts.Model.ModuleName())
+	}
+
+	description := action.Description
+	if description == "" {
+		description = "Permission to " + strings.ReplaceAll(name, "_", " ") + " " + module + " records."
+	}
+	opts.App.Actions = append(opts.App.Actions, permission.ActionDefinition{
+		Module:      module,
+		Action:      name,
+		Description: description,
+	})
+	return name
+}
+
+// handle mounts a named route behind perm; a nil perm leaves it public.
+func handle(
+	app *server.App,
 	router fiber.Router,
-	controller controller.ICRUDController,
+	method string,
+	path string,
+	name string,
+	perm permission.IPermission,
+	handler fiber.Handler,
 ) {
-	router.Get("/", controller.List)
-	router.Post("/", controller.Create)
-	router.Get("/:id", controller.Retrieve)
-	router.Put("/:id", controller.Update)
-	router.Patch("/:id", controller.PartialUpdate)
-	router.Delete("/:id", controller.Delete)
+	if perm == nil {
+		add(router, method, path, handler)
+	} else {
+		add(router, method, path, middleware.Check(perm), handler)
+	}
+	app.NameRoute(router, name, perm)
+}
+
+// add mounts handlers like router.Add, answering HEAD on GET routes as
+// router.Get does. HEAD goes first so the route name lands on GET.
+func add(router fiber.Router, method string, path string, handlers ...fiber.Handler) {
+	if method == fiber.MethodGet {
+		router.Add(fiber.MethodHead, path, handlers...)
+	}
+	router.Add(method, path, handlers...)
+}
+
+func isJSONArray(body []byte) bool {
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && trimmed[0] == '['
 }
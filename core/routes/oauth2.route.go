@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"grf/core/permission"
+	"grf/core/server"
+	"grf/domain/oauth2/controller"
+	"grf/domain/oauth2/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RegisterOAuth2Routes mounts the OAuth2 server. The token, introspection
+// and revocation endpoints authenticate the client themselves.
+func RegisterOAuth2Routes(
+	router fiber.Router,
+	app *server.App,
+) {
+	IsAuthenticated := app.IsAuthenticated
+	// Only the user's own credentials may grant access to a client.
+	IsFirstParty := permission.NewAnd(IsAuthenticated, app.IsFirstParty)
+
+	oauth2Controller := controller.NewOAuth2Controller(app.DB, app.Config)
+	clientController := controller.NewDefaultClientController(app.DB, app.Validator)
+
+	oauth2Routes := router.Group("/oauth2")
+	handle(app, oauth2Routes, fiber.MethodGet, "/authorize", "oauth2.authorize_info", IsFirstParty, oauth2Controller.AuthorizeInfo)
+	handle(app, oauth2Routes, fiber.MethodPost, "/authorize", "oauth2.authorize", IsFirstParty, oauth2Controller.Authorize)
+	handle(app, oauth2Routes, fiber.MethodPost, "/token", "oauth2.token", nil, oauth2Controller.Token)
+	handle(app, oauth2Routes, fiber.MethodPost, "/introspect", "oauth2.introspect", nil, oauth2Controller.Introspect)
+	handle(app, oauth2Routes, fiber.MethodPost, "/revoke", "oauth2.revoke", nil, oauth2Controller.Revoke)
+
+	RegisterModelController(&RegisterModelOptions{
+		App:        app,
+		Router:     oauth2Routes,
+		Path:       "/clients",
+		Model:      new(model.Client),
+		Controller: clientController,
+		Permission: permission.NewAnd(IsAuthenticated, app.IsAdmin),
+	})
+}
@@ -7,4 +7,5 @@ import (
 func RegisterRoutes(app *server.App) {
 	apiV1 := app.FiberApp.Group("/v1")
 	RegisterAuthRoutes(apiV1, app)
+	RegisterOAuth2Routes(apiV1, app)
 }
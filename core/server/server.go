@@ -2,7 +2,10 @@ package server
 
 import (
 	"grf/core/config"
+	"grf/core/keyring"
 	"grf/core/middleware"
+	"grf/core/migrations"
+	"grf/core/models"
 	"grf/core/permission"
 
 	"github.com/go-playground/validator/v10"
@@ -15,17 +18,60 @@ type App struct {
 	Config    *config.Config
 	DB        *gorm.DB
 	Validator *validator.Validate
+	// Keys signs and verifies the JWTs issued by the app.
+	Keys *keyring.Keyring
 
 	I18nMw *middleware.I18NMiddleware
 
-	Models []interface{}
+	Models     []interface{}
+	Migrations []*migrations.Migration
+	// UserRevokers revoke the credentials other modules issued to a user
+	// whose auth tokens are revoked.
+	UserRevokers []models.UserRevoker
+
+	// Actions collects the custom controller actions mounted by the routes,
+	// so their permissions can be registered.
+	Actions []permission.ActionDefinition
+	// ModelActions holds the CRUD actions exposed per module. Modules that
+	// were never mounted get all of them.
+	ModelActions map[string][]string
 
 	AllowAny                  permission.IPermission
 	IsAuthenticatedOrReadOnly permission.IPermission
 	IsAuthenticated           permission.IPermission
 	IsAdmin                   permission.IPermission
+	IsFirstParty              permission.IPermission
+
+	PermissionResolver *permission.Resolver
+
+	// RoutePermissions maps route names to the permission guarding them,
+	// nil for public routes, so the route table can be listed with them.
+	RoutePermissions map[string]permission.IPermission
+}
+
+// NameRoute names the route just added to router and records its permission.
+func (a *App) NameRoute(router fiber.Router, name string, perm permission.IPermission) {
+	router.Name(name)
+	if a.RoutePermissions == nil {
+		a.RoutePermissions = make(map[string]permission.IPermission)
+	}
+	a.RoutePermissions[name] = perm
+}
+
+// HasPermission requires an authenticated user holding codename, resolved
+// through the app's permission cache, e.g. app.HasPermission("user.export").
+func (a *App) HasPermission(codename string) permission.IPermission {
+	perm := permission.NewHasPermission(a.DB, codename)
+	perm.Resolver = a.PermissionResolver
+	return permission.NewAnd(a.IsAuthenticated, perm)
 }
 
 func (a *App) Start() error {
 	return a.FiberApp.Listen(":" + a.Config.ServerPort)
 }
+
+// HasScope requires an authenticated user whose credentials grant every
+// scope, e.g. app.HasScope("users:read") on a route used by API keys.
+func (a *App) HasScope(scopes ...string) permission.IPermission {
+	return permission.NewAnd(a.IsAuthenticated, permission.NewHasScope(scopes...))
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"grf/core/dto"
+	"grf/core/exceptions"
 	"grf/core/filterset"
 	"grf/core/models"
 	"grf/core/pagination"
@@ -9,13 +10,20 @@ import (
 )
 
 type IService[T models.IModel, C any, U any, P dto.IPatchDTO, R any, F filterset.IFilterSet, ID comparable] interface {
-	List(filter F, pagination pagination.IPagination[T]) (*pagination.Response[T], error)
+	List(filter filterset.IFilterSet, pagination pagination.IPagination[T]) (*pagination.Response[T], error)
 	GetByID(id ID) (T, error)
 	GetAllByID(ids []ID) ([]T, error)
 	Create(dto C) (T, error)
 	Update(id ID, dto U) (T, error)
 	PartialUpdate(id ID, dto P) (T, error)
 	Delete(id ID) error
+
+	BulkCreate(dtos []C) ([]T, error)
+	BulkPartialUpdate(ids []ID, dtos []P) ([]T, error)
+	BulkDelete(ids []ID) error
+
+	WithScopes(scopes ...repository.Scope) IService[T, C, U, P, R, F, ID]
+	ForUser(user models.IUser) IService[T, C, U, P, R, F, ID]
 }
 
 type GenericService[T models.IModel, C any, U any, P dto.IPatchDTO, R any, F filterset.IFilterSet, ID comparable] struct {
@@ -23,6 +31,8 @@ type GenericService[T models.IModel, C any, U any, P dto.IPatchDTO, R any, F fil
 
 	MapCreateToModel func(dto C) T
 	MapUpdateToModel func(dto U, model T) T
+
+	Scope func(user models.IUser) repository.Scope
 }
 
 type Config[T models.IModel, C any, U any, P dto.IPatchDTO, R any, F filterset.IFilterSet, ID comparable] struct {
@@ -30,6 +40,11 @@ type Config[T models.IModel, C any, U any, P dto.IPatchDTO, R any, F filterset.I
 
 	MapCreateToModel func(dto C) T
 	MapUpdateToModel func(dto U, model T) T
+
+	// Scope narrows the queries of ForUser to what the request user may
+	// see. Rows left out behave as missing, so detail routes answer 404.
+	// The user is nil on anonymous requests.
+	Scope func(user models.IUser) repository.Scope
 }
 
 func NewGenericService[T models.IModel, C any, U any, P dto.IPatchDTO, R any, F filterset.IFilterSet, ID comparable](
@@ -44,11 +59,12 @@ func NewGenericService[T models.IModel, C any, U any, P dto.IPatchDTO, R any, F
 		Repo:             config.Repo,
 		MapCreateToModel: config.MapCreateToModel,
 		MapUpdateToModel: config.MapUpdateToModel,
+		Scope:            config.Scope,
 	}
 }
 
 func (s *GenericService[T, C, U, P, R, F, ID]) List(
-	filter F,
+	filter filterset.IFilterSet,
 	pagination pagination.IPagination[T],
 ) (*pagination.Response[T], error) {
 	return s.Repo.FindPaginated(filter, pagination)
@@ -104,3 +120,70 @@ func (s *GenericService[T, C, U, P, R, F, ID]) PartialUpdate(id ID, dto P) (T, e
 func (s *GenericService[T, C, U, P, R, F, ID]) Delete(id ID) error {
 	return s.Repo.Delete(id)
 }
+
+func (s *GenericService[T, C, U, P, R, F, ID]) BulkCreate(dtos []C) ([]T, error) {
+	records := make([]T, len(dtos))
+	err := s.Repo.Transaction(func(repo repository.IRepository[T, ID]) error {
+		for i, dto := range dtos {
+			records[i] = s.MapCreateToModel(dto)
+			if err := repo.Create(records[i]); err != nil {
+				return exceptions.NewBulkItemError(i, err)
+			}
+		}
+		return nil
+	})
+	return records, err
+}
+
+func (s *GenericService[T, C, U, P, R, F, ID]) BulkPartialUpdate(ids []ID, dtos []P) ([]T, error) {
+	records := make([]T, len(dtos))
+	err := s.Repo.Transaction(func(repo repository.IRepository[T, ID]) error {
+		for i, dto := range dtos {
+			record, err := repo.FindById(ids[i])
+			if err != nil {
+				return exceptions.NewBulkItemError(i, err)
+			}
+			records[i] = record
+
+			patchMap := dto.ToPatchMap()
+			if dto.IsEmpty() || len(patchMap) == 0 {
+				continue
+			}
+			if err := repo.PartialUpdate(record, patchMap); err != nil {
+				return exceptions.NewBulkItemError(i, err)
+			}
+		}
+		return nil
+	})
+	return records, err
+}
+
+func (s *GenericService[T, C, U, P, R, F, ID]) BulkDelete(ids []ID) error {
+	return s.Repo.Transaction(func(repo repository.IRepository[T, ID]) error {
+		for i, id := range ids {
+			if err := repo.Delete(id); err != nil {
+				return exceptions.NewBulkItemError(i, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *GenericService[T, C, U, P, R, F, ID]) WithScopes(scopes ...repository.Scope) IService[T, C, U, P, R, F, ID] {
+	if len(scopes) == 0 {
+		return s
+	}
+	return &GenericService[T, C, U, P, R, F, ID]{
+		Repo:             s.Repo.WithScopes(scopes...),
+		MapCreateToModel: s.MapCreateToModel,
+		MapUpdateToModel: s.MapUpdateToModel,
+		Scope:            s.Scope,
+	}
+}
+
+func (s *GenericService[T, C, U, P, R, F, ID]) ForUser(user models.IUser) IService[T, C, U, P, R, F, ID] {
+	if s.Scope == nil {
+		return s
+	}
+	return s.WithScopes(s.Scope(user))
+}
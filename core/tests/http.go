@@ -16,9 +16,11 @@ type RequestOptions struct {
 	URL    string
 	Token  string
 	Body   interface{}
+	// Header is set after Token, so it can replace the Authorization scheme.
+	Header map[string]string
 }
 
-func MakeRequest(t *testing.T, app *fiber.App, opts RequestOptions) (*http.Response, string) {
+func MakeRequest(t testing.TB, app *fiber.App, opts RequestOptions) (*http.Response, string) {
 	var bodyReader io.Reader
 	var bodyString string
 	if opts.Body != nil {
@@ -35,6 +37,9 @@ func MakeRequest(t *testing.T, app *fiber.App, opts RequestOptions) (*http.Respo
 	if opts.Token != "" {
 		req.Header.Set("Authorization", "Bearer "+opts.Token)
 	}
+	for key, value := range opts.Header {
+		req.Header.Set(key, value)
+	}
 	t.Logf("\nRequest\nPath: %s\nBody: %s", req.URL.String(), bodyString)
 	resp, err := app.Test(req)
 	if err != nil {
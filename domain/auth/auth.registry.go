@@ -1,11 +1,54 @@
 package auth
 
-import "grf/domain/auth/model"
+import (
+	"embed"
+	"grf/core/management"
+	"grf/core/migrations"
+	"grf/domain/auth/command"
+	"grf/domain/auth/model"
+	"io/fs"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
 
 func GetModels() []interface{} {
 	return []interface{}{
 		&model.Permission{},
 		&model.Group{},
 		&model.User{},
+		&model.ObjectPermission{},
+		&model.OutstandingToken{},
+		&model.BlacklistedToken{},
+		&model.APIKey{},
+		&model.Session{},
+		&model.ExternalIdentity{},
+	}
+}
+
+func GetMigrations() []*migrations.Migration {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		panic(err)
+	}
+	return migrations.MustFromFS("auth", files)
+}
+
+func GetCommands() []*management.Command {
+	return []*management.Command{
+		command.NewCreateSuperuserCommand(),
+		command.NewChangePasswordCommand(),
+		command.NewLinkOIDCCommand(),
+		command.NewFlushExpiredTokensCommand(),
+		command.NewClearSessionsCommand(),
+	}
+}
+
+func GetModule() *management.Module {
+	return &management.Module{
+		Name:       "auth",
+		Models:     GetModels(),
+		Migrations: GetMigrations(),
+		Commands:   GetCommands(),
 	}
 }
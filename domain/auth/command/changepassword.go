@@ -0,0 +1,69 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"grf/core/management"
+	"grf/core/validator"
+	"grf/domain/auth/dto"
+	"grf/domain/auth/model"
+	"grf/domain/auth/repository"
+
+	"gorm.io/gorm"
+)
+
+func NewChangePasswordCommand() *management.Command {
+	return &management.Command{
+		Name:        "changepassword",
+		Args:        "<username>",
+		Description: "Set a user's password, prompting for it",
+		Run:         changePassword,
+	}
+}
+
+func changePassword(ctx *management.Context, args []string) error {
+	flags := ctx.FlagSet("changepassword")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return errors.New("usage: changepassword <username>")
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+	userRepo := repository.NewUserRepository(db)
+	user, err := userRepo.FindUserByEmailOrUsername(flags.Arg(0))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("user %q does not exist", flags.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(ctx.Out, "Changing password for %s\n", user.Username)
+	password, err := promptNewPassword(ctx)
+	if err != nil {
+		return err
+	}
+	if err := validator.GetValidator().Struct(dto.SetPasswordDTO{Password: password}); err != nil {
+		return err
+	}
+
+	if err := user.SetPassword(password, ctx.Config.PasswordHashCost); err != nil {
+		return err
+	}
+	if err := userRepo.Update(&user); err != nil {
+		return err
+	}
+	if err := repository.NewTokenRepository(db, ctx.UserRevokers()...).RevokeUser(user.ID, model.TokenRevoked); err != nil {
+		return err
+	}
+	if err := repository.NewSessionRepository(db).DeleteUser(user.ID); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Out, "Password changed for %s, existing tokens and sessions revoked.\n", user.Username)
+	return nil
+}
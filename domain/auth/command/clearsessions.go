@@ -0,0 +1,33 @@
+package command
+
+import (
+	"fmt"
+	"grf/core/management"
+	"grf/domain/auth/repository"
+	"time"
+)
+
+func NewClearSessionsCommand() *management.Command {
+	return &management.Command{
+		Name:        "clearsessions",
+		Description: "Delete expired cookie sessions",
+		Run:         clearSessions,
+	}
+}
+
+func clearSessions(ctx *management.Context, args []string) error {
+	if err := ctx.FlagSet("clearsessions").Parse(args); err != nil {
+		return err
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+	deleted, err := repository.NewSessionRepository(db).DeleteExpired(time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Out, "%d expired sessions deleted.\n", deleted)
+	return nil
+}
@@ -0,0 +1,108 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"grf/core/management"
+	"grf/core/validator"
+	"grf/domain/auth/dto"
+	"grf/domain/auth/model"
+	"os"
+)
+
+func NewCreateSuperuserCommand() *management.Command {
+	return &management.Command{
+		Name:        "createsuperuser",
+		Args:        "[-username name] [-email address] [-no-input]",
+		Description: "Create a superuser, prompting for missing fields",
+		Run:         createSuperuser,
+	}
+}
+
+// createSuperuser takes username and email from flags or SUPERUSER_USERNAME
+// and SUPERUSER_EMAIL, and the password from SUPERUSER_PASSWORD or a prompt.
+// With -no-input every field must come from flags or the environment.
+func createSuperuser(ctx *management.Context, args []string) error {
+	flags := ctx.FlagSet("createsuperuser")
+	username := flags.String("username", os.Getenv("SUPERUSER_USERNAME"), "username")
+	email := flags.String("email", os.Getenv("SUPERUSER_EMAIL"), "email address")
+	noInput := flags.Bool("no-input", false, "fail instead of prompting for missing fields")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	password := os.Getenv("SUPERUSER_PASSWORD")
+
+	if *noInput {
+		if *username == "" || *email == "" || password == "" {
+			return errors.New("-no-input needs -username, -email and SUPERUSER_PASSWORD")
+		}
+	} else {
+		var err error
+		if *username == "" {
+			if *username, err = ctx.Prompt("Username: "); err != nil {
+				return err
+			}
+		}
+		if *email == "" {
+			if *email, err = ctx.Prompt("Email address: "); err != nil {
+				return err
+			}
+		}
+		if password == "" {
+			if password, err = promptNewPassword(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	input := dto.UserCreateDTO{Username: *username, Email: *email, Password: password}
+	if err := validator.GetValidator().Struct(input); err != nil {
+		return err
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+	var count int64
+	if err := db.Model(&model.User{}).
+		Where("username = ? OR email = ?", input.Username, input.Email).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("a user with this username or email already exists")
+	}
+
+	user := &model.User{
+		Username:    input.Username,
+		Email:       input.Email,
+		IsActive:    true,
+		IsStaff:     true,
+		IsSuperuser: true,
+	}
+	if err := user.SetPassword(input.Password, ctx.Config.PasswordHashCost); err != nil {
+		return err
+	}
+	if err := db.Create(user).Error; err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Out, "Superuser %s created.\n", user.Username)
+	return nil
+}
+
+// promptNewPassword asks for a password twice.
+func promptNewPassword(ctx *management.Context) (string, error) {
+	password, err := ctx.PromptPassword("Password: ")
+	if err != nil {
+		return "", err
+	}
+	again, err := ctx.PromptPassword("Password (again): ")
+	if err != nil {
+		return "", err
+	}
+	if password != again {
+		return "", errors.New("passwords don't match")
+	}
+	return password, nil
+}
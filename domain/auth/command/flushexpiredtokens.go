@@ -0,0 +1,33 @@
+package command
+
+import (
+	"fmt"
+	"grf/core/management"
+	"grf/domain/auth/repository"
+	"time"
+)
+
+func NewFlushExpiredTokensCommand() *management.Command {
+	return &management.Command{
+		Name:        "flushexpiredtokens",
+		Description: "Delete expired refresh tokens and their blacklist entries",
+		Run:         flushExpiredTokens,
+	}
+}
+
+func flushExpiredTokens(ctx *management.Context, args []string) error {
+	if err := ctx.FlagSet("flushexpiredtokens").Parse(args); err != nil {
+		return err
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+	deleted, err := repository.NewTokenRepository(db).DeleteExpired(time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Out, "%d expired tokens deleted.\n", deleted)
+	return nil
+}
@@ -0,0 +1,58 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"grf/core/management"
+	"grf/domain/auth/model"
+	"grf/domain/auth/repository"
+
+	"gorm.io/gorm"
+)
+
+func NewLinkOIDCCommand() *management.Command {
+	return &management.Command{
+		Name:        "linkoidc",
+		Args:        "<username> <subject>",
+		Description: "Let an existing user sign in as an account of the OIDC provider",
+		Run:         linkOIDC,
+	}
+}
+
+// linkOIDC links a user to the provider account whose sub claim is subject.
+// The provider only signs in the users it created or that were linked.
+func linkOIDC(ctx *management.Context, args []string) error {
+	flags := ctx.FlagSet("linkoidc")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 || flags.Arg(1) == "" {
+		return errors.New("usage: linkoidc <username> <subject>")
+	}
+	if ctx.Config.OIDCIssuer == "" {
+		return errors.New("OIDC_ISSUER is not set")
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+	user, err := repository.NewUserRepository(db).FindUserByEmailOrUsername(flags.Arg(0))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("user %q does not exist", flags.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+
+	identities := repository.NewExternalIdentityRepository(db)
+	if _, err := identities.FindBySubject(ctx.Config.OIDCIssuer, flags.Arg(1)); err == nil {
+		return fmt.Errorf("subject %q is already linked", flags.Arg(1))
+	}
+	identity := &model.ExternalIdentity{UserID: user.ID, Issuer: ctx.Config.OIDCIssuer, Subject: flags.Arg(1)}
+	if err := identities.Create(identity); err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Out, "%s linked to %s at %s.\n", user.Username, identity.Subject, identity.Issuer)
+	return nil
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	controllers "grf/core/controller"
+	"grf/core/exceptions"
+	"grf/core/filterset"
+	"grf/core/models"
+	"grf/core/pagination"
+	"grf/core/permission"
+	"grf/core/service"
+	"grf/domain/auth/dto"
+	"grf/domain/auth/filter"
+	"grf/domain/auth/mapper"
+	"grf/domain/auth/model"
+	"grf/domain/auth/repository"
+	services "grf/domain/auth/service"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func NewDefaultAPIKeyController(
+	db *gorm.DB,
+	validate *validator.Validate,
+) *controllers.GenericController[
+	*model.APIKey, *dto.APIKeyCreateDTO, *dto.APIKeyUpdateDTO, *dto.APIKeyPatchDTO, *dto.APIKeyResponseDTO, *filter.APIKeyFilterSet, uint64,
+] {
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+
+	apiKeyService := services.NewAPIKeyService(
+		&service.Config[*model.APIKey, *dto.APIKeyCreateDTO, *dto.APIKeyUpdateDTO, *dto.APIKeyPatchDTO, *dto.APIKeyResponseDTO, *filter.APIKeyFilterSet, uint64]{
+			Repo:             apiKeyRepo,
+			MapCreateToModel: mapper.MapCreateToAPIKey,
+			MapUpdateToModel: mapper.MapUpdateToAPIKey,
+		},
+		apiKeyRepo,
+	)
+
+	apiKeyPaginator := pagination.NewLimitOffsetPagination[*model.APIKey](10, 100)
+	apiKeyConfig := &controllers.Config[
+		*model.APIKey, *dto.APIKeyCreateDTO, *dto.APIKeyUpdateDTO, *dto.APIKeyPatchDTO, *dto.APIKeyResponseDTO, *filter.APIKeyFilterSet, uint64,
+	]{
+		Service:       apiKeyService,
+		Validator:     validate,
+		Paginator:     apiKeyPaginator,
+		Ordering:      filterset.NewOrderingFilter([]string{"id", "name", "created_at", "expires_at", "last_used_at"}, "id"),
+		MapToResponse: mapper.MapAPIKeyToResponse,
+		NewFilterSet:  func() *filter.APIKeyFilterSet { return new(filter.APIKeyFilterSet) },
+		NewSearchFilter: func() filterset.IFilterSet {
+			return filterset.NewSearchFilter("name", "prefix")
+		},
+		NewPatchDTO: func() *dto.APIKeyPatchDTO { return new(dto.APIKeyPatchDTO) },
+		ParseID: func(s string) (uint64, error) {
+			id, err := strconv.ParseUint(s, 10, 64)
+			return id, err
+		},
+
+		ExtraActions: []controllers.Action{
+			{
+				Method:      fiber.MethodPost,
+				Path:        "/revoke",
+				Detail:      true,
+				Codename:    "api_key.revoke",
+				Description: "Permission to revoke auth_api_key records.",
+				Handler:     revokeAPIKeyHandler(apiKeyService),
+			},
+		},
+	}
+
+	return controllers.NewGenericController(apiKeyConfig)
+}
+
+// revokeAPIKeyHandler looks the key up like the other detail routes, scoped
+// to the request user and checked against the route's object permissions.
+func revokeAPIKeyHandler(apiKeyService *services.APIKeyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return exceptions.NewBadRequest("id_required", err)
+		}
+
+		requestUser, _ := c.Locals("user").(models.IUser)
+		key, err := apiKeyService.ForUser(requestUser).GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := permission.CheckObject(c, key); err != nil {
+			return err
+		}
+		if err := apiKeyService.Revoke(key); err != nil {
+			return err
+		}
+
+		return c.JSON(mapper.MapAPIKeyToResponse(key))
+	}
+}
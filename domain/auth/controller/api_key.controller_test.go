@@ -0,0 +1,216 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"grf/core/middleware"
+	"grf/core/tests"
+	"grf/domain/auth/dto"
+	"grf/domain/auth/model"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func apiKeyRequest(t *testing.T, method, url, key string) (*http.Response, string) {
+	return tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+		Method: method,
+		URL:    url,
+		Header: map[string]string{"Authorization": "Api-Key " + key},
+	})
+}
+
+func TestAPIKeys(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	scoped := func(c *fiber.Ctx) error { return c.SendStatus(http.StatusNoContent) }
+	testApp.FiberApp.Get("/v1/test-scope-read", middleware.Check(testApp.HasScope("users:read")), scoped)
+	testApp.FiberApp.Get("/v1/test-scope-write", middleware.Check(testApp.HasScope("users:write")), scoped)
+
+	adminToken, _ := loginAs(t, "admin", "admin123")
+	userToken, _ := loginAs(t, "user", "user123")
+
+	var created dto.APIKeyResponseDTO
+	t.Run("POST /api-keys (Admin 201 mostra a chave)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: "/v1/api-keys", Token: adminToken,
+			Body: dto.APIKeyCreateDTO{Name: "ci", UserID: fixtures.NormalUser.ID, Scopes: []string{"users:read", "users:read"}},
+		})
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Esperado 201, obteve %d: %s", resp.StatusCode, body)
+		}
+		json.Unmarshal([]byte(body), &created)
+		if !strings.HasPrefix(created.Key, created.Prefix+".") {
+			t.Errorf("Esperado chave com o prefixo %s, obteve %q", created.Prefix, created.Key)
+		}
+		if len(created.Scopes) != 1 || created.Scopes[0] != "users:read" {
+			t.Errorf("Esperado escopos [users:read], obteve %v", created.Scopes)
+		}
+
+		var stored model.APIKey
+		testApp.DB.First(&stored, created.ID)
+		if stored.HashedKey == "" || strings.Contains(created.Key, stored.HashedKey) {
+			t.Error("Esperado apenas o hash do segredo armazenado")
+		}
+	})
+
+	t.Run("GET /api-keys (Admin 200 sem a chave)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/api-keys?user_id=" + jsonNumber(fixtures.NormalUser.ID), Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+		if !strings.Contains(body, created.Prefix) || strings.Contains(body, `"key"`) {
+			t.Errorf("Esperado a chave listada sem o segredo: %s", body)
+		}
+	})
+
+	t.Run("POST /api-keys (User 403)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: "/v1/api-keys", Token: userToken,
+			Body: dto.APIKeyCreateDTO{Name: "minha", UserID: fixtures.NormalUser.ID},
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("POST /api-keys (Entradas inválidas)", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		cases := []struct {
+			name   string
+			body   dto.APIKeyCreateDTO
+			status int
+		}{
+			{"usuário inexistente", dto.APIKeyCreateDTO{Name: "x", UserID: 999999}, http.StatusBadRequest},
+			{"expiração no passado", dto.APIKeyCreateDTO{Name: "x", UserID: fixtures.NormalUser.ID, ExpiresAt: &past}, http.StatusBadRequest},
+			{"escopo com espaço", dto.APIKeyCreateDTO{Name: "x", UserID: fixtures.NormalUser.ID, Scopes: []string{"a b"}}, http.StatusUnprocessableEntity},
+		}
+		for _, tc := range cases {
+			resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+				Method: http.MethodPost, URL: "/v1/api-keys", Token: adminToken, Body: tc.body,
+			})
+			if resp.StatusCode != tc.status {
+				t.Errorf("%s: Esperado %d, obteve %d: %s", tc.name, tc.status, resp.StatusCode, body)
+			}
+		}
+	})
+
+	t.Run("Autentica com Api-Key", func(t *testing.T) {
+		resp, body := apiKeyRequest(t, http.MethodGet, "/v1/auth/me", created.Key)
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"username":"user"`) {
+			t.Fatalf("Esperado 200 como user, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var stored model.APIKey
+		testApp.DB.First(&stored, created.ID)
+		if stored.LastUsedAt == nil {
+			t.Error("Esperado last_used_at preenchido")
+		}
+	})
+
+	t.Run("Api-Key inválida 401", func(t *testing.T) {
+		for _, key := range []string{created.Prefix + ".errado", "semponto", "inexistente.segredo"} {
+			if resp, _ := apiKeyRequest(t, http.MethodGet, "/v1/auth/me", key); resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("%s: Esperado 401, obteve %d", key, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("HasScope limita apenas as chaves", func(t *testing.T) {
+		if resp, _ := apiKeyRequest(t, http.MethodGet, "/v1/test-scope-read", created.Key); resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Escopo concedido: Esperado 204, obteve %d", resp.StatusCode)
+		}
+		if resp, _ := apiKeyRequest(t, http.MethodGet, "/v1/test-scope-write", created.Key); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Escopo ausente: Esperado 403, obteve %d", resp.StatusCode)
+		}
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/test-scope-write", Token: userToken,
+		})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Sessão do usuário: Esperado 204, obteve %d", resp.StatusCode)
+		}
+		resp, _ = tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/test-scope-read",
+		})
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Anônimo: Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Api-Key expirada 401", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		expired := &model.APIKey{Name: "velha", UserID: fixtures.NormalUser.ID, ExpiresAt: &past}
+		key := expired.GenerateKey()
+		testApp.DB.Create(expired)
+
+		if resp, _ := apiKeyRequest(t, http.MethodGet, "/v1/auth/me", key); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Api-Key de admin não gerencia credenciais", func(t *testing.T) {
+		adminKey := &model.APIKey{Name: "admin", UserID: fixtures.AdminUser.ID}
+		key := adminKey.GenerateKey()
+		testApp.DB.Create(adminKey)
+
+		if resp, _ := apiKeyRequest(t, http.MethodGet, "/v1/auth/me", key); resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200 em /auth/me, obteve %d", resp.StatusCode)
+		}
+		routes := []struct{ method, url string }{
+			{http.MethodGet, "/v1/api-keys"},
+			{http.MethodPost, "/v1/api-keys/" + jsonNumber(created.ID) + "/revoke"},
+			{http.MethodGet, "/v1/permissions"},
+			{http.MethodGet, "/v1/oauth2/clients"},
+			{http.MethodPost, "/v1/auth/logout-all"},
+			{http.MethodPost, "/v1/auth/change-password"},
+		}
+		for _, route := range routes {
+			resp, body := apiKeyRequest(t, route.method, route.url, key)
+			if resp.StatusCode != http.StatusForbidden {
+				t.Errorf("%s %s: Esperado 403, obteve %d: %s", route.method, route.url, resp.StatusCode, body)
+			}
+		}
+	})
+
+	t.Run("POST /api-keys/:id/revoke (inexistente 404)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: "/v1/api-keys/999999/revoke", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Esperado 404, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("POST /api-keys/:id/revoke", func(t *testing.T) {
+		url := "/v1/api-keys/" + jsonNumber(created.ID)
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: url + "/revoke", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"revoked_at":"`) {
+			t.Fatalf("Esperado 200 com revoked_at, obteve %d: %s", resp.StatusCode, body)
+		}
+		if resp, _ := apiKeyRequest(t, http.MethodGet, "/v1/auth/me", created.Key); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Chave revogada: Esperado 401, obteve %d", resp.StatusCode)
+		}
+
+		resp, _ = tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodDelete, URL: url, Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("DELETE: Esperado 405, obteve %d", resp.StatusCode)
+		}
+	})
+}
+
+func jsonNumber(id uint64) string {
+	data, _ := json.Marshal(id)
+	return string(data)
+}
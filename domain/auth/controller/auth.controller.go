@@ -2,13 +2,17 @@ package controller
 
 import (
 	"errors"
+	"grf/core/auth"
 	"grf/core/config"
 	"grf/core/exceptions"
+	"grf/core/keyring"
+	"grf/core/models"
 	"grf/domain/auth/dto"
 	"grf/domain/auth/mapper"
 	"grf/domain/auth/model"
 	"grf/domain/auth/repository"
 	"grf/domain/auth/service"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -16,54 +20,72 @@ import (
 )
 
 type Controller struct {
-	UserRepo     *repository.UserRepository
-	Validator    *validator.Validate
-	TokenService *service.TokenService
+	Config         *config.Config
+	UserRepo       *repository.UserRepository
+	Validator      *validator.Validate
+	TokenService   *service.TokenService
+	SessionService *service.SessionService
 }
 
 func NewAuthController(
 	db *gorm.DB,
 	config *config.Config,
+	keys *keyring.Keyring,
 	validate *validator.Validate,
+	userRevokers ...models.UserRevoker,
 ) *Controller {
+	tokenService := service.NewTokenService(db, config, keys)
+	tokenService.TokenRepo.UserRevokers = userRevokers
 	return &Controller{
-		UserRepo:     repository.NewUserRepository(db),
-		Validator:    validate,
-		TokenService: service.NewTokenService(db, config),
+		Config:         config,
+		UserRepo:       repository.NewUserRepository(db),
+		Validator:      validate,
+		TokenService:   tokenService,
+		SessionService: service.NewSessionService(db, config),
 	}
 }
 
 func (ac *Controller) ObtainToken(c *fiber.Ctx) error {
+	user, err := ac.checkCredentials(c)
+	if err != nil {
+		return err
+	}
+
+	access, refresh, err := ac.TokenService.GenerateTokenPair(user)
+	if err != nil {
+		return exceptions.NewInternal(err)
+	}
+
+	return c.JSON(dto.TokenResponseDTO{
+		AccessToken:  access,
+		RefreshToken: refresh,
+	})
+}
+
+// checkCredentials reads an ObtainTokenDTO and returns the active user it
+// identifies.
+func (ac *Controller) checkCredentials(c *fiber.Ctx) (*model.User, error) {
 	var input dto.ObtainTokenDTO
 	if err := c.BodyParser(&input); err != nil {
-		return exceptions.NewBadRequest("invalid_payload", err)
+		return nil, exceptions.NewBadRequest("invalid_payload", err)
 	}
 	if err := ac.Validator.Struct(input); err != nil {
-		return err
+		return nil, err
 	}
 
 	user, err := ac.UserRepo.FindUserByEmailOrUsername(input.Login)
 	if err != nil {
-		return exceptions.NewUnauthorized("invalid_credentials", err)
+		return nil, exceptions.NewUnauthorized("invalid_credentials", err)
 	}
 
 	if !user.IsActive {
-		return exceptions.NewUnauthorized("inactive_user", nil)
+		return nil, exceptions.NewUnauthorized("inactive_user", nil)
 	}
 
 	if !user.CheckPassword(input.Password) {
-		return exceptions.NewUnauthorized("invalid_credentials", nil)
+		return nil, exceptions.NewUnauthorized("invalid_credentials", nil)
 	}
-
-	access, refresh, err := ac.TokenService.GenerateTokenPair(&user)
-	if err != nil {
-		return exceptions.NewInternal(err)
-	}
-
-	return c.JSON(dto.TokenResponseDTO{
-		AccessToken:  access,
-		RefreshToken: refresh,
-	})
+	return &user, nil
 }
 
 func (ac *Controller) ObtainTokenRefresh(c *fiber.Ctx) error {
@@ -75,16 +97,11 @@ func (ac *Controller) ObtainTokenRefresh(c *fiber.Ctx) error {
 		return err
 	}
 
-	user, err := ac.TokenService.ValidateToken(input.Refresh, "refresh")
+	_, access, refresh, err := ac.TokenService.RotateRefreshToken(input.Refresh)
 	if err != nil {
 		return exceptions.NewError(fiber.StatusUnauthorized, err.Error(), err)
 	}
 
-	access, refresh, err := ac.TokenService.GenerateTokenPair(user)
-	if err != nil {
-		return exceptions.NewInternal(err)
-	}
-
 	return c.JSON(dto.TokenResponseDTO{
 		AccessToken:  access,
 		RefreshToken: refresh,
@@ -113,17 +130,111 @@ func (ac *Controller) ChangePassword(c *fiber.Ctx) error {
 		return exceptions.NewBadRequest("incorrect_new_password", nil)
 	}
 
-	if err := user.SetPassword(input.NewPassword); err != nil {
+	if err := user.SetPassword(input.NewPassword, ac.Config.PasswordHashCost); err != nil {
 		return exceptions.NewInternal(err)
 	}
 
 	if err := ac.UserRepo.Update(user); err != nil {
 		return exceptions.NewInternal(err)
 	}
+	if err := ac.TokenService.RevokeUserTokens(user); err != nil {
+		return exceptions.NewInternal(err)
+	}
+	if err := ac.SessionService.RevokeUserSessions(user); err != nil {
+		return exceptions.NewInternal(err)
+	}
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// Logout ends the session of the given refresh token, and with it the access
+// tokens issued alongside.
+func (ac *Controller) Logout(c *fiber.Ctx) error {
+	var input dto.RefreshTokenDTO
+	if err := c.BodyParser(&input); err != nil {
+		return exceptions.NewBadRequest("invalid_payload", err)
+	}
+	if err := ac.Validator.Struct(input); err != nil {
+		return err
+	}
+
+	if err := ac.TokenService.Logout(input.Refresh); err != nil {
+		return exceptions.NewError(fiber.StatusUnauthorized, err.Error(), err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// LogoutAll ends every session of the authenticated user, token or cookie.
+func (ac *Controller) LogoutAll(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*model.User)
+	if !ok {
+		return exceptions.NewInternal(errors.New("c.Locals(\"user\") não encontrado"))
+	}
+
+	if err := ac.TokenService.RevokeUserTokens(user); err != nil {
+		return exceptions.NewInternal(err)
+	}
+	if err := ac.SessionService.RevokeUserSessions(user); err != nil {
+		return exceptions.NewInternal(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// SessionLogin opens a cookie session for the browser front-end, which then
+// sends the CSRF token in a header on unsafe requests.
+func (ac *Controller) SessionLogin(c *fiber.Ctx) error {
+	user, err := ac.checkCredentials(c)
+	if err != nil {
+		return err
+	}
+
+	session, err := ac.SessionService.Login(user)
+	if err != nil {
+		return exceptions.NewInternal(err)
+	}
+
+	cfg := ac.SessionService.Config
+	c.Cookie(ac.sessionCookie(cfg.SessionCookieName, session.Key, session.ExpiresAt, true))
+	c.Cookie(ac.sessionCookie(cfg.CSRFCookieName, session.CSRFToken, session.ExpiresAt, false))
+
+	return c.JSON(dto.SessionResponseDTO{
+		User:      mapper.MapUserToResponse(user),
+		CSRFToken: session.CSRFToken,
+		ExpiresAt: session.ExpiresAt,
+	})
+}
+
+// SessionLogout ends the cookie session of the request and clears its
+// cookies.
+func (ac *Controller) SessionLogout(c *fiber.Ctx) error {
+	if session, ok := c.Locals(auth.SessionLocal).(*model.Session); ok {
+		if err := ac.SessionService.Logout(session); err != nil {
+			return exceptions.NewInternal(err)
+		}
+	}
+
+	cfg := ac.SessionService.Config
+	c.Cookie(ac.sessionCookie(cfg.SessionCookieName, "", time.Unix(0, 0), true))
+	c.Cookie(ac.sessionCookie(cfg.CSRFCookieName, "", time.Unix(0, 0), false))
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (ac *Controller) sessionCookie(name, value string, expires time.Time, httpOnly bool) *fiber.Cookie {
+	cfg := ac.SessionService.Config
+	return &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		Secure:   cfg.SessionCookieSecure,
+		HTTPOnly: httpOnly,
+		SameSite: cfg.SessionCookieSameSite,
+	}
+}
+
 func (ac *Controller) GetMe(c *fiber.Ctx) error {
 
 	user, ok := c.Locals("user").(*model.User)
@@ -134,3 +245,10 @@ func (ac *Controller) GetMe(c *fiber.Ctx) error {
 	response := mapper.MapUserToResponse(user)
 	return c.JSON(response)
 }
+
+// JWKS publishes the public keys tokens are signed with, so other services
+// can verify them without sharing a secret.
+func (ac *Controller) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(ac.TokenService.Keys.JWKS())
+}
@@ -105,3 +105,145 @@ func TestAuthEndpoints(t *testing.T) {
 		loginAs(t, "user", "novasenha123")
 	})
 }
+
+func meStatus(t *testing.T, accessToken string) int {
+	resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+		Method: http.MethodGet,
+		URL:    "/v1/auth/me",
+		Token:  accessToken,
+	})
+	return resp.StatusCode
+}
+
+func refreshTokens(t *testing.T, refreshToken string) (int, dto.TokenResponseDTO) {
+	resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+		Method: http.MethodPost,
+		URL:    "/v1/auth/refresh",
+		Body:   dto.RefreshTokenDTO{Refresh: refreshToken},
+	})
+	var tokens dto.TokenResponseDTO
+	if resp.StatusCode == http.StatusOK {
+		if err := json.Unmarshal([]byte(body), &tokens); err != nil {
+			t.Fatalf("Resposta de refresh inválida: %s", body)
+		}
+	}
+	return resp.StatusCode, tokens
+}
+
+func TestTokenRevocation(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	_, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	t.Run("Refresh rotaciona e reuso revoga a família", func(t *testing.T) {
+		access, refresh := loginAs(t, "user", "user123")
+
+		status, rotated := refreshTokens(t, refresh)
+		if status != http.StatusOK {
+			t.Fatalf("Refresh: Esperado 200, obteve %d", status)
+		}
+		if rotated.RefreshToken == refresh {
+			t.Error("Esperado um novo refresh token")
+		}
+		if meStatus(t, access) != http.StatusOK || meStatus(t, rotated.AccessToken) != http.StatusOK {
+			t.Error("Esperado que os access tokens da família continuem válidos após a rotação")
+		}
+
+		if status, _ := refreshTokens(t, refresh); status != http.StatusUnauthorized {
+			t.Errorf("Reuso: Esperado 401, obteve %d", status)
+		}
+		if status, _ := refreshTokens(t, rotated.RefreshToken); status != http.StatusUnauthorized {
+			t.Errorf("Refresh após reuso: Esperado 401, obteve %d", status)
+		}
+		if status := meStatus(t, rotated.AccessToken); status != http.StatusUnauthorized {
+			t.Errorf("Access após reuso: Esperado 401, obteve %d", status)
+		}
+	})
+
+	t.Run("Logout encerra apenas a sessão do refresh token", func(t *testing.T) {
+		access, refresh := loginAs(t, "user", "user123")
+		otherAccess, _ := loginAs(t, "user", "user123")
+
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost,
+			URL:    "/v1/auth/logout",
+			Body:   dto.RefreshTokenDTO{Refresh: refresh},
+		})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Logout: Esperado 204, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		if status := meStatus(t, access); status != http.StatusUnauthorized {
+			t.Errorf("Access após logout: Esperado 401, obteve %d", status)
+		}
+		if status, _ := refreshTokens(t, refresh); status != http.StatusUnauthorized {
+			t.Errorf("Refresh após logout: Esperado 401, obteve %d", status)
+		}
+		if status := meStatus(t, otherAccess); status != http.StatusOK {
+			t.Errorf("Outra sessão: Esperado 200, obteve %d", status)
+		}
+	})
+
+	t.Run("Logout com token inválido", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost,
+			URL:    "/v1/auth/logout",
+			Body:   dto.RefreshTokenDTO{Refresh: "invalido"},
+		})
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Logout-all encerra todas as sessões", func(t *testing.T) {
+		access, refresh := loginAs(t, "user", "user123")
+		otherAccess, _ := loginAs(t, "user", "user123")
+		adminAccess, _ := loginAs(t, "admin", "admin123")
+
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost,
+			URL:    "/v1/auth/logout-all",
+			Token:  access,
+		})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Logout-all: Esperado 204, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		if meStatus(t, access) != http.StatusUnauthorized || meStatus(t, otherAccess) != http.StatusUnauthorized {
+			t.Error("Esperado 401 em todas as sessões do usuário")
+		}
+		if status, _ := refreshTokens(t, refresh); status != http.StatusUnauthorized {
+			t.Errorf("Refresh após logout-all: Esperado 401, obteve %d", status)
+		}
+		if status := meStatus(t, adminAccess); status != http.StatusOK {
+			t.Errorf("Sessão de outro usuário: Esperado 200, obteve %d", status)
+		}
+	})
+
+	t.Run("Troca de senha revoga os tokens", func(t *testing.T) {
+		access, refresh := loginAs(t, "user", "user123")
+
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost,
+			URL:    "/v1/auth/change-password",
+			Token:  access,
+			Body:   dto.ChangePasswordDTO{OldPassword: "user123", NewPassword: "novasenha123", RepeatNewPassword: "novasenha123"},
+		})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("ChangePassword: Esperado 204, obteve %d", resp.StatusCode)
+		}
+
+		if status := meStatus(t, access); status != http.StatusUnauthorized {
+			t.Errorf("Access após troca de senha: Esperado 401, obteve %d", status)
+		}
+		if status, _ := refreshTokens(t, refresh); status != http.StatusUnauthorized {
+			t.Errorf("Refresh após troca de senha: Esperado 401, obteve %d", status)
+		}
+		newAccess, _ := loginAs(t, "user", "novasenha123")
+		if status := meStatus(t, newAccess); status != http.StatusOK {
+			t.Errorf("Nova sessão: Esperado 200, obteve %d", status)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	controllers "grf/core/controller"
+	"grf/core/filterset"
 	"grf/core/pagination"
 	"grf/core/repository"
 	"grf/core/service"
@@ -46,9 +47,14 @@ func NewDefaultGroupController(
 		Service:       groupService,
 		Validator:     validate,
 		Paginator:     groupPaginator,
+		Ordering:      filterset.NewOrderingFilter([]string{"id", "name", "created_at"}, "id"),
+		Expandable:    map[string]string{"permissions": "Permissions"},
 		MapToResponse: mapper.MapGroupToResponse,
 		NewFilterSet:  func() *filter.GroupFilterSet { return new(filter.GroupFilterSet) },
-		NewPatchDTO:   func() *dto.GroupPatchDTO { return new(dto.GroupPatchDTO) },
+		NewSearchFilter: func() filterset.IFilterSet {
+			return filterset.NewSearchFilter("name")
+		},
+		NewPatchDTO: func() *dto.GroupPatchDTO { return new(dto.GroupPatchDTO) },
 		ParseID: func(s string) (uint64, error) {
 			id, err := strconv.ParseUint(s, 10, 64)
 			return id, err
@@ -3,10 +3,20 @@ package controller_test
 import (
 	"encoding/json"
 	"fmt"
+	corecontroller "grf/core/controller"
+	"grf/core/models"
+	"grf/core/pagination"
+	"grf/core/permission"
+	"grf/core/routes"
 	"grf/core/tests"
+	"grf/domain/auth/controller"
 	authdto "grf/domain/auth/dto"
+	"grf/domain/auth/model"
+	authservice "grf/domain/auth/service"
 	"net/http"
 	"testing"
+
+	"github.com/gofiber/fiber/v2"
 )
 
 func TestGroupCRUD(t *testing.T) {
@@ -86,6 +96,23 @@ func TestGroupCRUD(t *testing.T) {
 		}
 	})
 
+	t.Run("GET /groups?search=atualizado (Admin 200)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/groups?search=atualizado", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var listResp pagination.Response[authdto.GroupResponseDTO]
+		if err := json.Unmarshal([]byte(body), &listResp); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if len(listResp.Results) != 1 || listResp.Results[0].ID != createdGroupID {
+			t.Errorf("Esperado somente o grupo %d, obteve %v", createdGroupID, listResp.Results)
+		}
+	})
+
 	t.Run("DELETE /groups/:id (Admin 204)", func(t *testing.T) {
 		url := fmt.Sprintf("/v1/groups/%d", createdGroupID)
 		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
@@ -96,3 +123,297 @@ func TestGroupCRUD(t *testing.T) {
 		}
 	})
 }
+
+func TestGroupReadOnlyRoutes(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	_, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	routes.RegisterModelController(&routes.RegisterModelOptions{
+		App:        testApp,
+		Router:     testApp.FiberApp.Group("/v1"),
+		Path:       "/readonly-groups",
+		Model:      new(model.Group),
+		Controller: controller.NewDefaultGroupController(testApp.DB, testApp.Validator),
+		Actions:    models.ReadOnlyActions,
+	})
+
+	adminToken, _ := loginAs(t, "admin", "admin123")
+	userToken, _ := loginAs(t, "user", "user123")
+
+	t.Run("GET /readonly-groups (Admin 200)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/readonly-groups", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("HEAD /readonly-groups e /readonly-groups/:id (Admin 200)", func(t *testing.T) {
+		var group model.Group
+		testApp.DB.First(&group)
+		for _, url := range []string{"/v1/readonly-groups", fmt.Sprintf("/v1/readonly-groups/%d", group.ID)} {
+			resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+				Method: http.MethodHead, URL: url, Token: adminToken,
+			})
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("%s: Esperado 200, obteve %d", url, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("HEAD /readonly-groups (Anônimo 401)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodHead, URL: "/v1/readonly-groups",
+		})
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("POST /readonly-groups (User 405)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: "/v1/readonly-groups", Token: userToken,
+			Body: authdto.GroupCreateDTO{Name: "Grupo"},
+		})
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Fatalf("Esperado 405, obteve %d: %s", resp.StatusCode, body)
+		}
+		if allow := resp.Header.Get("Allow"); allow != "GET, HEAD" {
+			t.Errorf("Esperado Allow 'GET, HEAD', obteve '%s'", allow)
+		}
+	})
+
+	t.Run("DELETE /readonly-groups/:id (Admin 405)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodDelete, URL: "/v1/readonly-groups/1", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Fatalf("Esperado 405, obteve %d: %s", resp.StatusCode, body)
+		}
+		if allow := resp.Header.Get("Allow"); allow != "GET, HEAD" {
+			t.Errorf("Esperado Allow 'GET, HEAD', obteve '%s'", allow)
+		}
+	})
+}
+
+func TestGroupActionPermissions(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	_, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	routes.RegisterModelController(&routes.RegisterModelOptions{
+		App:        testApp,
+		Router:     testApp.FiberApp.Group("/v1"),
+		Path:       "/listable-groups",
+		Model:      new(model.Group),
+		Controller: controller.NewDefaultGroupController(testApp.DB, testApp.Validator),
+		Permission: permission.NewAnd(testApp.IsAuthenticated, testApp.IsAdmin),
+		ActionPermissions: map[string]permission.IPermission{
+			models.ListAction: testApp.IsAuthenticated,
+		},
+	})
+
+	adminToken, _ := loginAs(t, "admin", "admin123")
+	userToken, _ := loginAs(t, "user", "user123")
+
+	var groupID uint64
+	t.Run("POST /listable-groups (Admin 201)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: "/v1/listable-groups", Token: adminToken,
+			Body: authdto.GroupCreateDTO{Name: "Grupo Listável"},
+		})
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Esperado 201, obteve %d: %s", resp.StatusCode, body)
+		}
+		var respDTO authdto.GroupResponseDTO
+		if err := json.Unmarshal([]byte(body), &respDTO); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		groupID = respDTO.ID
+	})
+
+	t.Run("GET /listable-groups (User 200)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/listable-groups", Token: userToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("GET /listable-groups (Anônimo 401)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/listable-groups",
+		})
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GET /listable-groups/:id (User 403)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: fmt.Sprintf("/v1/listable-groups/%d", groupID), Token: userToken,
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("DELETE /listable-groups/:id (User 403)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodDelete, URL: fmt.Sprintf("/v1/listable-groups/%d", groupID), Token: userToken,
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestGroupObjectPermissions(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	routes.RegisterModelController(&routes.RegisterModelOptions{
+		App:        testApp,
+		Router:     testApp.FiberApp.Group("/v1"),
+		Path:       "/granted-groups",
+		Model:      new(model.Group),
+		Controller: controller.NewDefaultGroupController(testApp.DB, testApp.Validator),
+		Permission: permission.NewAnd(
+			testApp.IsAuthenticated,
+			permission.NewObjectPermissions(testApp.DB, new(model.Group)),
+		),
+	})
+
+	grants := authservice.NewObjectPermissionService(testApp.DB)
+	first := &model.Group{Name: "Primeiro"}
+	second := &model.Group{Name: "Segundo"}
+	editors := &model.Group{Name: "Editores"}
+	for _, group := range []*model.Group{first, second, editors} {
+		if err := testApp.DB.Create(group).Error; err != nil {
+			t.Fatalf("Falha ao criar grupo: %v", err)
+		}
+	}
+
+	userToken, _ := loginAs(t, "user", "user123")
+	patch := func(group *model.Group) int {
+		name := group.Name + " Editado"
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPatch, URL: fmt.Sprintf("/v1/granted-groups/%d", group.ID), Token: userToken,
+			Body: authdto.GroupPatchDTO{Name: &name},
+		})
+		return resp.StatusCode
+	}
+
+	t.Run("PATCH /granted-groups/:id (Sem permissão 403)", func(t *testing.T) {
+		if status := patch(first); status != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", status)
+		}
+	})
+
+	t.Run("PATCH /granted-groups/:id (Permissão no objeto 200)", func(t *testing.T) {
+		if err := grants.AssignToUser(fixtures.NormalUser, "group.partialupdate", first); err != nil {
+			t.Fatalf("Falha ao conceder permissão: %v", err)
+		}
+		if status := patch(first); status != http.StatusOK {
+			t.Errorf("Esperado 200, obteve %d", status)
+		}
+		if status := patch(second); status != http.StatusForbidden {
+			t.Errorf("Esperado 403 no outro grupo, obteve %d", status)
+		}
+	})
+
+	t.Run("GET /granted-groups/:id (Permissão via grupo 200)", func(t *testing.T) {
+		if err := testApp.DB.Model(fixtures.NormalUser).Association("Groups").Append(editors); err != nil {
+			t.Fatalf("Falha ao adicionar usuário ao grupo: %v", err)
+		}
+		if err := grants.AssignToGroup(editors, "group.detail", second); err != nil {
+			t.Fatalf("Falha ao conceder permissão: %v", err)
+		}
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: fmt.Sprintf("/v1/granted-groups/%d", second.ID), Token: userToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("GET /granted-groups (Sem permissão de modelo 403)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/granted-groups", Token: userToken,
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("ObjectsWithPerm filtra os grupos concedidos", func(t *testing.T) {
+		var groups []*model.Group
+		scope := grants.Repo.ObjectsWithPerm(fixtures.NormalUser, "group", models.DetailAction, new(model.Group))
+		if err := testApp.DB.Scopes(scope).Find(&groups).Error; err != nil {
+			t.Fatalf("Falha ao filtrar grupos: %v", err)
+		}
+		if len(groups) != 1 || groups[0].ID != second.ID {
+			t.Errorf("Esperado apenas o grupo %d, obteve %v", second.ID, groups)
+		}
+
+		var all []*model.Group
+		adminScope := grants.Repo.ObjectsWithPerm(fixtures.AdminUser, "group", models.DetailAction, new(model.Group))
+		if err := testApp.DB.Scopes(adminScope).Find(&all).Error; err != nil {
+			t.Fatalf("Falha ao filtrar grupos: %v", err)
+		}
+		if len(all) != 4 {
+			t.Errorf("Esperado 4 grupos para o admin, obteve %d", len(all))
+		}
+	})
+
+	t.Run("POST /assignable-groups/:id/assign-members (Sem permissão de modelo 403)", func(t *testing.T) {
+		groupController := controller.NewDefaultGroupController(testApp.DB, testApp.Validator)
+		groupController.ExtraActions = []corecontroller.Action{{
+			Method:      http.MethodPost,
+			Path:        "/assign-members",
+			Detail:      true,
+			Codename:    "group.assign_members",
+			Description: "Permission to assign members to auth_group records.",
+			Handler: func(c *fiber.Ctx) error {
+				return c.SendStatus(http.StatusNoContent)
+			},
+		}}
+		routes.RegisterModelController(&routes.RegisterModelOptions{
+			App:        testApp,
+			Router:     testApp.FiberApp.Group("/v1"),
+			Path:       "/assignable-groups",
+			Model:      new(model.Group),
+			Controller: groupController,
+			Permission: permission.NewAnd(
+				testApp.IsAuthenticated,
+				permission.NewObjectPermissions(testApp.DB, new(model.Group)),
+			),
+		})
+
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: fmt.Sprintf("/v1/assignable-groups/%d/assign-members", first.ID), Token: userToken,
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("PATCH /granted-groups/:id (Permissão removida 403)", func(t *testing.T) {
+		if err := grants.RemoveFromUser(fixtures.NormalUser, "group.partialupdate", first); err != nil {
+			t.Fatalf("Falha ao remover permissão: %v", err)
+		}
+		if status := patch(first); status != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", status)
+		}
+	})
+}
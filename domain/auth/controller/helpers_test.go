@@ -13,6 +13,13 @@ import (
 )
 
 var authTables = []string{
+	"auth_blacklisted_token",
+	"auth_outstanding_token",
+	"auth_api_key",
+	"auth_session",
+	"auth_external_identity_groups",
+	"auth_external_identity",
+	"auth_object_permission",
 	"auth_user_permissions",
 	"auth_user_groups",
 	"auth_group_permissions",
@@ -22,6 +29,7 @@ var authTables = []string{
 
 func clearAuthTables(db *gorm.DB) {
 	tests2.ClearTables(db, authTables)
+	testApp.PermissionResolver.Invalidate()
 }
 
 type TestFixtures struct {
@@ -57,10 +65,6 @@ func createTestFixtures(db *gorm.DB) (*TestFixtures, error) {
 
 		getPerm(db, "permission", models.ListAction),
 		getPerm(db, "permission", models.DetailAction),
-		getPerm(db, "permission", models.CreateAction),
-		getPerm(db, "permission", models.UpdateAction),
-		getPerm(db, "permission", models.PartialUpdateAction),
-		getPerm(db, "permission", models.DeleteAction),
 	}
 	adminGroup := model.Group{Name: "Admin"}
 	if err := db.Create(&adminGroup).Error; err != nil {
@@ -72,7 +76,7 @@ func createTestFixtures(db *gorm.DB) (*TestFixtures, error) {
 	}
 
 	adminUser := model.User{Username: "admin", Email: "admin@test.com", IsActive: true, IsSuperuser: true}
-	err = adminUser.SetPassword("admin123")
+	err = adminUser.SetPassword("admin123", testApp.Config.PasswordHashCost)
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +85,7 @@ func createTestFixtures(db *gorm.DB) (*TestFixtures, error) {
 	}
 
 	normalUser := model.User{Username: "user", Email: "user@test.com", IsActive: true}
-	err = normalUser.SetPassword("user123")
+	err = normalUser.SetPassword("user123", testApp.Config.PasswordHashCost)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +102,7 @@ func createTestFixtures(db *gorm.DB) (*TestFixtures, error) {
 	}, nil
 }
 
-func loginAs(t *testing.T, username, password string) (accessToken, refreshToken string) {
+func loginAs(t testing.TB, username, password string) (accessToken, refreshToken string) {
 	loginDTO := dto.ObtainTokenDTO{
 		Login:    username,
 		Password: password,
@@ -0,0 +1,233 @@
+package controller_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"grf/core/config"
+	"grf/core/keyring"
+	"grf/core/tests"
+	"grf/domain/auth/controller"
+	"grf/domain/auth/service"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKeyPair writes the private key as PKCS#8 and its public key as PKIX.
+func writeKeyPair(t *testing.T, name string, key crypto.Signer) (privateFile, publicFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	privateDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Falha ao serializar chave privada: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatalf("Falha ao serializar chave pública: %v", err)
+	}
+	privateFile = filepath.Join(dir, name+".pem")
+	publicFile = filepath.Join(dir, name+".pub.pem")
+	os.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600)
+	os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o644)
+	return privateFile, publicFile
+}
+
+func loadKeyring(t *testing.T, privateFile string, publicFiles ...string) *keyring.Keyring {
+	t.Helper()
+	keys, err := keyring.FromConfig(&config.Config{JWTPrivateKeyFile: privateFile, JWTPublicKeyFiles: publicFiles})
+	if err != nil {
+		t.Fatalf("Falha ao carregar chaves: %v", err)
+	}
+	return keys
+}
+
+// jwkPublicKey rebuilds the public key a JWK describes, as a client would.
+func jwkPublicKey(t *testing.T, jwk keyring.JWK) interface{} {
+	t.Helper()
+	decode := func(s string) []byte {
+		data, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatalf("JWK com base64 inválido: %v", err)
+		}
+		return data
+	}
+	switch jwk.Kty {
+	case "RSA":
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(decode(jwk.N)), E: int(new(big.Int).SetBytes(decode(jwk.E)).Int64())}
+	case "EC":
+		point := append([]byte{4}, append(decode(jwk.X), decode(jwk.Y)...)...)
+		key, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+		if err != nil {
+			t.Fatalf("JWK EC inválido: %v", err)
+		}
+		return key
+	case "OKP":
+		return ed25519.PublicKey(decode(jwk.X))
+	}
+	t.Fatalf("kty inesperado %q", jwk.Kty)
+	return nil
+}
+
+func TestJWTKeyring(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	signers := []struct {
+		alg string
+		key crypto.Signer
+	}{
+		{"RS256", rsaKey},
+		{"ES256", ecKey},
+		{"EdDSA", edKey},
+	}
+
+	t.Run("JWKS vazio com HS256", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet,
+			URL:    "/.well-known/jwks.json",
+		})
+		if resp.StatusCode != http.StatusOK || body != `{"keys":[]}` {
+			t.Errorf("Esperado JWKS vazio, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("HEAD JWKS 200", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodHead,
+			URL:    "/.well-known/jwks.json",
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("Esperado 200, obteve %d", resp.StatusCode)
+		}
+	})
+
+	for _, signer := range signers {
+		t.Run("Assina e publica chave "+signer.alg, func(t *testing.T) {
+			privateFile, _ := writeKeyPair(t, signer.alg, signer.key)
+			keys := loadKeyring(t, privateFile)
+			tokenService := service.NewTokenService(testApp.DB, testApp.Config, keys)
+
+			access, _, err := tokenService.GenerateTokenPair(fixtures.NormalUser)
+			if err != nil {
+				t.Fatalf("Falha ao gerar tokens: %v", err)
+			}
+			if _, err := tokenService.ValidateToken(access, "access"); err != nil {
+				t.Errorf("Esperado token válido, obteve %v", err)
+			}
+
+			url := "/test/jwks/" + signer.alg
+			testApp.FiberApp.Get(url, controller.NewAuthController(testApp.DB, testApp.Config, keys, testApp.Validator).JWKS)
+			resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: url})
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("JWKS: Esperado 200, obteve %d", resp.StatusCode)
+			}
+			var set keyring.JWKSet
+			if err := json.Unmarshal([]byte(body), &set); err != nil || len(set.Keys) != 1 {
+				t.Fatalf("Esperado uma chave no JWKS, obteve %s", body)
+			}
+			jwk := set.Keys[0]
+			if jwk.Alg != signer.alg || jwk.Use != "sig" || jwk.Kid != keys.Active.ID {
+				t.Errorf("JWK inesperado: %+v", jwk)
+			}
+
+			token, err := jwt.Parse(access, func(token *jwt.Token) (interface{}, error) {
+				if token.Header["kid"] != jwk.Kid {
+					t.Errorf("Esperado kid %s, obteve %v", jwk.Kid, token.Header["kid"])
+				}
+				return jwkPublicKey(t, jwk), nil
+			}, jwt.WithValidMethods([]string{signer.alg}))
+			if err != nil || !token.Valid {
+				t.Errorf("Esperado token verificável com o JWKS, obteve %v", err)
+			}
+		})
+	}
+
+	t.Run("Rotação aceita tokens da chave anterior", func(t *testing.T) {
+		oldPrivate, oldPublic := writeKeyPair(t, "old", rsaKey)
+		newPrivate, _ := writeKeyPair(t, "new", ecKey)
+
+		oldService := service.NewTokenService(testApp.DB, testApp.Config, loadKeyring(t, oldPrivate))
+		oldAccess, _, err := oldService.GenerateTokenPair(fixtures.NormalUser)
+		if err != nil {
+			t.Fatalf("Falha ao gerar tokens: %v", err)
+		}
+
+		rotated := loadKeyring(t, newPrivate, oldPublic)
+		if len(rotated.JWKS().Keys) != 2 {
+			t.Errorf("Esperado as duas chaves publicadas, obteve %d", len(rotated.JWKS().Keys))
+		}
+		rotatedService := service.NewTokenService(testApp.DB, testApp.Config, rotated)
+		if _, err := rotatedService.ValidateToken(oldAccess, "access"); err != nil {
+			t.Errorf("Esperado token da chave anterior válido, obteve %v", err)
+		}
+		newAccess, _, _ := rotatedService.GenerateTokenPair(fixtures.NormalUser)
+		parsed, _, _ := jwt.NewParser().ParseUnverified(newAccess, jwt.MapClaims{})
+		if parsed.Header["kid"] != rotated.Active.ID || parsed.Method.Alg() != "ES256" {
+			t.Errorf("Esperado token assinado pela nova chave, obteve %v", parsed.Header)
+		}
+
+		retired := service.NewTokenService(testApp.DB, testApp.Config, loadKeyring(t, newPrivate))
+		if _, err := retired.ValidateToken(oldAccess, "access"); err == nil {
+			t.Error("Esperado token rejeitado após remover a chave anterior")
+		}
+	})
+
+	t.Run("Rejeita HS256 assinado com a chave pública", func(t *testing.T) {
+		privateFile, publicFile := writeKeyPair(t, "rsa", rsaKey)
+		keys := loadKeyring(t, privateFile)
+		publicPEM, _ := os.ReadFile(publicFile)
+
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, service.CustomClaims{
+			UserID: fixtures.AdminUser.ID,
+			Type:   "access",
+			Family: "forjada",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ID: "forjado",
+			},
+		})
+		forged.Header["kid"] = keys.Active.ID
+		forgedToken, _ := forged.SignedString(publicPEM)
+
+		tokenService := service.NewTokenService(testApp.DB, testApp.Config, keys)
+		if _, err := tokenService.ValidateToken(forgedToken, "access"); err == nil {
+			t.Error("Esperado token HS256 rejeitado")
+		}
+		hmacAccess, _ := loginAs(t, "user", "user123")
+		if _, err := tokenService.ValidateToken(hmacAccess, "access"); err == nil {
+			t.Error("Esperado token do segredo compartilhado rejeitado")
+		}
+	})
+
+	t.Run("Configuração inválida", func(t *testing.T) {
+		if _, err := keyring.FromConfig(&config.Config{JWTPublicKeyFiles: []string{"a.pem"}}); err == nil {
+			t.Error("Esperado erro com chaves públicas sem chave privada")
+		}
+		_, publicFile := writeKeyPair(t, "pub", ecKey)
+		if _, err := keyring.FromConfig(&config.Config{JWTPrivateKeyFile: publicFile}); err == nil {
+			t.Error("Esperado erro com chave pública como chave de assinatura")
+		}
+		smallKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+		smallFile, _ := writeKeyPair(t, "small", smallKey)
+		if _, err := keyring.FromConfig(&config.Config{JWTPrivateKeyFile: smallFile}); err == nil {
+			t.Error("Esperado erro com chave RSA de 1024 bits")
+		}
+	})
+}
@@ -7,21 +7,30 @@ import (
 	"grf/domain/auth"
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 var testApp *server.App
 var err error
 
 func TestMain(m *testing.M) {
+	jwksFile, err := writeOIDCStandIn()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	testApp, err = bootstrap.NewApp(config.Config{
 		DBName:               "file:memdb1?mode=memory&cache=shared",
 		DBVendor:             "sqlite",
 		DBMigrate:            true,
+		PermissionSync:       true,
 		DBLogLevel:           "info",
 		DBMaxIdle:            10,
 		DBMaxOpened:          30,
-		DBMaxLifeTimeSeconds: 30,
+		DBMaxLifeTimeSeconds: 600, // o banco em memória some quando todas as conexões fecham
 
 		AppName: "TestGRF",
 
@@ -35,7 +44,23 @@ func TestMain(m *testing.M) {
 		JWTSecret:               "test_super_secret_jwt_secret_do_not_verify",
 		JWTExpiresInMinutes:     30,
 		JWTRefreshExpiresInDays: 1,
-	}, auth.GetModels())
+
+		PasswordHashCost: bcrypt.MinCost,
+
+		SessionCookieName:     "sessionid",
+		SessionCookieAge:      3600,
+		SessionCookieSameSite: "Lax",
+		CSRFCookieName:        "csrftoken",
+		CSRFHeaderName:        "X-CSRFToken",
+
+		OIDCIssuer:           oidcIssuer,
+		OIDCAudience:         oidcAudience,
+		OIDCJWKSFile:         jwksFile,
+		OIDCJWKSCacheSeconds: 300,
+		OIDCUsernameClaim:    "preferred_username",
+		OIDCCreateUsers:      true,
+		OIDCGroupsClaim:      "groups",
+	}, auth.GetModels(), auth.GetMigrations())
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -43,5 +68,6 @@ func TestMain(m *testing.M) {
 	code := m.Run()
 
 	log.Println("Suíte de testes 'auth' concluída.")
+	os.RemoveAll(filepath.Dir(jwksFile))
 	os.Exit(code)
 }
@@ -0,0 +1,178 @@
+package controller_test
+
+import (
+	"bytes"
+	"grf/core/management"
+	"grf/core/migrations"
+	"grf/domain/auth"
+	"grf/domain/auth/model"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func newTestCLI(input string) (*management.CLI, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	cli := management.New(*testApp.Config, auth.GetModule())
+	cli.Context.SetApp(testApp)
+	cli.Context.In = strings.NewReader(input)
+	cli.Context.Out = out
+	cli.Context.Err = out
+	return cli, out
+}
+
+func TestManagementCommands(t *testing.T) {
+	clearAuthTables(testApp.DB)
+
+	t.Run("createsuperuser sem interação", func(t *testing.T) {
+		t.Setenv("SUPERUSER_PASSWORD", "Superuser@123")
+		cli, out := newTestCLI("")
+		err := cli.Run([]string{"createsuperuser", "-no-input", "-username", "root", "-email", "root@test.com"})
+		if err != nil {
+			t.Fatalf("Falha ao criar superusuário: %v (%s)", err, out)
+		}
+
+		var user model.User
+		if err := testApp.DB.Where("username = ?", "root").First(&user).Error; err != nil {
+			t.Fatalf("Superusuário não encontrado: %v", err)
+		}
+		if !user.IsSuperuser || !user.IsStaff || !user.IsActive {
+			t.Errorf("Esperado superusuário ativo e staff, obteve %+v", user)
+		}
+
+		if err := cli.Run([]string{"createsuperuser", "-no-input", "-username", "root", "-email", "root@test.com"}); err == nil {
+			t.Error("Esperado erro ao repetir o username")
+		}
+	})
+
+	t.Run("createsuperuser sem senha falha com -no-input", func(t *testing.T) {
+		t.Setenv("SUPERUSER_PASSWORD", "")
+		cli, _ := newTestCLI("")
+		if err := cli.Run([]string{"createsuperuser", "-no-input", "-username", "other", "-email", "other@test.com"}); err == nil {
+			t.Error("Esperado erro sem SUPERUSER_PASSWORD")
+		}
+	})
+
+	t.Run("changepassword lê a senha da entrada", func(t *testing.T) {
+		access, _ := loginAs(t, "root", "Superuser@123")
+		cli, out := newTestCLI("Changed@123\nChanged@123\n")
+		if err := cli.Run([]string{"changepassword", "root"}); err != nil {
+			t.Fatalf("Falha ao trocar a senha: %v (%s)", err, out)
+		}
+
+		var user model.User
+		testApp.DB.Where("username = ?", "root").First(&user)
+		if !user.CheckPassword("Changed@123") {
+			t.Error("Esperado que a nova senha fosse aceita")
+		}
+		if status := meStatus(t, access); status != http.StatusUnauthorized {
+			t.Errorf("Access após changepassword: Esperado 401, obteve %d", status)
+		}
+	})
+
+	t.Run("changepassword com senhas diferentes falha", func(t *testing.T) {
+		cli, _ := newTestCLI("Changed@123\nOutra@1234\n")
+		if err := cli.Run([]string{"changepassword", "root"}); err == nil {
+			t.Error("Esperado erro com confirmação diferente")
+		}
+	})
+
+	t.Run("showurls lista nomes e permissões", func(t *testing.T) {
+		cli, out := newTestCLI("")
+		if err := cli.Run([]string{"showurls"}); err != nil {
+			t.Fatalf("Falha ao listar rotas: %v", err)
+		}
+		for _, expected := range []string{"users.set_password", "ModelPermissions(user)", "auth.token", "AllowAny"} {
+			if !strings.Contains(out.String(), expected) {
+				t.Errorf("Esperado %q na saída:\n%s", expected, out)
+			}
+		}
+	})
+
+	t.Run("flushexpiredtokens remove apenas os expirados", func(t *testing.T) {
+		var user model.User
+		testApp.DB.Where("username = ?", "root").First(&user)
+		expired := &model.OutstandingToken{UserID: user.ID, JTI: "expirado", Family: "f1", ExpiresAt: time.Now().Add(-time.Hour)}
+		valid := &model.OutstandingToken{UserID: user.ID, JTI: "valido", Family: "f2", ExpiresAt: time.Now().Add(time.Hour)}
+		testApp.DB.Create(expired)
+		testApp.DB.Create(valid)
+		testApp.DB.Create(&model.BlacklistedToken{TokenID: expired.ID, Reason: model.TokenLogout})
+
+		cli, out := newTestCLI("")
+		if err := cli.Run([]string{"flushexpiredtokens"}); err != nil {
+			t.Fatalf("Falha ao limpar tokens: %v (%s)", err, out)
+		}
+
+		var count int64
+		testApp.DB.Model(&model.OutstandingToken{}).Where("jti IN ?", []string{"expirado", "valido"}).Count(&count)
+		if count != 1 {
+			t.Errorf("Esperado apenas o token válido, obteve %d tokens", count)
+		}
+		testApp.DB.Model(&model.BlacklistedToken{}).Where("token_id = ?", expired.ID).Count(&count)
+		if count != 0 {
+			t.Error("Esperado que a blacklist do token expirado fosse removida")
+		}
+	})
+
+	t.Run("check aponta migration sem script para o dialeto", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		cli := management.New(*testApp.Config, auth.GetModule(), &management.Module{
+			Name: "reports",
+			Migrations: []*migrations.Migration{{
+				Module:   "reports",
+				Version:  1,
+				Name:     "initial",
+				Up:       func(tx *gorm.DB) error { return nil },
+				Dialects: []string{"mysql", "postgres"},
+			}},
+		})
+		cli.Context.SetApp(testApp)
+		cli.Context.Out = out
+		cli.Context.Err = out
+
+		if err := cli.Run([]string{"check"}); err == nil {
+			t.Errorf("Esperado falha no check, obteve:\n%s", out)
+		}
+		if !strings.Contains(out.String(), "ERROR: migration reports.0001_initial has no script for sqlite") {
+			t.Errorf("Esperado erro sobre o dialeto, obteve:\n%s", out)
+		}
+	})
+
+	t.Run("check exige OIDC_AUDIENCE", func(t *testing.T) {
+		cfg := *testApp.Config
+		cfg.OIDCAudience = ""
+		out := &bytes.Buffer{}
+		cli := management.New(cfg, auth.GetModule())
+		cli.Context.SetApp(testApp)
+		cli.Context.Out = out
+		cli.Context.Err = out
+
+		if err := cli.Run([]string{"check"}); err == nil || !strings.Contains(out.String(), "ERROR: OIDC_AUDIENCE is empty") {
+			t.Errorf("Esperado erro sobre OIDC_AUDIENCE, obteve %v:\n%s", err, out)
+		}
+	})
+
+	t.Run("check recusa PASSWORD_HASH_COST baixo fora de development", func(t *testing.T) {
+		cfg := *testApp.Config
+		cfg.Env = "production"
+		out := &bytes.Buffer{}
+		cli := management.New(cfg, auth.GetModule())
+		cli.Context.SetApp(testApp)
+		cli.Context.Out = out
+		cli.Context.Err = out
+
+		if err := cli.Run([]string{"check"}); err == nil || !strings.Contains(out.String(), "ERROR: PASSWORD_HASH_COST is below 12") {
+			t.Errorf("Esperado erro sobre PASSWORD_HASH_COST, obteve %v:\n%s", err, out)
+		}
+	})
+
+	t.Run("comando desconhecido", func(t *testing.T) {
+		cli, _ := newTestCLI("")
+		if err := cli.Run([]string{"nao-existe"}); err == nil {
+			t.Error("Esperado erro para comando desconhecido")
+		}
+	})
+}
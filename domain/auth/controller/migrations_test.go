@@ -0,0 +1,230 @@
+package controller_test
+
+import (
+	"bytes"
+	"errors"
+	"grf/core/config"
+	"grf/core/database"
+	"grf/core/migrations"
+	"grf/domain/auth"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func connectMigrationsDB(t *testing.T, name string) *gorm.DB {
+	db, err := database.ConnectDB(&config.Config{
+		DBName:               "file:" + name + "?mode=memory&cache=shared",
+		DBVendor:             "sqlite",
+		DBLogLevel:           "silent",
+		DBMaxIdle:            1,
+		DBMaxOpened:          1,
+		DBMaxLifeTimeSeconds: 30,
+	})
+	if err != nil {
+		t.Fatalf("Falha ao conectar: %v", err)
+	}
+	return db
+}
+
+func TestAuthMigrations(t *testing.T) {
+	db := connectMigrationsDB(t, "migrationsdb")
+	migrator := migrations.NewMigrator(db, auth.GetMigrations())
+	migrator.LockTimeout = 300 * time.Millisecond
+
+	t.Run("Status inicial pendente", func(t *testing.T) {
+		statuses, err := migrator.Status()
+		if err != nil {
+			t.Fatalf("Falha ao obter status: %v", err)
+		}
+		if len(statuses) == 0 || statuses[0].Migration.ID() != "auth.0001_initial" || statuses[0].AppliedAt != nil {
+			t.Errorf("Esperado auth.0001_initial pendente, obteve %+v", statuses)
+		}
+	})
+
+	t.Run("Up aplica as migrations uma vez", func(t *testing.T) {
+		applied, err := migrator.Up()
+		if err != nil {
+			t.Fatalf("Falha ao aplicar: %v", err)
+		}
+		if len(applied) != len(migrator.Migrations) {
+			t.Errorf("Esperado %d migrations aplicadas, obteve %d", len(migrator.Migrations), len(applied))
+		}
+		for _, table := range authTables {
+			if !db.Migrator().HasTable(table) {
+				t.Errorf("Esperado tabela %s", table)
+			}
+		}
+
+		applied, err = migrator.Up()
+		if err != nil {
+			t.Fatalf("Falha ao reaplicar: %v", err)
+		}
+		if len(applied) != 0 {
+			t.Errorf("Esperado nenhuma migration pendente, obteve %d", len(applied))
+		}
+	})
+
+	t.Run("Lock ocupado bloqueia Up", func(t *testing.T) {
+		if err := db.Exec("INSERT INTO schema_migrations_lock (id, owner, locked_at) VALUES (1, 'outro', ?)", time.Now()).Error; err != nil {
+			t.Fatalf("Falha ao criar lock: %v", err)
+		}
+		if _, err := migrator.Up(); !errors.Is(err, migrations.ErrLocked) {
+			t.Errorf("Esperado ErrLocked, obteve %v", err)
+		}
+		if err := migrator.Unlock(); err != nil {
+			t.Fatalf("Falha ao liberar lock: %v", err)
+		}
+		if _, err := migrator.Up(); err != nil {
+			t.Errorf("Esperado Up após unlock, obteve %v", err)
+		}
+	})
+
+	t.Run("Rollback desfaz a última migration", func(t *testing.T) {
+		reverted, err := migrator.Rollback(1)
+		if err != nil {
+			t.Fatalf("Falha ao reverter: %v", err)
+		}
+		if len(reverted) != 1 || reverted[0].ID() != "auth.0005_external_identity" {
+			t.Fatalf("Esperado auth.0005_external_identity revertida, obteve %v", reverted)
+		}
+		if db.Migrator().HasTable("auth_external_identity") || !db.Migrator().HasTable("auth_session") {
+			t.Error("Esperado apenas as tabelas da 0005 removidas")
+		}
+
+		reverted, err = migrator.Rollback(4)
+		if err != nil {
+			t.Fatalf("Falha ao reverter: %v", err)
+		}
+		if len(reverted) != 4 || reverted[2].ID() != "auth.0002_token_blacklist" || reverted[3].ID() != "auth.0001_initial" {
+			t.Fatalf("Esperado auth.0004_session a auth.0001_initial revertidas, obteve %v", reverted)
+		}
+		if db.Migrator().HasTable("auth_outstanding_token") || db.Migrator().HasTable("auth_user") {
+			t.Error("Esperado auth_outstanding_token e auth_user removidas")
+		}
+
+		statuses, err := migrator.Status()
+		if err != nil {
+			t.Fatalf("Falha ao obter status: %v", err)
+		}
+		if statuses[0].AppliedAt != nil {
+			t.Error("Esperado auth.0001_initial pendente após rollback")
+		}
+	})
+
+	t.Run("Rollback sem passos é erro", func(t *testing.T) {
+		if _, err := migrator.Rollback(0); err == nil {
+			t.Error("Esperado erro com 0 passos")
+		}
+	})
+}
+
+type authNote struct {
+	ID    uint64 `gorm:"primarykey"`
+	Title string `gorm:"size:100;index"`
+}
+
+func (authNote) TableName() string { return "auth_note" }
+
+type authNoteRenamed struct {
+	ID      uint64 `gorm:"primarykey"`
+	Heading string `gorm:"size:100"`
+}
+
+func (authNoteRenamed) TableName() string { return "auth_note" }
+
+func TestMakeMigrations(t *testing.T) {
+	db := connectMigrationsDB(t, "makemigrationsdb")
+	authMigrations := auth.GetMigrations()
+	if _, err := migrations.NewMigrator(db, authMigrations).Up(); err != nil {
+		t.Fatalf("Falha ao aplicar migrations: %v", err)
+	}
+	dir := t.TempDir()
+	var out bytes.Buffer
+	makeMigrations := func(name string, models ...interface{}) *migrations.MakeResult {
+		t.Helper()
+		out.Reset()
+		result, err := migrations.MakeMigrations(&migrations.MakeOptions{
+			DB:         db,
+			Module:     "auth",
+			Models:     append(auth.GetModels(), models...),
+			Migrations: authMigrations,
+			Dir:        dir,
+			Name:       name,
+			Out:        &out,
+		})
+		if err != nil {
+			t.Fatalf("Falha ao gerar migration: %v", err)
+		}
+		return result
+	}
+	apply := func() {
+		t.Helper()
+		generated, err := migrations.FromFS("auth", os.DirFS(dir))
+		if err != nil {
+			t.Fatalf("Falha ao carregar migrations geradas: %v", err)
+		}
+		authMigrations = append(auth.GetMigrations(), generated...)
+		if _, err := migrations.NewMigrator(db, authMigrations).Up(); err != nil {
+			t.Fatalf("Falha ao aplicar migrations geradas: %v", err)
+		}
+	}
+
+	t.Run("Models iguais à 0001_initial não geram mudanças", func(t *testing.T) {
+		if result := makeMigrations("noop"); len(result.Changes) != 0 {
+			for _, change := range result.Changes {
+				t.Errorf("Mudança inesperada: %s %v", change.Description, change.Up)
+			}
+		}
+	})
+
+	t.Run("Novo model gera CREATE TABLE", func(t *testing.T) {
+		result := makeMigrations("add_note", new(authNote))
+		if !strings.HasSuffix(result.UpFile, "0006_add_note.up.sqlite.sql") {
+			t.Fatalf("Esperado arquivo 0006_add_note, obteve %s", result.UpFile)
+		}
+		up, _ := os.ReadFile(result.UpFile)
+		if !strings.Contains(string(up), "CREATE TABLE `auth_note`") || !strings.Contains(string(up), "idx_auth_note_title") {
+			t.Errorf("Esperado CREATE TABLE e índice, obteve:\n%s", up)
+		}
+		if !strings.Contains(out.String(), "REVIEW 0006_add_note only runs on sqlite: write its mysql and postgres scripts") {
+			t.Errorf("Esperado aviso sobre os outros dialetos, obteve:\n%s", out.String())
+		}
+		apply()
+		if !db.Migrator().HasTable("auth_note") {
+			t.Error("Esperado tabela auth_note")
+		}
+
+		generated := authMigrations[len(authMigrations)-1]
+		if !generated.Supports("sqlite") || generated.Supports("mysql") {
+			t.Errorf("Esperado migration apenas para sqlite, obteve %v", generated.Dialects)
+		}
+	})
+
+	t.Run("Renomear campo é marcado para revisão", func(t *testing.T) {
+		result := makeMigrations("rename_title", new(authNoteRenamed))
+		up, _ := os.ReadFile(result.UpFile)
+		if !strings.Contains(string(up), "-- REVIEW: title was removed and heading added") ||
+			!strings.Contains(string(up), "-- ALTER TABLE `auth_note` RENAME COLUMN `title` TO `heading`;") {
+			t.Errorf("Esperado rename comentado para revisão, obteve:\n%s", up)
+		}
+		if !strings.Contains(string(up), "-- DROP INDEX `idx_auth_note_title`;") {
+			t.Errorf("Esperado remoção de índice comentada, obteve:\n%s", up)
+		}
+		apply()
+		if !db.Migrator().HasColumn("auth_note", "title") {
+			t.Error("Esperado coluna title mantida até a revisão")
+		}
+	})
+
+	t.Run("Model removido é marcado para revisão", func(t *testing.T) {
+		result := makeMigrations("drop_note")
+		up, _ := os.ReadFile(result.UpFile)
+		if !strings.Contains(string(up), "-- DROP TABLE `auth_note`;") {
+			t.Errorf("Esperado DROP TABLE comentado, obteve:\n%s", up)
+		}
+	})
+}
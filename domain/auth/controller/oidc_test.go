@@ -0,0 +1,335 @@
+package controller_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"grf/core/keyring"
+	"grf/core/tests"
+	"grf/domain/auth/model"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	oidcIssuer   = "https://idp.test"
+	oidcAudience = "grf-api"
+)
+
+// oidcKeys signs the tokens of the stand-in identity provider, whose JWK
+// Set writeOIDCStandIn publishes to a file.
+var oidcKeys *keyring.Keyring
+
+func writeOIDCStandIn() (string, error) {
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", err
+	}
+	key, err := keyring.NewKey(private)
+	if err != nil {
+		return "", err
+	}
+	oidcKeys = keyring.New(key)
+
+	dir, err := os.MkdirTemp("", "oidc")
+	if err != nil {
+		return "", err
+	}
+	data, _ := json.Marshal(oidcKeys.JWKS())
+	file := filepath.Join(dir, "jwks.json")
+	return file, os.WriteFile(file, data, 0o644)
+}
+
+// oidcToken is a token of the stand-in provider; extra overrides its
+// claims, a nil value removing the claim.
+func oidcToken(t *testing.T, username string, extra jwt.MapClaims) string {
+	t.Helper()
+	claims := jwt.MapClaims{
+		"iss":                oidcIssuer,
+		"aud":                oidcAudience,
+		"sub":                "idp|" + username,
+		"exp":                time.Now().Add(time.Minute).Unix(),
+		"iat":                time.Now().Unix(),
+		"preferred_username": username,
+		"email":              username + "@corp.test",
+	}
+	for key, value := range extra {
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+	}
+	token, err := oidcKeys.Sign(claims)
+	if err != nil {
+		t.Fatalf("Falha ao assinar token OIDC: %v", err)
+	}
+	return token
+}
+
+func oidcRequest(t *testing.T, url, token string) (*http.Response, string) {
+	return tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: url, Token: token})
+}
+
+func TestOIDCAuth(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	t.Run("Usuário local não é encontrado pelo username", func(t *testing.T) {
+		if resp, _ := oidcRequest(t, "/v1/auth/me", oidcToken(t, "user", nil)); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("linkoidc vincula o usuário local pelo sub", func(t *testing.T) {
+		cli, out := newTestCLI("")
+		if err := cli.Run([]string{"linkoidc", "user", "idp|user"}); err != nil {
+			t.Fatalf("Falha ao vincular: %v (%s)", err, out)
+		}
+		resp, body := oidcRequest(t, "/v1/auth/me", oidcToken(t, "user", nil))
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"email":"user@test.com"`) {
+			t.Errorf("Esperado 200 como o usuário local, obteve %d: %s", resp.StatusCode, body)
+		}
+		// O username do provedor pode mudar, o sub não.
+		token := oidcToken(t, "user", jwt.MapClaims{"preferred_username": "renomeado"})
+		if resp, body := oidcRequest(t, "/v1/auth/me", token); resp.StatusCode != http.StatusOK || !strings.Contains(body, `"username":"user"`) {
+			t.Errorf("Username renomeado: Esperado 200 como user, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		cli, out = newTestCLI("")
+		if err := cli.Run([]string{"linkoidc", "admin", "idp|user"}); err == nil {
+			t.Errorf("Esperado erro ao vincular o mesmo sub de novo: %s", out)
+		}
+	})
+
+	t.Run("Usuário criado no primeiro acesso", func(t *testing.T) {
+		token := oidcToken(t, "maria", jwt.MapClaims{"given_name": "Maria", "family_name": "Silva"})
+		resp, body := oidcRequest(t, "/v1/auth/me", token)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var created model.User
+		if err := testApp.DB.Where("username = ?", "maria").First(&created).Error; err != nil {
+			t.Fatalf("Usuário não criado: %v", err)
+		}
+		if created.Email != "maria@corp.test" || created.FirstName != "Maria" || created.LastName != "Silva" || !created.IsActive {
+			t.Errorf("Usuário inesperado: %+v", created)
+		}
+		if created.CheckPassword("") || created.CheckPassword(created.Password) {
+			t.Error("Esperado senha inutilizável")
+		}
+
+		oidcRequest(t, "/v1/auth/me", token)
+		var count int64
+		testApp.DB.Model(&model.User{}).Where("username = ?", "maria").Count(&count)
+		if count != 1 {
+			t.Errorf("Esperado 1 usuário, obteve %d", count)
+		}
+	})
+
+	t.Run("Criação desativada 401", func(t *testing.T) {
+		testApp.Config.OIDCCreateUsers = false
+		defer func() { testApp.Config.OIDCCreateUsers = true }()
+		if resp, _ := oidcRequest(t, "/v1/auth/me", oidcToken(t, "joao", nil)); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Email de outro usuário não é vinculado", func(t *testing.T) {
+		token := oidcToken(t, "intruso", jwt.MapClaims{"email": fixtures.AdminUser.Email})
+		if resp, _ := oidcRequest(t, "/v1/auth/me", token); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Grupos sincronizados pela claim", func(t *testing.T) {
+		token := oidcToken(t, "ana", jwt.MapClaims{"groups": []string{"Admin", "Desconhecido"}})
+		if resp, body := oidcRequest(t, "/v1/users", token); resp.StatusCode != http.StatusOK {
+			t.Fatalf("Com o grupo Admin: Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+		var ana model.User
+		testApp.DB.Preload("Groups").Where("username = ?", "ana").First(&ana)
+		if len(ana.Groups) != 1 || ana.Groups[0].Name != "Admin" {
+			t.Errorf("Esperado apenas o grupo Admin, obteve %+v", ana.Groups)
+		}
+
+		local := &model.Group{Name: "Suporte"}
+		testApp.DB.Create(local)
+		testApp.DB.Model(&ana).Association("Groups").Append(local)
+
+		token = oidcToken(t, "ana", jwt.MapClaims{"groups": []string{}})
+		if resp, _ := oidcRequest(t, "/v1/users", token); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Sem grupos: Esperado 403, obteve %d", resp.StatusCode)
+		}
+		ana.Groups = nil
+		testApp.DB.Preload("Groups").First(&ana, ana.ID)
+		if len(ana.Groups) != 1 || ana.Groups[0].Name != "Suporte" {
+			t.Errorf("Esperado apenas o grupo local Suporte, obteve %+v", ana.Groups)
+		}
+	})
+
+	t.Run("Usuário inativo 401", func(t *testing.T) {
+		testApp.DB.Model(fixtures.NormalUser).Update("is_active", false)
+		defer testApp.DB.Model(fixtures.NormalUser).Update("is_active", true)
+		if resp, _ := oidcRequest(t, "/v1/auth/me", oidcToken(t, "user", nil)); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	forged, _ := keyring.NewKey(otherKey)
+	forgedToken, _ := keyring.New(forged).Sign(jwt.MapClaims{
+		"iss": oidcIssuer, "aud": oidcAudience, "exp": time.Now().Add(time.Minute).Unix(), "preferred_username": "user",
+	})
+	hmacToken, _ := keyring.NewHMAC("segredo").Sign(jwt.MapClaims{
+		"iss": oidcIssuer, "aud": oidcAudience, "exp": time.Now().Add(time.Minute).Unix(), "preferred_username": "user",
+	})
+
+	invalid := []struct {
+		name  string
+		token string
+	}{
+		{"Audiência errada", oidcToken(t, "user", jwt.MapClaims{"aud": "outra-api"})},
+		{"Expirado", oidcToken(t, "user", jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})},
+		{"Sem exp", oidcToken(t, "user", jwt.MapClaims{"exp": nil})},
+		{"Sem sub", oidcToken(t, "user", jwt.MapClaims{"sub": nil})},
+		{"Novo usuário sem username", oidcToken(t, "novo", jwt.MapClaims{"preferred_username": nil})},
+		{"Chave desconhecida", forgedToken},
+		{"HS256", hmacToken},
+	}
+	for _, tc := range invalid {
+		t.Run("Token inválido 401 ("+tc.name+")", func(t *testing.T) {
+			resp, body := oidcRequest(t, "/v1/auth/me", tc.token)
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("Esperado 401, obteve %d: %s", resp.StatusCode, body)
+			}
+		})
+	}
+
+	t.Run("Outros emissores seguem para o JWT local", func(t *testing.T) {
+		access, _ := loginAs(t, "user", "user123")
+		if resp, _ := oidcRequest(t, "/v1/auth/me", access); resp.StatusCode != http.StatusOK {
+			t.Errorf("JWT local: Esperado 200, obteve %d", resp.StatusCode)
+		}
+		token := oidcToken(t, "user", jwt.MapClaims{"iss": "https://outro.test"})
+		if resp, _ := oidcRequest(t, "/v1/auth/me", token); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Outro emissor: Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestJWKSource(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	var keys []*keyring.Key
+	for _, private := range []interface{}{rsaKey, ecKey, edKey} {
+		key, err := keyring.NewKey(private)
+		if err != nil {
+			t.Fatalf("Falha ao criar chave: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	set, _ := json.Marshal(keyring.New(keys[0], keys[1:]...).JWKS())
+
+	t.Run("ParseJWKS reconstrói as chaves publicadas", func(t *testing.T) {
+		parsed, err := keyring.ParseJWKS(set)
+		if err != nil || len(parsed) != len(keys) {
+			t.Fatalf("Esperado %d chaves, obteve %d (%v)", len(keys), len(parsed), err)
+		}
+		for i, key := range parsed {
+			if key.ID != keys[i].ID || key.Method != keys[i].Method || key.CanSign() {
+				t.Errorf("Chave %d inesperada: %s %s", i, key.ID, key.Method.Alg())
+			}
+		}
+
+		mixed := `{"keys":[{"kty":"oct","k":"c2VncmVkbw"},{"kty":"RSA","use":"enc","n":"AQAB","e":"AQAB"}]}`
+		if _, err := keyring.ParseJWKS([]byte(mixed)); err == nil {
+			t.Error("Esperado erro para um conjunto sem chaves de assinatura")
+		}
+		badAlg := strings.Replace(string(set), `"alg":"EdDSA"`, `"alg":"RS256"`, 1)
+		if _, err := keyring.ParseJWKS([]byte(badAlg)); err == nil {
+			t.Error("Esperado erro para alg incompatível com a chave")
+		}
+	})
+
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(set)
+	}))
+	defer server.Close()
+
+	t.Run("URL carregada uma vez e mantida em cache", func(t *testing.T) {
+		source := keyring.NewJWKSource(server.URL, "", time.Minute)
+		for _, key := range keys {
+			token, err := keyring.New(key).Sign(jwt.MapClaims{"sub": "1"})
+			if err != nil {
+				t.Fatalf("Falha ao assinar: %v", err)
+			}
+			if _, err := jwt.Parse(token, source.Keyfunc); err != nil {
+				t.Errorf("Token %s: esperado válido, obteve %v", key.Method.Alg(), err)
+			}
+		}
+		// Um kid desconhecido não recarrega o conjunto logo em seguida.
+		forged, _ := keyring.NewKey(rsaKey)
+		forged.ID = "desconhecido"
+		token, _ := keyring.New(forged).Sign(jwt.MapClaims{"sub": "1"})
+		if _, err := jwt.Parse(token, source.Keyfunc); err == nil {
+			t.Error("Esperado erro para kid desconhecido")
+		}
+		if fetches.Load() != 1 {
+			t.Errorf("Esperado 1 busca do JWKS, obteve %d", fetches.Load())
+		}
+	})
+
+	t.Run("Cargas simultâneas compartilham uma busca", func(t *testing.T) {
+		var slowFetches atomic.Int32
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			slowFetches.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			w.Write(set)
+		}))
+		defer slow.Close()
+
+		source := keyring.NewJWKSource(slow.URL, "", time.Minute)
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := source.Load(); err != nil {
+					t.Errorf("Falha ao carregar: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		if slowFetches.Load() != 1 {
+			t.Errorf("Esperado 1 busca do JWKS, obteve %d", slowFetches.Load())
+		}
+	})
+
+	t.Run("URL indisponível", func(t *testing.T) {
+		source := keyring.NewJWKSource(server.URL+"/404", "", time.Minute)
+		server.Config.Handler = http.NotFoundHandler()
+		if err := source.Load(); err == nil {
+			t.Error("Esperado erro ao carregar o JWKS")
+		}
+	})
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	controllers "grf/core/controller"
+	"grf/core/filterset"
 	"grf/core/pagination"
 	"grf/core/repository"
 	"grf/core/service"
@@ -44,6 +45,7 @@ func NewDefaultPermissionController(
 		Service:       svc,
 		Validator:     validate,
 		Paginator:     paginator,
+		Ordering:      filterset.NewOrderingFilter([]string{"id", "module", "action"}, "module,action"),
 		MapToResponse: mapper.MapPermissionToResponse,
 		NewFilterSet:  func() *filter.PermissionFilterSet { return new(filter.PermissionFilterSet) },
 		NewPatchDTO:   func() *dto.PermissionPatchDTO { return new(dto.PermissionPatchDTO) },
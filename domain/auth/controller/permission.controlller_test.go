@@ -1,9 +1,16 @@
 package controller_test
 
 import (
+	"bytes"
+	"fmt"
+	"grf/core/bootstrap"
+	"grf/core/models"
+	"grf/core/permission"
 	"grf/core/tests"
 	"grf/domain/auth/dto"
+	"grf/domain/auth/model"
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -35,15 +42,117 @@ func TestPermissionCRUD(t *testing.T) {
 		}
 	})
 
-	t.Run("POST /permissions (Admin 201)", func(t *testing.T) {
+	t.Run("POST /permissions (Admin 405)", func(t *testing.T) {
 		dto := dto.PermissionCreateDTO{
 			Module: "test", Action: "create", Description: "Test perm",
 		}
 		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
 			Method: http.MethodPost, URL: "/v1/permissions", Token: adminToken, Body: dto,
 		})
-		if resp.StatusCode != http.StatusCreated {
-			t.Fatalf("Esperado 201, obteve %d: %s", resp.StatusCode, body)
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Fatalf("Esperado 405, obteve %d: %s", resp.StatusCode, body)
+		}
+		if allow := resp.Header.Get("Allow"); allow != "GET, HEAD" {
+			t.Errorf("Esperado Allow 'GET, HEAD', obteve '%s'", allow)
+		}
+	})
+
+	t.Run("DELETE /permissions/:id (Admin 405)", func(t *testing.T) {
+		perm := getPerm(testApp.DB, "user", models.ListAction)
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodDelete, URL: fmt.Sprintf("/v1/permissions/%d", perm.ID), Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			t.Errorf("Esperado 405, obteve %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestPermissionSync(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	if _, err := createTestFixtures(testApp.DB); err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	t.Run("Sync repetido não altera nada", func(t *testing.T) {
+		report, err := permission.SyncPermissions(bootstrap.PermissionOptions(testApp, false, false))
+		if err != nil {
+			t.Fatalf("Falha no sync: %v", err)
+		}
+		if !report.Empty() {
+			t.Errorf("Esperado relatório vazio, obteve %+v", report)
+		}
+	})
+
+	t.Run("Descrição alterada é atualizada", func(t *testing.T) {
+		testApp.DB.Model(&model.Permission{}).
+			Where("module = ? AND action = ?", "user", models.ListAction).
+			Update("description", "desatualizada")
+
+		report, err := permission.SyncPermissions(bootstrap.PermissionOptions(testApp, false, false))
+		if err != nil {
+			t.Fatalf("Falha no sync: %v", err)
+		}
+		if len(report.Updated) != 1 {
+			t.Fatalf("Esperado 1 atualização, obteve %d", len(report.Updated))
+		}
+		if perm := getPerm(testApp.DB, "user", models.ListAction); perm.Description == "desatualizada" {
+			t.Error("Descrição deveria ter sido restaurada")
+		}
+	})
+
+	legacy := &model.Permission{Module: "legacy", Action: "old", Description: "Permissão antiga"}
+	if err := testApp.DB.Create(legacy).Error; err != nil {
+		t.Fatalf("Falha ao criar permissão: %v", err)
+	}
+	group := &model.Group{Name: "Legado"}
+	if err := testApp.DB.Create(group).Error; err != nil {
+		t.Fatalf("Falha ao criar grupo: %v", err)
+	}
+	if err := testApp.DB.Model(group).Association("Permissions").Append(legacy); err != nil {
+		t.Fatalf("Falha ao associar permissão: %v", err)
+	}
+
+	t.Run("Dry-run lista a remoção sem aplicar", func(t *testing.T) {
+		var out bytes.Buffer
+		options := bootstrap.PermissionOptions(testApp, true, true)
+		options.Out = &out
+
+		if _, err := permission.SyncPermissions(options); err != nil {
+			t.Fatalf("Falha no sync: %v", err)
+		}
+		if !strings.Contains(out.String(), "- legacy.old") {
+			t.Errorf("Esperado legacy.old no relatório, obteve %q", out.String())
+		}
+
+		var count int64
+		testApp.DB.Model(&model.Permission{}).Where("module = ?", "legacy").Count(&count)
+		if count != 1 {
+			t.Error("Dry-run não deveria remover permissões")
+		}
+	})
+
+	t.Run("Prune remove e desassocia", func(t *testing.T) {
+		testApp.PermissionResolver.Cache.Set("sentinela", models.NewPermissionSet("legacy.old"))
+		report, err := permission.SyncPermissions(bootstrap.PermissionOptions(testApp, true, false))
+		if err != nil {
+			t.Fatalf("Falha no sync: %v", err)
+		}
+		if len(report.Pruned) == 0 {
+			t.Fatal("Esperado ao menos 1 remoção")
+		}
+
+		var count int64
+		testApp.DB.Model(&model.Permission{}).Where("module = ?", "legacy").Count(&count)
+		if count != 0 {
+			t.Error("Permissão legacy.old deveria ter sido removida")
+		}
+		testApp.DB.Table("auth_group_permissions").Where("group_id = ?", group.ID).Count(&count)
+		if count != 0 {
+			t.Error("Permissão removida deveria ser desassociada do grupo")
+		}
+		if _, ok := testApp.PermissionResolver.Cache.Get("sentinela"); ok {
+			t.Error("Sync deveria limpar o cache de permissões")
 		}
 	})
 }
@@ -0,0 +1,184 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"grf/core/middleware"
+	"grf/core/tests"
+	"grf/domain/auth/dto"
+	"grf/domain/auth/model"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// sessionLogin logs in through the session endpoint and returns the session
+// cookie value and the CSRF token.
+func sessionLogin(t *testing.T, username, password string) (sessionID, csrfToken string) {
+	t.Helper()
+	resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+		Method: http.MethodPost,
+		URL:    "/v1/auth/session/login",
+		Body:   dto.ObtainTokenDTO{Login: username, Password: password},
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Falha ao abrir sessão como %s, status %d: %s", username, resp.StatusCode, body)
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == testApp.Config.SessionCookieName {
+			sessionID = cookie.Value
+		}
+	}
+	var session dto.SessionResponseDTO
+	json.Unmarshal([]byte(body), &session)
+	return sessionID, session.CSRFToken
+}
+
+func sessionRequest(t *testing.T, method, url, sessionID, csrfToken string) (*http.Response, string) {
+	header := map[string]string{"Cookie": testApp.Config.SessionCookieName + "=" + sessionID}
+	if csrfToken != "" {
+		header[testApp.Config.CSRFHeaderName] = csrfToken
+	}
+	return tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: method, URL: url, Header: header})
+}
+
+func TestSessionAuth(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	unsafe := func(c *fiber.Ctx) error { return c.SendStatus(http.StatusNoContent) }
+	testApp.FiberApp.Post("/v1/test-session-post", middleware.Check(testApp.IsAuthenticated), unsafe)
+
+	t.Run("Login define cookies HttpOnly e CSRF", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost,
+			URL:    "/v1/auth/session/login",
+			Body:   dto.ObtainTokenDTO{Login: "user", Password: "user123"},
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+		cookies := make(map[string]*http.Cookie)
+		for _, cookie := range resp.Cookies() {
+			cookies[cookie.Name] = cookie
+		}
+		session, csrf := cookies["sessionid"], cookies["csrftoken"]
+		if session == nil || !session.HttpOnly || session.SameSite != http.SameSiteLaxMode || session.Path != "/" {
+			t.Errorf("Cookie de sessão inesperado: %+v", session)
+		}
+		if csrf == nil || csrf.HttpOnly || !strings.Contains(body, `"csrf_token":"`+csrf.Value+`"`) {
+			t.Errorf("Esperado cookie CSRF legível e igual ao corpo, obteve %+v: %s", csrf, body)
+		}
+
+		var stored model.Session
+		testApp.DB.Where("user_id = ?", fixtures.NormalUser.ID).First(&stored)
+		if stored.HashedKey == "" || stored.HashedKey == session.Value {
+			t.Error("Esperado apenas o hash da chave da sessão armazenado")
+		}
+	})
+
+	t.Run("Login com credenciais inválidas 401", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost,
+			URL:    "/v1/auth/session/login",
+			Body:   dto.ObtainTokenDTO{Login: "user", Password: "errada"},
+		})
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+		if len(resp.Cookies()) != 0 {
+			t.Errorf("Esperado nenhum cookie, obteve %v", resp.Cookies())
+		}
+	})
+
+	sessionID, csrfToken := sessionLogin(t, "user", "user123")
+
+	t.Run("Autentica com o cookie", func(t *testing.T) {
+		resp, body := sessionRequest(t, http.MethodGet, "/v1/auth/me", sessionID, "")
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"username":"user"`) {
+			t.Errorf("Esperado 200 como user sem CSRF em GET, obteve %d: %s", resp.StatusCode, body)
+		}
+		if resp, _ := sessionRequest(t, http.MethodGet, "/v1/auth/me", "inexistente", ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Cookie inválido: Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CSRF exigido em métodos inseguros", func(t *testing.T) {
+		if resp, _ := sessionRequest(t, http.MethodPost, "/v1/test-session-post", sessionID, ""); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Sem CSRF: Esperado 403, obteve %d", resp.StatusCode)
+		}
+		if resp, _ := sessionRequest(t, http.MethodPost, "/v1/test-session-post", sessionID, "errado"); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("CSRF errado: Esperado 403, obteve %d", resp.StatusCode)
+		}
+		if resp, _ := sessionRequest(t, http.MethodPost, "/v1/test-session-post", sessionID, csrfToken); resp.StatusCode != http.StatusNoContent {
+			t.Errorf("CSRF correto: Esperado 204, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("CSRF não se aplica ao JWT", func(t *testing.T) {
+		access, _ := loginAs(t, "user", "user123")
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost,
+			URL:    "/v1/test-session-post",
+			Token:  access,
+			Header: map[string]string{"Cookie": "sessionid=" + sessionID},
+		})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Esperado 204 com Bearer, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Sessão expirada 401", func(t *testing.T) {
+		expired := &model.Session{UserID: fixtures.NormalUser.ID, ExpiresAt: time.Now().Add(-time.Minute)}
+		key := expired.GenerateKey()
+		testApp.DB.Create(expired)
+
+		if resp, _ := sessionRequest(t, http.MethodGet, "/v1/auth/me", key, ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+
+		cli, out := newTestCLI("")
+		if err := cli.Run([]string{"clearsessions"}); err != nil || !strings.Contains(out.String(), "1 expired sessions deleted.") {
+			t.Errorf("clearsessions: esperado 1 sessão removida, obteve %v (%s)", err, out)
+		}
+	})
+
+	t.Run("Logout exige CSRF e encerra a sessão", func(t *testing.T) {
+		if resp, _ := sessionRequest(t, http.MethodPost, "/v1/auth/session/logout", sessionID, ""); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Sem CSRF: Esperado 403, obteve %d", resp.StatusCode)
+		}
+		resp, _ := sessionRequest(t, http.MethodPost, "/v1/auth/session/logout", sessionID, csrfToken)
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Esperado 204, obteve %d", resp.StatusCode)
+		}
+		for _, cookie := range resp.Cookies() {
+			if cookie.Value != "" || !cookie.Expires.Before(time.Now()) {
+				t.Errorf("Esperado cookie %s removido, obteve %+v", cookie.Name, cookie)
+			}
+		}
+		if resp, _ := sessionRequest(t, http.MethodGet, "/v1/auth/me", sessionID, ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Após logout: Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Troca de senha encerra as sessões", func(t *testing.T) {
+		sessionID, csrfToken := sessionLogin(t, "user", "user123")
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost,
+			URL:    "/v1/auth/change-password",
+			Body:   dto.ChangePasswordDTO{OldPassword: "user123", NewPassword: "user12345", RepeatNewPassword: "user12345"},
+			Header: map[string]string{"Cookie": "sessionid=" + sessionID, "X-CSRFToken": csrfToken},
+		})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Esperado 204, obteve %d: %s", resp.StatusCode, body)
+		}
+		if resp, _ := sessionRequest(t, http.MethodGet, "/v1/auth/me", sessionID, ""); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+}
@@ -1,23 +1,34 @@
 package controller
 
 import (
+	"grf/core/config"
 	controllers "grf/core/controller"
+	"grf/core/exceptions"
+	"grf/core/filterset"
+	"grf/core/models"
 	"grf/core/pagination"
+	"grf/core/permission"
 	"grf/core/service"
 	"grf/domain/auth/dto"
 	"grf/domain/auth/filter"
 	"grf/domain/auth/mapper"
 	"grf/domain/auth/model"
 	"grf/domain/auth/repository"
+	authservice "grf/domain/auth/service"
 	"strconv"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
+// NewDefaultUserController mounts set-password, which also revokes the
+// credentials of userRevokers.
 func NewDefaultUserController(
 	db *gorm.DB,
+	config *config.Config,
 	validate *validator.Validate,
+	userRevokers ...models.UserRevoker,
 ) *controllers.GenericController[
 	*model.User, *dto.UserCreateDTO, *dto.UserUpdateDTO, *dto.UserPatchDTO, *dto.UserResponseDTO, *filter.UserFilterSet, uint64,
 ] {
@@ -25,9 +36,12 @@ func NewDefaultUserController(
 
 	userService := service.NewGenericService(
 		&service.Config[*model.User, *dto.UserCreateDTO, *dto.UserUpdateDTO, *dto.UserPatchDTO, *dto.UserResponseDTO, *filter.UserFilterSet, uint64]{
-			Repo:             userRepo,
-			MapCreateToModel: mapper.MapCreateToUser,
+			Repo: userRepo,
+			MapCreateToModel: func(input *dto.UserCreateDTO) *model.User {
+				return mapper.MapCreateToUser(input, config.PasswordHashCost)
+			},
 			MapUpdateToModel: mapper.MapUpdateToUser,
+			Scope:            authservice.UserScope,
 		},
 	)
 
@@ -38,17 +52,88 @@ func NewDefaultUserController(
 		Service:   userService,
 		Validator: validate,
 		Paginator: userPaginator,
+		Ordering:  filterset.NewOrderingFilter([]string{"id", "username", "email", "first_name", "last_name", "last_login", "created_at"}, "id"),
+
+		Expandable: map[string]string{
+			"groups":             "Groups",
+			"groups.permissions": "Groups.Permissions",
+			"user_permissions":   "UserPermissions",
+		},
 
 		MapToResponse: mapper.MapUserToResponse,
 
 		NewFilterSet: func() *filter.UserFilterSet { return new(filter.UserFilterSet) },
-		NewPatchDTO:  func() *dto.UserPatchDTO { return new(dto.UserPatchDTO) },
+		NewSearchFilter: func() filterset.IFilterSet {
+			return filterset.NewSearchFilter("username", "email", "first_name", "last_name")
+		},
+		NewPatchDTO: func() *dto.UserPatchDTO { return new(dto.UserPatchDTO) },
 
 		ParseID: func(s string) (uint64, error) {
 			id, err := strconv.ParseUint(s, 10, 64)
 			return id, err
 		},
+
+		ExtraActions: []controllers.Action{
+			{
+				Method:      fiber.MethodPost,
+				Path:        "/set-password",
+				Detail:      true,
+				Codename:    "user.set_password",
+				Description: "Permission to set the password of auth_user records.",
+				Handler:     setPasswordHandler(config, userService, userRepo, repository.NewTokenRepository(db, userRevokers...), repository.NewSessionRepository(db), validate),
+			},
+		},
 	}
 
 	return controllers.NewGenericController(userConfig)
 }
+
+// setPasswordHandler also ends the user's sessions, as a password change does.
+// The user is looked up like on the other detail routes, scoped to the
+// request user and checked against the route's object permissions.
+func setPasswordHandler(
+	config *config.Config,
+	userService service.IService[*model.User, *dto.UserCreateDTO, *dto.UserUpdateDTO, *dto.UserPatchDTO, *dto.UserResponseDTO, *filter.UserFilterSet, uint64],
+	userRepo *repository.UserRepository,
+	tokenRepo *repository.TokenRepository,
+	sessionRepo *repository.SessionRepository,
+	validate *validator.Validate,
+) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+		if err != nil {
+			return exceptions.NewBadRequest("id_required", err)
+		}
+
+		var input dto.SetPasswordDTO
+		if err := c.BodyParser(&input); err != nil {
+			return exceptions.NewBadRequest("invalid_payload", err)
+		}
+		if err := validate.Struct(input); err != nil {
+			return err
+		}
+
+		requestUser, _ := c.Locals("user").(models.IUser)
+		user, err := userService.ForUser(requestUser).GetByID(id)
+		if err != nil {
+			return err
+		}
+		if err := permission.CheckObject(c, user); err != nil {
+			return err
+		}
+		if err := user.SetPassword(input.Password, config.PasswordHashCost); err != nil {
+			return exceptions.NewInternal(err)
+		}
+		if err := userRepo.Update(user); err != nil {
+			return exceptions.NewInternal(err)
+		}
+		if err := tokenRepo.RevokeUser(user.ID, model.TokenRevoked); err != nil {
+			return exceptions.NewInternal(err)
+		}
+		if err := sessionRepo.DeleteUser(user.ID); err != nil {
+			return exceptions.NewInternal(err)
+		}
+
+		return c.SendStatus(fiber.StatusNoContent)
+	}
+}
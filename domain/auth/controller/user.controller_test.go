@@ -1,14 +1,25 @@
 package controller_test
 
 import (
+	"errors"
 	"fmt"
+	"grf/core/middleware"
+	"grf/core/models"
 	"grf/core/pagination"
+	"grf/core/permission"
+	"grf/core/routes"
 	"grf/core/tests"
+	"grf/domain/auth/controller"
 	authdto "grf/domain/auth/dto"
+	"grf/domain/auth/model"
 	"net/http"
+	"sync/atomic"
 	"testing"
 
 	"github.com/goccy/go-json"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 func TestUserCRUD(t *testing.T) {
@@ -124,3 +135,1173 @@ func TestUserCRUD(t *testing.T) {
 		}
 	})
 }
+
+func TestUserFilters(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	_, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	adminToken, _ := loginAs(t, "admin", "admin123")
+
+	listUsers := func(t *testing.T, query string) (int, []authdto.UserResponseDTO) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users?" + query, Token: adminToken,
+		})
+		var listResp pagination.Response[authdto.UserResponseDTO]
+		if resp.StatusCode == http.StatusOK {
+			if err := json.Unmarshal([]byte(body), &listResp); err != nil {
+				t.Fatalf("Falha ao decodificar resposta: %v", err)
+			}
+		}
+		return resp.StatusCode, listResp.Results
+	}
+
+	t.Run("GET /users?username=admin (exact)", func(t *testing.T) {
+		status, results := listUsers(t, "username=admin")
+		if status != http.StatusOK || len(results) != 1 || results[0].Username != "admin" {
+			t.Errorf("Esperado somente 'admin', obteve %d %v", status, results)
+		}
+	})
+
+	t.Run("GET /users?username__in=admin,user (in)", func(t *testing.T) {
+		status, results := listUsers(t, "username__in=admin,user")
+		if status != http.StatusOK || len(results) != 2 {
+			t.Errorf("Esperado 2 usuários, obteve %d %v", status, results)
+		}
+	})
+
+	t.Run("GET /users?email__iexact=ADMIN@TEST.COM (iexact)", func(t *testing.T) {
+		status, results := listUsers(t, "email__iexact=ADMIN@TEST.COM")
+		if status != http.StatusOK || len(results) != 1 {
+			t.Errorf("Esperado 1 usuário, obteve %d %v", status, results)
+		}
+	})
+
+	t.Run("GET /users?last_login__isnull=false (isnull)", func(t *testing.T) {
+		status, results := listUsers(t, "last_login__isnull=false")
+		if status != http.StatusOK || len(results) != 0 {
+			t.Errorf("Esperado 0 usuários, obteve %d %v", status, results)
+		}
+	})
+
+	t.Run("GET /users?id__in=1&created_at__gte=2000-01-01 (in + gte)", func(t *testing.T) {
+		status, results := listUsers(t, "id__in=1&created_at__gte=2000-01-01")
+		if status != http.StatusOK || len(results) != 1 {
+			t.Errorf("Esperado 1 usuário, obteve %d %v", status, results)
+		}
+	})
+
+	t.Run("GET /users?username__icontains=ADM (icontains)", func(t *testing.T) {
+		status, results := listUsers(t, "username__icontains=ADM")
+		if status != http.StatusOK || len(results) != 1 || results[0].Username != "admin" {
+			t.Errorf("Esperado somente 'admin', obteve %d %v", status, results)
+		}
+	})
+
+	t.Run("GET /users?username__icontains=%25 (wildcard escapado)", func(t *testing.T) {
+		status, results := listUsers(t, "username__icontains=%25")
+		if status != http.StatusOK || len(results) != 0 {
+			t.Errorf("Esperado 0 usuários, obteve %d %v", status, results)
+		}
+	})
+
+	t.Run("GET /users?email__contains=TEST (contains case sensitive)", func(t *testing.T) {
+		status, results := listUsers(t, "email__contains=TEST")
+		if status != http.StatusOK || len(results) != 0 {
+			t.Errorf("Esperado 0 usuários, obteve %d %v", status, results)
+		}
+	})
+
+	t.Run("GET /users?username__regex=^us (regex)", func(t *testing.T) {
+		status, results := listUsers(t, "username__regex=%5Eus")
+		if status != http.StatusOK || len(results) != 1 || results[0].Username != "user" {
+			t.Errorf("Esperado somente 'user', obteve %d %v", status, results)
+		}
+	})
+
+	t.Run("GET /users?username__iregex=^ADMIN$ (iregex)", func(t *testing.T) {
+		status, results := listUsers(t, "username__iregex=%5EADMIN%24")
+		if status != http.StatusOK || len(results) != 1 || results[0].Username != "admin" {
+			t.Errorf("Esperado somente 'admin', obteve %d %v", status, results)
+		}
+	})
+
+	t.Run("GET /users?search=ADM (search)", func(t *testing.T) {
+		status, results := listUsers(t, "search=ADM")
+		if status != http.StatusOK || len(results) != 1 || results[0].Username != "admin" {
+			t.Errorf("Esperado somente 'admin', obteve %d %v", status, results)
+		}
+	})
+
+	t.Run("GET /users?search=test.com user (search AND entre termos)", func(t *testing.T) {
+		status, results := listUsers(t, "search=test.com%20user&username__in=admin,user")
+		if status != http.StatusOK || len(results) != 1 || results[0].Username != "user" {
+			t.Errorf("Esperado somente 'user', obteve %d %v", status, results)
+		}
+	})
+
+	t.Run("GET /users?is_active=talvez (400)", func(t *testing.T) {
+		status, _ := listUsers(t, "is_active=talvez")
+		if status != http.StatusBadRequest {
+			t.Errorf("Esperado 400, obteve %d", status)
+		}
+	})
+
+	t.Run("GET /users?created_at__range=2000-01-01 (400)", func(t *testing.T) {
+		status, _ := listUsers(t, "created_at__range=2000-01-01")
+		if status != http.StatusBadRequest {
+			t.Errorf("Esperado 400, obteve %d", status)
+		}
+	})
+}
+
+func TestUserOrdering(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	_, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	adminToken, _ := loginAs(t, "admin", "admin123")
+
+	t.Run("GET /users?ordering=-username (Admin 200)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users?ordering=-username", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var listResp pagination.Response[authdto.UserResponseDTO]
+		if err := json.Unmarshal([]byte(body), &listResp); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if len(listResp.Results) != 2 || listResp.Results[0].Username != "user" || listResp.Results[1].Username != "admin" {
+			t.Errorf("Ordenação incorreta: %v", listResp.Results)
+		}
+	})
+
+	t.Run("GET /users?ordering=username&limit=1&offset=1 (Admin 200)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users?ordering=username&limit=1&offset=1", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var listResp pagination.Response[authdto.UserResponseDTO]
+		if err := json.Unmarshal([]byte(body), &listResp); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if len(listResp.Results) != 1 || listResp.Results[0].Username != "user" || *listResp.Count != 2 {
+			t.Errorf("Paginação ordenada incorreta: %v", listResp.Results)
+		}
+	})
+
+	t.Run("GET /users?ordering=password (Admin 400)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users?ordering=password", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Esperado 400, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+}
+
+func TestUserSparseFieldsets(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	adminToken, _ := loginAs(t, "admin", "admin123")
+
+	t.Run("GET /users?fields=id,username (Admin 200)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users?fields=id,username&ordering=id", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var listResp pagination.Response[map[string]interface{}]
+		if err := json.Unmarshal([]byte(body), &listResp); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if len(listResp.Results) != 2 {
+			t.Fatalf("Esperado 2 usuários, obteve %d", len(listResp.Results))
+		}
+		first := listResp.Results[0]
+		if len(first) != 2 || first["username"] != "admin" || first["id"] == nil {
+			t.Errorf("Esperado somente id e username, obteve %v", first)
+		}
+	})
+
+	t.Run("GET /users/:id?omit=email,created_at (Admin 200)", func(t *testing.T) {
+		url := fmt.Sprintf("/v1/users/%d?omit=email,created_at", fixtures.NormalUser.ID)
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: url, Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var user map[string]interface{}
+		if err := json.Unmarshal([]byte(body), &user); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if _, ok := user["email"]; ok {
+			t.Errorf("Campo 'email' deveria ter sido omitido: %v", user)
+		}
+		if _, ok := user["created_at"]; ok {
+			t.Errorf("Campo 'created_at' deveria ter sido omitido: %v", user)
+		}
+		if user["username"] != "user" {
+			t.Errorf("Esperado 'user', obteve %v", user["username"])
+		}
+	})
+
+	t.Run("PATCH /users/:id?fields=first_name (Admin 200)", func(t *testing.T) {
+		url := fmt.Sprintf("/v1/users/%d?fields=first_name", fixtures.NormalUser.ID)
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPatch, URL: url, Token: adminToken, Body: map[string]string{"first_name": "Sparse"},
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+		if body != `{"first_name":"Sparse"}` {
+			t.Errorf("Resposta inesperada: %s", body)
+		}
+	})
+
+	t.Run("GET /users?fields=password (Admin 400)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users?fields=password", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Esperado 400, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+}
+
+func TestUserExpand(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	var adminGroup model.Group
+	testApp.DB.Where("name = ?", "Admin").First(&adminGroup)
+	if err := testApp.DB.Model(fixtures.NormalUser).Association("Groups").Append(&adminGroup); err != nil {
+		t.Fatalf("Falha ao associar grupo: %v", err)
+	}
+
+	adminToken, _ := loginAs(t, "admin", "admin123")
+
+	t.Run("GET /users/:id?expand=groups.permissions (Admin 200)", func(t *testing.T) {
+		url := fmt.Sprintf("/v1/users/%d?expand=groups.permissions", fixtures.NormalUser.ID)
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: url, Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var respDTO authdto.UserResponseDTO
+		if err := json.Unmarshal([]byte(body), &respDTO); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if len(respDTO.Groups) != 1 || respDTO.Groups[0].Name != "Admin" {
+			t.Fatalf("Esperado grupo 'Admin' expandido, obteve %v", respDTO.Groups)
+		}
+		if len(respDTO.Groups[0].Permissions) != 14 {
+			t.Errorf("Esperado 14 permissões no grupo, obteve %d", len(respDTO.Groups[0].Permissions))
+		}
+	})
+
+	t.Run("GET /users?expand=groups (Admin 200)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users?expand=groups&ordering=id", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var listResp pagination.Response[authdto.UserResponseDTO]
+		if err := json.Unmarshal([]byte(body), &listResp); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if *listResp.Count != 2 || len(listResp.Results[0].Groups) != 0 || len(listResp.Results[1].Groups) != 1 {
+			t.Errorf("Expansão incorreta na listagem: %v", listResp.Results)
+		}
+		if len(listResp.Results[1].Groups[0].Permissions) != 0 {
+			t.Errorf("Permissões do grupo não deveriam ser expandidas")
+		}
+	})
+
+	t.Run("GET /users/:id sem expand (Admin 200)", func(t *testing.T) {
+		url := fmt.Sprintf("/v1/users/%d", fixtures.NormalUser.ID)
+		_, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: url, Token: adminToken,
+		})
+
+		var user map[string]interface{}
+		if err := json.Unmarshal([]byte(body), &user); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if _, ok := user["groups"]; ok {
+			t.Errorf("Grupos não deveriam ser retornados sem expand: %v", user)
+		}
+	})
+
+	t.Run("GET /users?expand=password (Admin 400)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users?expand=password", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Esperado 400, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("GET /users?expand=groups.permissions.groups (Admin 400)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users?expand=groups.permissions.groups", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Esperado 400, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+}
+
+func TestUserBulk(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	_, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	adminToken, _ := loginAs(t, "admin", "admin123")
+	userToken, _ := loginAs(t, "user", "user123")
+
+	var createdIDs []uint64
+
+	t.Run("POST /users [bulk] (Admin 201)", func(t *testing.T) {
+		dtos := []authdto.UserCreateDTO{
+			{Username: "bulk1", Email: "bulk1@test.com", Password: "password123"},
+			{Username: "bulk2", Email: "bulk2@test.com", Password: "password123"},
+		}
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: "/v1/users", Token: adminToken, Body: dtos,
+		})
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("Esperado 201, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var respDTOs []authdto.UserResponseDTO
+		if err := json.Unmarshal([]byte(body), &respDTOs); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if len(respDTOs) != 2 || respDTOs[0].Username != "bulk1" || respDTOs[1].Username != "bulk2" {
+			t.Fatalf("Criação em lote incorreta: %v", respDTOs)
+		}
+		createdIDs = []uint64{respDTOs[0].ID, respDTOs[1].ID}
+	})
+
+	t.Run("POST /users [bulk] (Admin 422 por índice)", func(t *testing.T) {
+		dtos := []authdto.UserCreateDTO{
+			{Username: "bulk3", Email: "bulk3@test.com", Password: "password123"},
+			{Username: "bulk4", Email: "invalido", Password: "password123"},
+		}
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: "/v1/users", Token: adminToken, Body: dtos,
+		})
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Fatalf("Esperado 422, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var errResp struct {
+			Items []struct {
+				Index  int               `json:"index"`
+				Fields map[string]string `json:"fields"`
+			} `json:"items"`
+		}
+		if err := json.Unmarshal([]byte(body), &errResp); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if len(errResp.Items) != 1 || errResp.Items[0].Index != 1 || errResp.Items[0].Fields["email"] == "" {
+			t.Errorf("Erro por índice incorreto: %s", body)
+		}
+
+		var count int64
+		testApp.DB.Table("auth_user").Where("username = ?", "bulk3").Count(&count)
+		if count != 0 {
+			t.Errorf("Nenhum usuário deveria ter sido criado")
+		}
+	})
+
+	t.Run("POST /users [bulk] (Admin 500 rollback)", func(t *testing.T) {
+		dtos := []authdto.UserCreateDTO{
+			{Username: "bulk5", Email: "bulk5@test.com", Password: "password123"},
+			{Username: "bulk1", Email: "dup@test.com", Password: "password123"},
+		}
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: "/v1/users", Token: adminToken, Body: dtos,
+		})
+		if resp.StatusCode < http.StatusBadRequest {
+			t.Fatalf("Esperado erro, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var count int64
+		testApp.DB.Table("auth_user").Where("username = ?", "bulk5").Count(&count)
+		if count != 0 {
+			t.Errorf("A transação deveria ter sido desfeita")
+		}
+	})
+
+	t.Run("PATCH /users [bulk] (Admin 200)", func(t *testing.T) {
+		patches := []map[string]interface{}{
+			{"id": createdIDs[0], "first_name": "Primeiro"},
+			{"id": createdIDs[1], "first_name": "Segundo"},
+		}
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPatch, URL: "/v1/users", Token: adminToken, Body: patches,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var respDTOs []authdto.UserResponseDTO
+		if err := json.Unmarshal([]byte(body), &respDTOs); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if len(respDTOs) != 2 || respDTOs[0].FirstName != "Primeiro" || respDTOs[1].FirstName != "Segundo" {
+			t.Errorf("Atualização em lote incorreta: %v", respDTOs)
+		}
+	})
+
+	t.Run("PATCH /users [bulk] (Admin 404 por índice)", func(t *testing.T) {
+		patches := []map[string]interface{}{
+			{"id": createdIDs[0], "first_name": "Nunca"},
+			{"id": 999999, "first_name": "Inexistente"},
+		}
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPatch, URL: "/v1/users", Token: adminToken, Body: patches,
+		})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Esperado 404, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("DELETE /users?id__in= [bulk] (User 403)", func(t *testing.T) {
+		url := fmt.Sprintf("/v1/users?id__in=%d,%d", createdIDs[0], createdIDs[1])
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodDelete, URL: url, Token: userToken,
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("DELETE /users?id__in= [bulk] (Admin 204)", func(t *testing.T) {
+		url := fmt.Sprintf("/v1/users?id__in=%d,%d", createdIDs[0], createdIDs[1])
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodDelete, URL: url, Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Esperado 204, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		var count int64
+		testApp.DB.Table("auth_user").Where("id IN ? AND deleted_at IS NULL", createdIDs).Count(&count)
+		if count != 0 {
+			t.Errorf("Esperado 0 usuários restantes, obteve %d", count)
+		}
+	})
+}
+
+func TestUserActions(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	adminToken, _ := loginAs(t, "admin", "admin123")
+	userToken, _ := loginAs(t, "user", "user123")
+	url := fmt.Sprintf("/v1/users/%d/set-password", fixtures.AdminUser.ID)
+
+	t.Run("Permissão user.set_password registrada", func(t *testing.T) {
+		perm := getPerm(testApp.DB, "user", "set_password")
+		if perm.Description == "" {
+			t.Error("Esperado descrição na permissão user.set_password")
+		}
+	})
+
+	t.Run("POST /users/:id/set-password (User 403)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: url, Token: userToken,
+			Body: authdto.SetPasswordDTO{Password: "newpass123"},
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("POST /users/:id/set-password (User com permissão, outro usuário 404)", func(t *testing.T) {
+		perm := getPerm(testApp.DB, "user", "set_password")
+		if err := testApp.DB.Model(fixtures.NormalUser).Association("UserPermissions").Append(perm); err != nil {
+			t.Fatalf("Falha ao conceder permissão: %v", err)
+		}
+
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: url, Token: userToken,
+			Body: authdto.SetPasswordDTO{Password: "newpass123"},
+		})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("Esperado 404, obteve %d: %s", resp.StatusCode, body)
+		}
+		loginAs(t, "admin", "admin123")
+	})
+
+	t.Run("POST /users/:id/set-password (User com permissão, próprio 204)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: fmt.Sprintf("/v1/users/%d/set-password", fixtures.NormalUser.ID), Token: userToken,
+			Body: authdto.SetPasswordDTO{Password: "newpass123"},
+		})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Esperado 204, obteve %d: %s", resp.StatusCode, body)
+		}
+		loginAs(t, "user", "newpass123")
+	})
+
+	t.Run("POST /users/:id/set-password (Admin 204)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: url, Token: adminToken,
+			Body: authdto.SetPasswordDTO{Password: "newpass123"},
+		})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("Esperado 204, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		resp, _ = tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/auth/me", Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado tokens do admin revogados, obteve %d", resp.StatusCode)
+		}
+		adminToken, _ = loginAs(t, "admin", "newpass123")
+	})
+
+	t.Run("POST /others/:id/set-password (Permissão por objeto 403)", func(t *testing.T) {
+		routes.RegisterModelController(&routes.RegisterModelOptions{
+			App:        testApp,
+			Router:     testApp.FiberApp.Group("/v1"),
+			Path:       "/others",
+			Model:      new(model.User),
+			Controller: controller.NewDefaultUserController(testApp.DB, testApp.Config, testApp.Validator),
+			Permission: permission.NewAnd(testApp.IsAuthenticated, testApp.IsAdmin),
+			ActionPermissions: map[string]permission.IPermission{
+				"set_password": permission.NewAnd(
+					testApp.IsAuthenticated,
+					testApp.IsAdmin,
+					permission.NewNot(permission.NewIsOwner("ID")),
+				),
+			},
+		})
+
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: fmt.Sprintf("/v1/others/%d/set-password", fixtures.AdminUser.ID), Token: adminToken,
+			Body: authdto.SetPasswordDTO{Password: "otherpass123"},
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d: %s", resp.StatusCode, body)
+		}
+		loginAs(t, "admin", "newpass123")
+	})
+
+	t.Run("POST /users/:id/set-password (Senha curta 422)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: url, Token: adminToken,
+			Body: authdto.SetPasswordDTO{Password: "curta"},
+		})
+		if resp.StatusCode != http.StatusUnprocessableEntity {
+			t.Errorf("Esperado 422, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("POST /users/:id/set-password (Admin 404)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: "/v1/users/999999/set-password", Token: adminToken,
+			Body: authdto.SetPasswordDTO{Password: "newpass123"},
+		})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Esperado 404, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+}
+
+func TestUserCustomPermissions(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	testApp.FiberApp.Get("/v1/users-export", middleware.Check(testApp.HasPermission("user.export")), func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusNoContent)
+	})
+
+	userToken, _ := loginAs(t, "user", "user123")
+	export := func() int {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users-export", Token: userToken,
+		})
+		return resp.StatusCode
+	}
+
+	t.Run("Permissões customizadas registradas", func(t *testing.T) {
+		for _, codename := range [][2]string{{"user", "export"}, {"user", "impersonate"}, {"group", "assign_members"}} {
+			perm := getPerm(testApp.DB, codename[0], codename[1])
+			if perm.Description == "" {
+				t.Errorf("Esperado descrição na permissão %s.%s", codename[0], codename[1])
+			}
+		}
+	})
+
+	t.Run("GET /users-export (Sem token 401)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users-export",
+		})
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GET /users-export (User 403)", func(t *testing.T) {
+		if status := export(); status != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", status)
+		}
+	})
+
+	t.Run("GET /users-export (User com user.export 204)", func(t *testing.T) {
+		perm := getPerm(testApp.DB, "user", "export")
+		if err := testApp.DB.Model(fixtures.NormalUser).Association("UserPermissions").Append(perm); err != nil {
+			t.Fatalf("Falha ao conceder permissão: %v", err)
+		}
+		if status := export(); status != http.StatusNoContent {
+			t.Errorf("Esperado 204, obteve %d", status)
+		}
+	})
+}
+
+func TestUserObjectPermissions(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	routes.RegisterModelController(&routes.RegisterModelOptions{
+		App:        testApp,
+		Router:     testApp.FiberApp.Group("/v1"),
+		Path:       "/profiles",
+		Model:      new(model.User),
+		Controller: controller.NewDefaultUserController(testApp.DB, testApp.Config, testApp.Validator),
+		Permission: permission.NewAnd(
+			testApp.IsAuthenticated,
+			permission.NewOr(testApp.IsAdmin, permission.NewIsOwner("ID")),
+		),
+	})
+
+	adminToken, _ := loginAs(t, "admin", "admin123")
+	userToken, _ := loginAs(t, "user", "user123")
+	ownURL := fmt.Sprintf("/v1/profiles/%d", fixtures.NormalUser.ID)
+	otherURL := fmt.Sprintf("/v1/profiles/%d", fixtures.AdminUser.ID)
+
+	t.Run("GET /profiles/:id (Próprio perfil 200)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: ownURL, Token: userToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("GET /profiles/:id (Fora do escopo 404)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: otherURL, Token: userToken,
+		})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Esperado 404, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("PATCH /profiles/:id (Próprio perfil 200)", func(t *testing.T) {
+		firstName := "Próprio"
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPatch, URL: ownURL, Token: userToken,
+			Body: authdto.UserPatchDTO{FirstName: &firstName},
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("PATCH /profiles/:id (Fora do escopo 404)", func(t *testing.T) {
+		firstName := "Invasor"
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPatch, URL: otherURL, Token: userToken,
+			Body: authdto.UserPatchDTO{FirstName: &firstName},
+		})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Esperado 404, obteve %d", resp.StatusCode)
+		}
+
+		var admin model.User
+		testApp.DB.First(&admin, fixtures.AdminUser.ID)
+		if admin.FirstName == firstName {
+			t.Error("Perfil de outro usuário não deveria ser alterado")
+		}
+	})
+
+	t.Run("DELETE /profiles/:id (Fora do escopo 404)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodDelete, URL: otherURL, Token: userToken,
+		})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Esperado 404, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GET /profiles/:id (Admin 200)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: ownURL, Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("DELETE /protected-profiles/:id (Admin próprio perfil 403)", func(t *testing.T) {
+		routes.RegisterModelController(&routes.RegisterModelOptions{
+			App:        testApp,
+			Router:     testApp.FiberApp.Group("/v1"),
+			Path:       "/protected-profiles",
+			Model:      new(model.User),
+			Controller: controller.NewDefaultUserController(testApp.DB, testApp.Config, testApp.Validator),
+			Permission: permission.NewAnd(testApp.IsAuthenticated, testApp.IsAdmin),
+			ActionPermissions: map[string]permission.IPermission{
+				models.DeleteAction: permission.NewAnd(
+					testApp.IsAuthenticated,
+					testApp.IsAdmin,
+					permission.NewNot(permission.NewIsOwner("ID")),
+				),
+			},
+		})
+
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodDelete, URL: fmt.Sprintf("/v1/protected-profiles/%d", fixtures.AdminUser.ID), Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("DELETE /protected-profiles/:id (Admin outro perfil 204)", func(t *testing.T) {
+		other := model.User{Username: "descartavel", Email: "descartavel@test.com", IsActive: true}
+		if err := testApp.DB.Create(&other).Error; err != nil {
+			t.Fatalf("Falha ao criar usuário: %v", err)
+		}
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodDelete, URL: fmt.Sprintf("/v1/protected-profiles/%d", other.ID), Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Esperado 204, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("GET /non-admin-profiles/:id (Not sem verificação por objeto)", func(t *testing.T) {
+		routes.RegisterModelController(&routes.RegisterModelOptions{
+			App:        testApp,
+			Router:     testApp.FiberApp.Group("/v1"),
+			Path:       "/non-admin-profiles",
+			Model:      new(model.User),
+			Controller: controller.NewDefaultUserController(testApp.DB, testApp.Config, testApp.Validator),
+			Permission: permission.NewAnd(testApp.IsAuthenticated, permission.NewNot(testApp.IsAdmin)),
+			Bulk:       true,
+		})
+
+		url := fmt.Sprintf("/v1/non-admin-profiles/%d", fixtures.NormalUser.ID)
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: url, Token: userToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("User: Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+		resp, _ = tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: url, Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Admin: Esperado 403, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Rotas em massa com permissão por objeto são recusadas", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Esperado panic ao registrar rotas em massa com IsOwner")
+			}
+		}()
+		routes.RegisterModelController(&routes.RegisterModelOptions{
+			App:        testApp,
+			Router:     testApp.FiberApp.Group("/v1"),
+			Path:       "/bulk-profiles",
+			Model:      new(model.User),
+			Controller: controller.NewDefaultUserController(testApp.DB, testApp.Config, testApp.Validator),
+			Permission: permission.NewAnd(testApp.IsAuthenticated, permission.NewIsOwner("ID")),
+			Bulk:       true,
+		})
+	})
+
+	t.Run("DELETE /profiles/:id (Inexistente 404)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodDelete, URL: "/v1/profiles/999999", Token: userToken,
+		})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Esperado 404, obteve %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestUserScope(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	perms := []*model.Permission{
+		getPerm(testApp.DB, "user", models.ListAction),
+		getPerm(testApp.DB, "user", models.DetailAction),
+		getPerm(testApp.DB, "user", models.DeleteAction),
+	}
+	if err := testApp.DB.Model(fixtures.NormalUser).Association("UserPermissions").Append(perms); err != nil {
+		t.Fatalf("Falha ao conceder permissões: %v", err)
+	}
+
+	adminToken, _ := loginAs(t, "admin", "admin123")
+	userToken, _ := loginAs(t, "user", "user123")
+
+	t.Run("GET /users (User vê apenas a si mesmo)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users", Token: userToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+		var page pagination.Response[authdto.UserResponseDTO]
+		if err := json.Unmarshal([]byte(body), &page); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if page.Count == nil || *page.Count != 1 || len(page.Results) != 1 || page.Results[0].ID != fixtures.NormalUser.ID {
+			t.Errorf("Esperado apenas o próprio usuário, obteve %s", body)
+		}
+	})
+
+	t.Run("GET /users (Admin vê todos)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users", Token: adminToken,
+		})
+		var page pagination.Response[authdto.UserResponseDTO]
+		if err := json.Unmarshal([]byte(body), &page); err != nil {
+			t.Fatalf("Falha ao decodificar resposta: %v", err)
+		}
+		if resp.StatusCode != http.StatusOK || page.Count == nil || *page.Count != 2 {
+			t.Errorf("Esperado 2 usuários, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("GET /users/:id (Fora do escopo 404)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: fmt.Sprintf("/v1/users/%d", fixtures.AdminUser.ID), Token: userToken,
+		})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Esperado 404, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("DELETE /users/:id (Fora do escopo 404)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodDelete, URL: fmt.Sprintf("/v1/users/%d", fixtures.AdminUser.ID), Token: userToken,
+		})
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Esperado 404, obteve %d", resp.StatusCode)
+		}
+
+		var count int64
+		testApp.DB.Model(&model.User{}).Where("id = ?", fixtures.AdminUser.ID).Count(&count)
+		if count != 1 {
+			t.Error("Usuário fora do escopo não deveria ser removido")
+		}
+	})
+}
+
+func TestUserPermissionCache(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+
+	adminToken, _ := loginAs(t, "admin", "admin123")
+	userToken, _ := loginAs(t, "user", "user123")
+	listUsers := func() int {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/users", Token: userToken,
+		})
+		return resp.StatusCode
+	}
+
+	readers := &model.Group{Name: "Leitores"}
+	if err := testApp.DB.Create(readers).Error; err != nil {
+		t.Fatalf("Falha ao criar grupo: %v", err)
+	}
+	if err := testApp.DB.Model(readers).Association("Permissions").Append(fixtures.PermListUser); err != nil {
+		t.Fatalf("Falha ao associar permissão: %v", err)
+	}
+
+	t.Run("GET /users (Sem grupo 403)", func(t *testing.T) {
+		if status := listUsers(); status != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", status)
+		}
+	})
+
+	t.Run("GET /users (Adicionado ao grupo 200)", func(t *testing.T) {
+		if err := testApp.DB.Model(fixtures.NormalUser).Association("Groups").Append(readers); err != nil {
+			t.Fatalf("Falha ao adicionar usuário ao grupo: %v", err)
+		}
+		if status := listUsers(); status != http.StatusOK {
+			t.Errorf("Esperado 200, obteve %d", status)
+		}
+	})
+
+	t.Run("GET /users (Permissões do grupo removidas 403)", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPatch, URL: fmt.Sprintf("/v1/groups/%d", readers.ID), Token: adminToken,
+			Body: map[string]interface{}{"permission_ids": []uint64{}},
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+		if status := listUsers(); status != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", status)
+		}
+	})
+
+	key := fixtures.NormalUser.PermissionCacheKey()
+	cached := func() bool {
+		_, ok := testApp.PermissionResolver.Cache.Get(key)
+		return ok
+	}
+	grant := func(commit bool) {
+		err := testApp.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(readers).Association("Permissions").Append(fixtures.PermListUser); err != nil {
+				return err
+			}
+			if !cached() {
+				t.Error("Cache não deveria ser limpo antes do commit")
+			}
+			if !commit {
+				return errors.New("desfeita")
+			}
+			return nil
+		})
+		if commit && err != nil {
+			t.Fatalf("Falha ao conceder permissão: %v", err)
+		}
+	}
+
+	t.Run("GET /users (Transação desfeita mantém o cache 403)", func(t *testing.T) {
+		listUsers()
+		grant(false)
+		if !cached() {
+			t.Error("Transação desfeita não deveria limpar o cache")
+		}
+		if status := listUsers(); status != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", status)
+		}
+	})
+
+	t.Run("GET /users (Cache limpo após o commit 200)", func(t *testing.T) {
+		grant(true)
+		if cached() {
+			t.Error("Cache deveria ser limpo após o commit")
+		}
+		if status := listUsers(); status != http.StatusOK {
+			t.Errorf("Esperado 200, obteve %d", status)
+		}
+	})
+}
+
+func TestUserPermissionCacheOpenedDB(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+	var admins model.Group
+	testApp.DB.Where("name = ?", "Admin").First(&admins)
+
+	// Conexão aberta sem ConnectDB, como a de uma aplicação que embute o grf.
+	db, err := gorm.Open(sqlite.Open("file:memdb1?mode=memory&cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Falha ao abrir o banco: %v", err)
+	}
+	resolver := permission.NewResolver(db, nil)
+	key := fixtures.NormalUser.PermissionCacheKey()
+	resolver.Cache.Set(key, models.NewPermissionSet())
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&admins).Association("Permissions").Delete(fixtures.PermListUser); err != nil {
+			return err
+		}
+		return errors.New("desfeita")
+	})
+	if err == nil || err.Error() != "desfeita" {
+		t.Fatalf("Esperado a transação desfeita, obteve %v", err)
+	}
+	if _, ok := resolver.Cache.Get(key); !ok {
+		t.Error("Transação desfeita não deveria limpar o cache")
+	}
+
+	if err := db.Model(&admins).Association("Permissions").Delete(fixtures.PermListUser); err != nil {
+		t.Fatalf("Falha ao remover permissão: %v", err)
+	}
+	if _, ok := resolver.Cache.Get(key); ok {
+		t.Error("Cache deveria ser limpo após o commit")
+	}
+}
+
+func BenchmarkUserList(b *testing.B) {
+	clearAuthTables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		b.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+	if err := testApp.DB.Model(fixtures.NormalUser).Association("UserPermissions").Append(fixtures.PermListUser); err != nil {
+		b.Fatalf("Falha ao conceder permissão: %v", err)
+	}
+
+	routes.RegisterModelController(&routes.RegisterModelOptions{
+		App:        testApp,
+		Router:     testApp.FiberApp.Group("/v1"),
+		Path:       "/uncached-users",
+		Model:      new(model.User),
+		Controller: controller.NewDefaultUserController(testApp.DB, testApp.Config, testApp.Validator),
+		Permission: permission.NewAnd(
+			testApp.IsAuthenticated,
+			permission.NewModelPermissions(testApp.DB, new(model.User)),
+		),
+	})
+
+	var queries int64
+	queryCallbacks := testApp.DB.Callback().Query()
+	if queryCallbacks.Get("bench:count_queries") == nil {
+		_ = queryCallbacks.After("gorm:query").Register("bench:count_queries", func(*gorm.DB) {
+			atomic.AddInt64(&queries, 1)
+		})
+	}
+	defer func() { _ = queryCallbacks.Remove("bench:count_queries") }()
+
+	userToken, _ := loginAs(b, "user", "user123")
+
+	for _, bench := range []struct{ name, url string }{
+		{"sem_cache", "/v1/uncached-users"},
+		{"com_cache", "/v1/users"},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			atomic.StoreInt64(&queries, 0)
+			for i := 0; i < b.N; i++ {
+				resp, body := tests.MakeRequest(b, testApp.FiberApp, tests.RequestOptions{
+					Method: http.MethodGet, URL: bench.url, Token: userToken,
+				})
+				if resp.StatusCode != http.StatusOK {
+					b.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+				}
+			}
+			b.ReportMetric(float64(atomic.LoadInt64(&queries))/float64(b.N), "queries/op")
+		})
+	}
+}
+
+func TestUserCursorPagination(t *testing.T) {
+	clearAuthTables(testApp.DB)
+	if _, err := createTestFixtures(testApp.DB); err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		user := model.User{Username: fmt.Sprintf("repetido%d", i), Email: fmt.Sprintf("repetido%d@test.com", i), FirstName: "Igual", IsActive: true}
+		if err := testApp.DB.Create(&user).Error; err != nil {
+			t.Fatalf("Falha ao criar usuário: %v", err)
+		}
+	}
+
+	cursorController := controller.NewDefaultUserController(testApp.DB, testApp.Config, testApp.Validator)
+	cursorController.Paginator = pagination.NewCursorPagination[*model.User](2, 10, "id", "ASC")
+	routes.RegisterModelController(&routes.RegisterModelOptions{
+		App:        testApp,
+		Router:     testApp.FiberApp.Group("/v1"),
+		Path:       "/cursor-users",
+		Model:      new(model.User),
+		Controller: cursorController,
+	})
+	adminToken, _ := loginAs(t, "admin", "admin123")
+
+	queries := []string{
+		"ordering=first_name",
+		"ordering=-first_name,username",
+		"ordering=id",
+		"fields=id,username&ordering=-first_name",
+		"fields=id,username&ordering=-created_at",
+	}
+	for _, query := range queries {
+		t.Run("GET /cursor-users?"+query+" percorre cada usuário uma vez", func(t *testing.T) {
+			seen := make(map[uint64]int)
+			url := "/v1/cursor-users?" + query
+			for pages := 0; pages < 10; pages++ {
+				resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: url, Token: adminToken})
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+				}
+				var page pagination.Response[authdto.UserResponseDTO]
+				json.Unmarshal([]byte(body), &page)
+				for _, user := range page.Results {
+					seen[user.ID]++
+				}
+				if !page.HasNext {
+					break
+				}
+				url = "/v1/cursor-users?" + query + "&cursor=" + page.NextCursor
+			}
+			if len(seen) != 7 {
+				t.Errorf("Esperado 7 usuários distintos, obteve %d: %v", len(seen), seen)
+			}
+			for id, count := range seen {
+				if count != 1 {
+					t.Errorf("Usuário %d repetido %d vezes", id, count)
+				}
+			}
+		})
+	}
+
+	t.Run("GET /cursor-users?cursor=inválido 400", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: "/v1/cursor-users?cursor=xyz", Token: adminToken})
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Esperado 400, obteve %d", resp.StatusCode)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"grf/core/dto"
+	"time"
+)
+
+var _ dto.IPatchDTO = (*APIKeyPatchDTO)(nil)
+
+type APIKeyCreateDTO struct {
+	Name      string     `json:"name" validate:"required,max=100"`
+	UserID    uint64     `json:"user_id" validate:"required"`
+	Scopes    []string   `json:"scopes" validate:"max=50,dive,required,max=100,excludesall= "`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyUpdateDTO and APIKeyPatchDTO only rename a key; its secret, owner
+// and scopes are fixed once issued.
+type APIKeyUpdateDTO struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type APIKeyPatchDTO struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,max=100"`
+}
+
+func (dto *APIKeyPatchDTO) IsEmpty() bool {
+	return dto.Name == nil
+}
+
+func (dto *APIKeyPatchDTO) ToPatchMap() map[string]interface{} {
+	updates := make(map[string]interface{})
+	if dto.Name != nil {
+		updates["name"] = *dto.Name
+	}
+	return updates
+}
+
+type APIKeyResponseDTO struct {
+	ID         uint64     `json:"id"`
+	Name       string     `json:"name"`
+	UserID     uint64     `json:"user_id"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	// Key is only returned by the request that created the key.
+	Key string `json:"key,omitempty"`
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 type ObtainTokenDTO struct {
 	Login    string `json:"login" validate:"required"`
 	Password string `json:"password" validate:"required"`
@@ -17,3 +19,14 @@ type ChangePasswordDTO struct {
 	NewPassword       string `json:"new_password" validate:"required,min=8"`
 	RepeatNewPassword string `json:"repeat_new_password" validate:"required,min=8"`
 }
+type SetPasswordDTO struct {
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// SessionResponseDTO answers a session login. The CSRF token is also set as
+// a cookie readable by the front-end.
+type SessionResponseDTO struct {
+	User      *UserResponseDTO `json:"user"`
+	CSRFToken string           `json:"csrf_token"`
+	ExpiresAt time.Time        `json:"expires_at"`
+}
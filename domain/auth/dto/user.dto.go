@@ -76,4 +76,7 @@ type UserResponseDTO struct {
 	IsSuperuser bool       `json:"is_superuser"`
 	LastLogin   *time.Time `json:"last_login,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
+
+	Groups          []GroupResponseDTO      `json:"groups,omitempty"`
+	UserPermissions []PermissionResponseDTO `json:"user_permissions,omitempty"`
 }
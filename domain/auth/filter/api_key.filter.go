@@ -0,0 +1,19 @@
+package filter
+
+import (
+	"grf/core/filterset"
+	"time"
+)
+
+var _ filterset.IFilterSet = (*APIKeyFilterSet)(nil)
+
+type APIKeyFilterFields struct {
+	ID        uint64    `filter:"id,lookups=exact|in"`
+	UserID    uint64    `filter:"user_id,lookups=exact|in"`
+	Name      string    `filter:"name,lookups=exact|icontains"`
+	Prefix    string    `filter:"prefix"`
+	ExpiresAt time.Time `filter:"expires_at,lookups=gte|lte|isnull"`
+	RevokedAt time.Time `filter:"revoked_at,lookups=isnull"`
+}
+
+type APIKeyFilterSet = filterset.FilterSet[APIKeyFilterFields]
@@ -0,0 +1,37 @@
+package mapper
+
+import (
+	"grf/domain/auth/dto"
+	"grf/domain/auth/model"
+)
+
+func MapAPIKeyToResponse(key *model.APIKey) *dto.APIKeyResponseDTO {
+	return &dto.APIKeyResponseDTO{
+		ID:         key.ID,
+		Name:       key.Name,
+		UserID:     key.UserID,
+		Prefix:     key.Prefix,
+		Scopes:     key.ScopeList(),
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		Key:        key.Key,
+	}
+}
+
+func MapCreateToAPIKey(dto *dto.APIKeyCreateDTO) *model.APIKey {
+	key := &model.APIKey{
+		Name:      dto.Name,
+		UserID:    dto.UserID,
+		ExpiresAt: dto.ExpiresAt,
+	}
+	key.SetScopes(dto.Scopes)
+	key.GenerateKey()
+	return key
+}
+
+func MapUpdateToAPIKey(dto *dto.APIKeyUpdateDTO, key *model.APIKey) *model.APIKey {
+	key.Name = dto.Name
+	return key
+}
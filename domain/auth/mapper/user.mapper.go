@@ -7,7 +7,7 @@ import (
 )
 
 func MapUserToResponse(user *model.User) *dto.UserResponseDTO {
-	return &dto.UserResponseDTO{
+	resp := dto.UserResponseDTO{
 		ID:          user.ID,
 		Username:    user.Username,
 		Email:       user.Email,
@@ -19,9 +19,25 @@ func MapUserToResponse(user *model.User) *dto.UserResponseDTO {
 		LastLogin:   user.LastLogin,
 		CreatedAt:   user.CreatedAt,
 	}
+
+	if user.Groups != nil {
+		resp.Groups = make([]dto.GroupResponseDTO, len(user.Groups))
+		for i, group := range user.Groups {
+			resp.Groups[i] = *MapGroupToResponse(group)
+		}
+	}
+	if user.UserPermissions != nil {
+		resp.UserPermissions = make([]dto.PermissionResponseDTO, len(user.UserPermissions))
+		for i, perm := range user.UserPermissions {
+			resp.UserPermissions[i] = *MapPermissionToResponse(perm)
+		}
+	}
+
+	return &resp
 }
 
-func MapCreateToUser(dto *dto.UserCreateDTO) *model.User {
+// MapCreateToUser hashes the password with the bcrypt cost passwordHashCost.
+func MapCreateToUser(dto *dto.UserCreateDTO, passwordHashCost int) *model.User {
 	user := model.User{
 		Username:  dto.Username,
 		Email:     dto.Email,
@@ -31,7 +47,7 @@ func MapCreateToUser(dto *dto.UserCreateDTO) *model.User {
 		IsStaff:   false,
 	}
 
-	if err := user.SetPassword(dto.Password); err != nil {
+	if err := user.SetPassword(dto.Password, passwordHashCost); err != nil {
 		panic(fmt.Sprintf("Falha crítica ao gerar hash de senha: %v", err))
 	}
 
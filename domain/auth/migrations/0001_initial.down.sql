@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS auth_object_permission;
+DROP TABLE IF EXISTS auth_user_groups;
+DROP TABLE IF EXISTS auth_user_permissions;
+DROP TABLE IF EXISTS auth_user;
+DROP TABLE IF EXISTS auth_group_permissions;
+DROP TABLE IF EXISTS auth_group;
+DROP TABLE IF EXISTS auth_permission;
@@ -0,0 +1,82 @@
+-- IF NOT EXISTS lets databases created by AutoMigrate adopt this history.
+CREATE TABLE IF NOT EXISTS `auth_permission` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `module` varchar(100) NOT NULL,
+    `action` varchar(100) NOT NULL,
+    `description` varchar(255) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `unique_permission` (`module`, `action`)
+);
+
+CREATE TABLE IF NOT EXISTS `auth_group` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `name` varchar(50) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_auth_group_name` (`name`)
+);
+
+CREATE TABLE IF NOT EXISTS `auth_group_permissions` (
+    `group_id` bigint unsigned,
+    `permission_id` bigint unsigned,
+    PRIMARY KEY (`group_id`, `permission_id`),
+    CONSTRAINT `fk_auth_group_permissions_group` FOREIGN KEY (`group_id`) REFERENCES `auth_group`(`id`),
+    CONSTRAINT `fk_auth_group_permissions_permission` FOREIGN KEY (`permission_id`) REFERENCES `auth_permission`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `auth_user` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `password` varchar(128) NOT NULL,
+    `last_login` datetime(3) NULL,
+    `is_superuser` boolean DEFAULT false,
+    `username` varchar(150) NOT NULL,
+    `first_name` varchar(150),
+    `last_name` varchar(150),
+    `email` varchar(254) NOT NULL,
+    `is_staff` boolean DEFAULT false,
+    `is_active` boolean DEFAULT true,
+    PRIMARY KEY (`id`),
+    INDEX `idx_auth_user_deleted_at` (`deleted_at`),
+    UNIQUE INDEX `idx_auth_user_username` (`username`),
+    UNIQUE INDEX `idx_auth_user_email` (`email`)
+);
+
+CREATE TABLE IF NOT EXISTS `auth_user_permissions` (
+    `user_id` bigint unsigned,
+    `permission_id` bigint unsigned,
+    PRIMARY KEY (`user_id`, `permission_id`),
+    CONSTRAINT `fk_auth_user_permissions_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`),
+    CONSTRAINT `fk_auth_user_permissions_permission` FOREIGN KEY (`permission_id`) REFERENCES `auth_permission`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `auth_user_groups` (
+    `user_id` bigint unsigned,
+    `group_id` bigint unsigned,
+    PRIMARY KEY (`user_id`, `group_id`),
+    CONSTRAINT `fk_auth_user_groups_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`),
+    CONSTRAINT `fk_auth_user_groups_group` FOREIGN KEY (`group_id`) REFERENCES `auth_group`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `auth_object_permission` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `user_id` bigint unsigned,
+    `group_id` bigint unsigned,
+    `permission_id` bigint unsigned NOT NULL,
+    `object_module` varchar(100) NOT NULL,
+    `object_pk` varchar(64) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_auth_object_permission_user_id` (`user_id`),
+    INDEX `idx_auth_object_permission_group_id` (`group_id`),
+    INDEX `idx_auth_object_permission_permission_id` (`permission_id`),
+    INDEX `idx_object_permission_object` (`object_module`, `object_pk`),
+    CONSTRAINT `fk_auth_object_permission_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_auth_object_permission_group` FOREIGN KEY (`group_id`) REFERENCES `auth_group`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_auth_object_permission_permission` FOREIGN KEY (`permission_id`) REFERENCES `auth_permission`(`id`) ON DELETE CASCADE
+);
@@ -0,0 +1,82 @@
+-- IF NOT EXISTS lets databases created by AutoMigrate adopt this history.
+CREATE TABLE IF NOT EXISTS "auth_permission" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "module" varchar(100) NOT NULL,
+    "action" varchar(100) NOT NULL,
+    "description" varchar(255) NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "unique_permission" ON "auth_permission" ("module", "action");
+
+CREATE TABLE IF NOT EXISTS "auth_group" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "name" varchar(50) NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_auth_group_name" ON "auth_group" ("name");
+
+CREATE TABLE IF NOT EXISTS "auth_group_permissions" (
+    "group_id" bigint,
+    "permission_id" bigint,
+    PRIMARY KEY ("group_id", "permission_id"),
+    CONSTRAINT "fk_auth_group_permissions_group" FOREIGN KEY ("group_id") REFERENCES "auth_group"("id"),
+    CONSTRAINT "fk_auth_group_permissions_permission" FOREIGN KEY ("permission_id") REFERENCES "auth_permission"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "auth_user" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "password" varchar(128) NOT NULL,
+    "last_login" timestamptz,
+    "is_superuser" boolean DEFAULT false,
+    "username" varchar(150) NOT NULL,
+    "first_name" varchar(150),
+    "last_name" varchar(150),
+    "email" varchar(254) NOT NULL,
+    "is_staff" boolean DEFAULT false,
+    "is_active" boolean DEFAULT true,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_auth_user_email" ON "auth_user" ("email");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_auth_user_username" ON "auth_user" ("username");
+CREATE INDEX IF NOT EXISTS "idx_auth_user_deleted_at" ON "auth_user" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "auth_user_permissions" (
+    "user_id" bigint,
+    "permission_id" bigint,
+    PRIMARY KEY ("user_id", "permission_id"),
+    CONSTRAINT "fk_auth_user_permissions_user" FOREIGN KEY ("user_id") REFERENCES "auth_user"("id"),
+    CONSTRAINT "fk_auth_user_permissions_permission" FOREIGN KEY ("permission_id") REFERENCES "auth_permission"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "auth_user_groups" (
+    "user_id" bigint,
+    "group_id" bigint,
+    PRIMARY KEY ("user_id", "group_id"),
+    CONSTRAINT "fk_auth_user_groups_user" FOREIGN KEY ("user_id") REFERENCES "auth_user"("id"),
+    CONSTRAINT "fk_auth_user_groups_group" FOREIGN KEY ("group_id") REFERENCES "auth_group"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "auth_object_permission" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "user_id" bigint,
+    "group_id" bigint,
+    "permission_id" bigint NOT NULL,
+    "object_module" varchar(100) NOT NULL,
+    "object_pk" varchar(64) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_auth_object_permission_user" FOREIGN KEY ("user_id") REFERENCES "auth_user"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_auth_object_permission_group" FOREIGN KEY ("group_id") REFERENCES "auth_group"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_auth_object_permission_permission" FOREIGN KEY ("permission_id") REFERENCES "auth_permission"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_object_permission_object" ON "auth_object_permission" ("object_module", "object_pk");
+CREATE INDEX IF NOT EXISTS "idx_auth_object_permission_permission_id" ON "auth_object_permission" ("permission_id");
+CREATE INDEX IF NOT EXISTS "idx_auth_object_permission_group_id" ON "auth_object_permission" ("group_id");
+CREATE INDEX IF NOT EXISTS "idx_auth_object_permission_user_id" ON "auth_object_permission" ("user_id");
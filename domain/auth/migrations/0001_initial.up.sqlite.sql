@@ -0,0 +1,78 @@
+-- IF NOT EXISTS lets databases created by AutoMigrate adopt this history.
+CREATE TABLE IF NOT EXISTS `auth_permission` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `module` text NOT NULL,
+    `action` text NOT NULL,
+    `description` text NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `unique_permission` ON `auth_permission`(`module`, `action`);
+
+CREATE TABLE IF NOT EXISTS `auth_group` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `name` text NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_auth_group_name` ON `auth_group`(`name`);
+
+CREATE TABLE IF NOT EXISTS `auth_group_permissions` (
+    `group_id` integer,
+    `permission_id` integer,
+    PRIMARY KEY (`group_id`, `permission_id`),
+    CONSTRAINT `fk_auth_group_permissions_group` FOREIGN KEY (`group_id`) REFERENCES `auth_group`(`id`),
+    CONSTRAINT `fk_auth_group_permissions_permission` FOREIGN KEY (`permission_id`) REFERENCES `auth_permission`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `auth_user` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `password` text NOT NULL,
+    `last_login` datetime,
+    `is_superuser` numeric DEFAULT false,
+    `username` text NOT NULL,
+    `first_name` text,
+    `last_name` text,
+    `email` text NOT NULL,
+    `is_staff` numeric DEFAULT false,
+    `is_active` numeric DEFAULT true
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_auth_user_email` ON `auth_user`(`email`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_auth_user_username` ON `auth_user`(`username`);
+CREATE INDEX IF NOT EXISTS `idx_auth_user_deleted_at` ON `auth_user`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `auth_user_permissions` (
+    `user_id` integer,
+    `permission_id` integer,
+    PRIMARY KEY (`user_id`, `permission_id`),
+    CONSTRAINT `fk_auth_user_permissions_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`),
+    CONSTRAINT `fk_auth_user_permissions_permission` FOREIGN KEY (`permission_id`) REFERENCES `auth_permission`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `auth_user_groups` (
+    `user_id` integer,
+    `group_id` integer,
+    PRIMARY KEY (`user_id`, `group_id`),
+    CONSTRAINT `fk_auth_user_groups_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`),
+    CONSTRAINT `fk_auth_user_groups_group` FOREIGN KEY (`group_id`) REFERENCES `auth_group`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `auth_object_permission` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `user_id` integer,
+    `group_id` integer,
+    `permission_id` integer NOT NULL,
+    `object_module` text NOT NULL,
+    `object_pk` text NOT NULL,
+    CONSTRAINT `fk_auth_object_permission_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_auth_object_permission_group` FOREIGN KEY (`group_id`) REFERENCES `auth_group`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_auth_object_permission_permission` FOREIGN KEY (`permission_id`) REFERENCES `auth_permission`(`id`) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS `idx_object_permission_object` ON `auth_object_permission`(`object_module`, `object_pk`);
+CREATE INDEX IF NOT EXISTS `idx_auth_object_permission_permission_id` ON `auth_object_permission`(`permission_id`);
+CREATE INDEX IF NOT EXISTS `idx_auth_object_permission_group_id` ON `auth_object_permission`(`group_id`);
+CREATE INDEX IF NOT EXISTS `idx_auth_object_permission_user_id` ON `auth_object_permission`(`user_id`);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS auth_blacklisted_token;
+DROP TABLE IF EXISTS auth_outstanding_token;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS `auth_outstanding_token` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `user_id` bigint unsigned NOT NULL,
+    `jti` varchar(64) NOT NULL,
+    `family` varchar(64) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_auth_outstanding_token_user_id` (`user_id`),
+    UNIQUE INDEX `idx_auth_outstanding_token_jti` (`jti`),
+    INDEX `idx_auth_outstanding_token_family` (`family`),
+    INDEX `idx_auth_outstanding_token_expires_at` (`expires_at`),
+    CONSTRAINT `fk_auth_outstanding_token_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `auth_blacklisted_token` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `token_id` bigint unsigned NOT NULL,
+    `reason` varchar(20) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_auth_blacklisted_token_token_id` (`token_id`),
+    CONSTRAINT `fk_auth_outstanding_token_blacklisted` FOREIGN KEY (`token_id`) REFERENCES `auth_outstanding_token`(`id`) ON DELETE CASCADE
+);
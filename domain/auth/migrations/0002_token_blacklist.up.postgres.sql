@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS "auth_outstanding_token" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "user_id" bigint NOT NULL,
+    "jti" varchar(64) NOT NULL,
+    "family" varchar(64) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_auth_outstanding_token_user" FOREIGN KEY ("user_id") REFERENCES "auth_user"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_auth_outstanding_token_expires_at" ON "auth_outstanding_token" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_auth_outstanding_token_family" ON "auth_outstanding_token" ("family");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_auth_outstanding_token_jti" ON "auth_outstanding_token" ("jti");
+CREATE INDEX IF NOT EXISTS "idx_auth_outstanding_token_user_id" ON "auth_outstanding_token" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "auth_blacklisted_token" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "token_id" bigint NOT NULL,
+    "reason" varchar(20) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_auth_outstanding_token_blacklisted" FOREIGN KEY ("token_id") REFERENCES "auth_outstanding_token"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_auth_blacklisted_token_token_id" ON "auth_blacklisted_token" ("token_id");
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS `auth_outstanding_token` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `user_id` integer NOT NULL,
+    `jti` text NOT NULL,
+    `family` text NOT NULL,
+    `expires_at` datetime NOT NULL,
+    CONSTRAINT `fk_auth_outstanding_token_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS `idx_auth_outstanding_token_expires_at` ON `auth_outstanding_token`(`expires_at`);
+CREATE INDEX IF NOT EXISTS `idx_auth_outstanding_token_family` ON `auth_outstanding_token`(`family`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_auth_outstanding_token_jti` ON `auth_outstanding_token`(`jti`);
+CREATE INDEX IF NOT EXISTS `idx_auth_outstanding_token_user_id` ON `auth_outstanding_token`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `auth_blacklisted_token` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `token_id` integer NOT NULL,
+    `reason` text NOT NULL,
+    CONSTRAINT `fk_auth_outstanding_token_blacklisted` FOREIGN KEY (`token_id`) REFERENCES `auth_outstanding_token`(`id`) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_auth_blacklisted_token_token_id` ON `auth_blacklisted_token`(`token_id`);
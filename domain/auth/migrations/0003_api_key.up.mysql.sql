@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS `auth_api_key` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `name` varchar(100) NOT NULL,
+    `user_id` bigint unsigned NOT NULL,
+    `prefix` varchar(16) NOT NULL,
+    `hashed_key` varchar(64) NOT NULL,
+    `scopes` varchar(1000) NOT NULL,
+    `expires_at` datetime(3) NULL,
+    `last_used_at` datetime(3) NULL,
+    `revoked_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_auth_api_key_user_id` (`user_id`),
+    UNIQUE INDEX `idx_auth_api_key_prefix` (`prefix`),
+    CONSTRAINT `fk_auth_api_key_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
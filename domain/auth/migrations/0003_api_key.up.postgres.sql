@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS "auth_api_key" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "name" varchar(100) NOT NULL,
+    "user_id" bigint NOT NULL,
+    "prefix" varchar(16) NOT NULL,
+    "hashed_key" varchar(64) NOT NULL,
+    "scopes" varchar(1000) NOT NULL,
+    "expires_at" timestamptz,
+    "last_used_at" timestamptz,
+    "revoked_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_auth_api_key_user" FOREIGN KEY ("user_id") REFERENCES "auth_user"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_auth_api_key_prefix" ON "auth_api_key" ("prefix");
+CREATE INDEX IF NOT EXISTS "idx_auth_api_key_user_id" ON "auth_api_key" ("user_id");
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS `auth_api_key` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `name` text NOT NULL,
+    `user_id` integer NOT NULL,
+    `prefix` text NOT NULL,
+    `hashed_key` text NOT NULL,
+    `scopes` text NOT NULL,
+    `expires_at` datetime,
+    `last_used_at` datetime,
+    `revoked_at` datetime,
+    CONSTRAINT `fk_auth_api_key_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_auth_api_key_prefix` ON `auth_api_key`(`prefix`);
+CREATE INDEX IF NOT EXISTS `idx_auth_api_key_user_id` ON `auth_api_key`(`user_id`);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `auth_session` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `user_id` bigint unsigned NOT NULL,
+    `hashed_key` varchar(64) NOT NULL,
+    `csrf_token` varchar(64) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_auth_session_user_id` (`user_id`),
+    UNIQUE INDEX `idx_auth_session_hashed_key` (`hashed_key`),
+    INDEX `idx_auth_session_expires_at` (`expires_at`),
+    CONSTRAINT `fk_auth_session_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
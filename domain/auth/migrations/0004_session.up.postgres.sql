@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "auth_session" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "user_id" bigint NOT NULL,
+    "hashed_key" varchar(64) NOT NULL,
+    "csrf_token" varchar(64) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_auth_session_user" FOREIGN KEY ("user_id") REFERENCES "auth_user"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_auth_session_expires_at" ON "auth_session" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_auth_session_hashed_key" ON "auth_session" ("hashed_key");
+CREATE INDEX IF NOT EXISTS "idx_auth_session_user_id" ON "auth_session" ("user_id");
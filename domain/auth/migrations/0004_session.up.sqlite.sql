@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `auth_session` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `user_id` integer NOT NULL,
+    `hashed_key` text NOT NULL,
+    `csrf_token` text NOT NULL,
+    `expires_at` datetime NOT NULL,
+    CONSTRAINT `fk_auth_session_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS `idx_auth_session_expires_at` ON `auth_session`(`expires_at`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_auth_session_hashed_key` ON `auth_session`(`hashed_key`);
+CREATE INDEX IF NOT EXISTS `idx_auth_session_user_id` ON `auth_session`(`user_id`);
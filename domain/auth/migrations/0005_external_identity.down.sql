@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS auth_external_identity_groups;
+DROP TABLE IF EXISTS auth_external_identity;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS `auth_external_identity` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `user_id` bigint unsigned NOT NULL,
+    `issuer` varchar(255) NOT NULL,
+    `subject` varchar(255) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_auth_external_identity_user_id` (`user_id`),
+    UNIQUE INDEX `idx_auth_external_identity_subject` (`issuer`, `subject`),
+    CONSTRAINT `fk_auth_external_identity_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `auth_external_identity_groups` (
+    `external_identity_id` bigint unsigned,
+    `group_id` bigint unsigned,
+    PRIMARY KEY (`external_identity_id`, `group_id`),
+    CONSTRAINT `fk_auth_external_identity_groups_external_identity` FOREIGN KEY (`external_identity_id`) REFERENCES `auth_external_identity`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_auth_external_identity_groups_group` FOREIGN KEY (`group_id`) REFERENCES `auth_group`(`id`) ON DELETE CASCADE
+);
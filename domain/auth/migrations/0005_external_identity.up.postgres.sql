@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS "auth_external_identity" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "user_id" bigint NOT NULL,
+    "issuer" varchar(255) NOT NULL,
+    "subject" varchar(255) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_auth_external_identity_user" FOREIGN KEY ("user_id") REFERENCES "auth_user"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_auth_external_identity_subject" ON "auth_external_identity" ("issuer", "subject");
+CREATE INDEX IF NOT EXISTS "idx_auth_external_identity_user_id" ON "auth_external_identity" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "auth_external_identity_groups" (
+    "external_identity_id" bigint,
+    "group_id" bigint,
+    PRIMARY KEY ("external_identity_id", "group_id"),
+    CONSTRAINT "fk_auth_external_identity_groups_external_identity" FOREIGN KEY ("external_identity_id") REFERENCES "auth_external_identity"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_auth_external_identity_groups_group" FOREIGN KEY ("group_id") REFERENCES "auth_group"("id") ON DELETE CASCADE
+);
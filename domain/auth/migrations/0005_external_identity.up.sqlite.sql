@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS `auth_external_identity` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `user_id` integer NOT NULL,
+    `issuer` text NOT NULL,
+    `subject` text NOT NULL,
+    CONSTRAINT `fk_auth_external_identity_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_auth_external_identity_subject` ON `auth_external_identity`(`issuer`,`subject`);
+CREATE INDEX IF NOT EXISTS `idx_auth_external_identity_user_id` ON `auth_external_identity`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `auth_external_identity_groups` (
+    `external_identity_id` integer,
+    `group_id` integer,
+    PRIMARY KEY (`external_identity_id`, `group_id`),
+    CONSTRAINT `fk_auth_external_identity_groups_external_identity` FOREIGN KEY (`external_identity_id`) REFERENCES `auth_external_identity`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_auth_external_identity_groups_group` FOREIGN KEY (`group_id`) REFERENCES `auth_group`(`id`) ON DELETE CASCADE
+);
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// ExternalIdentity links a user to an account of an OpenID Connect
+// provider by the account's issuer and subject, which never change, unlike
+// usernames and emails. Only linked users sign in through the provider: the
+// link is made when the provider provisions the user, or by the linkoidc
+// command for existing users.
+type ExternalIdentity struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	UserID uint64 `gorm:"not null;index"`
+	User   *User  `gorm:"constraint:OnDelete:CASCADE;"`
+
+	Issuer  string `gorm:"size:255;not null;uniqueIndex:idx_auth_external_identity_subject"`
+	Subject string `gorm:"size:255;not null;uniqueIndex:idx_auth_external_identity_subject"`
+
+	// Groups are those the provider's groups claim added the user to. The
+	// sync only ever removes these, not the groups assigned locally.
+	Groups []*Group `gorm:"many2many:auth_external_identity_groups;constraint:OnDelete:CASCADE;"`
+}
+
+func (ExternalIdentity) TableName() string { return "auth_external_identity" }
+
+func (ExternalIdentity) ModuleName() string { return "external_identity" }
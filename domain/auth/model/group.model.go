@@ -1,6 +1,7 @@
 package model
 
 import (
+	"grf/core/models"
 	"time"
 )
 
@@ -17,3 +18,9 @@ type Group struct {
 func (Group) TableName() string { return "auth_group" }
 
 func (Group) ModuleName() string { return "group" }
+
+func (Group) CustomPermissions() []models.CustomPermission {
+	return []models.CustomPermission{
+		{Action: "assign_members", Description: "Permission to assign members to auth_group records."},
+	}
+}
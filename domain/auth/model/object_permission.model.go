@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// ObjectPermission grants a permission on a single record, identified by its
+// module and primary key, to either a user or a group.
+type ObjectPermission struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	UserID  *uint64 `gorm:"index"`
+	User    *User   `gorm:"constraint:OnDelete:CASCADE;"`
+	GroupID *uint64 `gorm:"index"`
+	Group   *Group  `gorm:"constraint:OnDelete:CASCADE;"`
+
+	PermissionID uint64      `gorm:"not null;index"`
+	Permission   *Permission `gorm:"constraint:OnDelete:CASCADE;"`
+
+	ObjectModule string `gorm:"size:100;not null;index:idx_object_permission_object"`
+	ObjectPK     string `gorm:"size:64;not null;index:idx_object_permission_object"`
+}
+
+func (ObjectPermission) TableName() string { return "auth_object_permission" }
+
+func (ObjectPermission) ModuleName() string { return "object_permission" }
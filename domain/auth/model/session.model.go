@@ -0,0 +1,49 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"time"
+)
+
+// Session is a browser login kept server-side. The cookie holds the key,
+// the table only its hash, so a leaked table can't be replayed.
+type Session struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	UserID uint64 `gorm:"not null;index"`
+	User   *User  `gorm:"constraint:OnDelete:CASCADE;"`
+
+	HashedKey string `gorm:"size:64;not null;uniqueIndex"`
+	// CSRFToken must be echoed in a header by unsafe requests.
+	CSRFToken string    `gorm:"size:64;not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+
+	// Key is the cookie value, only set on the instance that generated it.
+	Key string `gorm:"-"`
+}
+
+func (Session) TableName() string { return "auth_session" }
+
+func (Session) ModuleName() string { return "session" }
+
+// GenerateKey sets a new key and CSRF token and returns the key.
+func (s *Session) GenerateKey() string {
+	s.Key = rand.Text() + rand.Text()
+	s.HashedKey = HashSessionKey(s.Key)
+	s.CSRFToken = rand.Text()
+	return s.Key
+}
+
+func (s *Session) CheckCSRF(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(s.CSRFToken), []byte(token)) == 1
+}
+
+func (s *Session) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+func HashSessionKey(key string) string {
+	return hashSecret(key)
+}
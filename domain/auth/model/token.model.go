@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+)
+
+// Reasons a token was blacklisted. Rotated tokens were replaced by refresh;
+// every other reason means the whole session is over.
+const (
+	TokenRotated = "rotated"
+	TokenLogout  = "logout"
+	TokenReused  = "reused"
+	TokenRevoked = "revoked"
+)
+
+// OutstandingToken records an issued refresh token. Tokens rotated from the
+// same login share a Family, so reuse of an old one can revoke the chain.
+type OutstandingToken struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	UserID uint64 `gorm:"not null;index"`
+	User   *User  `gorm:"constraint:OnDelete:CASCADE;"`
+
+	JTI       string    `gorm:"size:64;not null;uniqueIndex"`
+	Family    string    `gorm:"size:64;not null;index"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+
+	Blacklisted *BlacklistedToken `gorm:"foreignKey:TokenID;constraint:OnDelete:CASCADE;"`
+}
+
+func (OutstandingToken) TableName() string { return "auth_outstanding_token" }
+
+func (OutstandingToken) ModuleName() string { return "outstanding_token" }
+
+type BlacklistedToken struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	TokenID uint64 `gorm:"not null;uniqueIndex"`
+	Reason  string `gorm:"size:20;not null"`
+}
+
+func (BlacklistedToken) TableName() string { return "auth_blacklisted_token" }
+
+func (BlacklistedToken) ModuleName() string { return "blacklisted_token" }
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"fmt"
+	"grf/core/config"
+	"grf/core/models"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -31,12 +34,24 @@ func (u *User) TableName() string { return "auth_user" }
 
 func (u *User) ModuleName() string { return "user" }
 
+func (u *User) CustomPermissions() []models.CustomPermission {
+	return []models.CustomPermission{
+		{Action: "export", Description: "Permission to export auth_user records."},
+		{Action: "impersonate", Description: "Permission to impersonate auth_user records."},
+	}
+}
+
 func (u *User) Active() bool { return u.IsActive }
 
 func (u *User) Admin() bool { return u.IsSuperuser || u.IsStaff }
 
-func (u *User) SetPassword(password string) error {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+// SetPassword hashes password with the bcrypt cost of PASSWORD_HASH_COST,
+// config.DefaultPasswordHashCost when zero.
+func (u *User) SetPassword(password string, cost int) error {
+	if cost == 0 {
+		cost = config.DefaultPasswordHashCost
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), cost)
 	if err != nil {
 		return err
 	}
@@ -90,3 +105,71 @@ func (u *User) HasPerm(
 
 	return err == nil && groupPermissionCount > 0
 }
+
+// PermissionCacheKey changes with the superuser flag, so promoting a user
+// never reads a stale set.
+func (u *User) PermissionCacheKey() string {
+	return fmt.Sprintf("%d:%t", u.ID, u.IsSuperuser)
+}
+
+// EffectivePermissions loads the direct and group permissions in one query.
+func (u *User) EffectivePermissions(db *gorm.DB) (*models.PermissionSet, error) {
+	if !u.IsActive {
+		return models.NewPermissionSet(), nil
+	}
+	if u.IsSuperuser {
+		return &models.PermissionSet{All: true}, nil
+	}
+
+	var permissions []Permission
+	err := db.Model(&Permission{}).
+		Select("module", "action").
+		Where("id IN (?) OR id IN (?)",
+			db.Table("auth_user_permissions").Select("permission_id").Where("user_id = ?", u.ID),
+			db.Table("auth_group_permissions").
+				Select("auth_group_permissions.permission_id").
+				Joins("INNER JOIN auth_user_groups ON auth_user_groups.group_id = auth_group_permissions.group_id").
+				Where("auth_user_groups.user_id = ?", u.ID),
+		).
+		Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	codenames := make([]string, len(permissions))
+	for i, permission := range permissions {
+		codenames[i] = permission.Module + "." + permission.Action
+	}
+	return models.NewPermissionSet(codenames...), nil
+}
+
+func (u *User) HasObjectPerm(
+	db *gorm.DB,
+	module string,
+	action string,
+	obj models.IModel,
+) bool {
+	if u.IsActive && u.IsSuperuser {
+		return true
+	}
+	if !u.IsActive {
+		return false
+	}
+
+	objectPK, err := models.PrimaryKey(db, obj)
+	if err != nil {
+		return false
+	}
+
+	userGroups := db.Table("auth_user_groups").Select("group_id").Where("user_id = ?", u.ID)
+
+	var count int64
+	err = db.Model(&ObjectPermission{}).
+		Joins("INNER JOIN auth_permission ON auth_permission.id = auth_object_permission.permission_id").
+		Where("auth_permission.module = ? AND auth_permission.action = ?", module, action).
+		Where("auth_object_permission.object_module = ? AND auth_object_permission.object_pk = ?", obj.ModuleName(), objectPK).
+		Where("auth_object_permission.user_id = ? OR auth_object_permission.group_id IN (?)", u.ID, userGroups).
+		Count(&count).Error
+
+	return err == nil && count > 0
+}
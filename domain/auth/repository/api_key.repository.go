@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"grf/core/repository"
+	"grf/domain/auth/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// lastUsedResolution limits how often authenticating with a key writes its
+// last-used timestamp.
+const lastUsedResolution = time.Minute
+
+type APIKeyRepository struct {
+	repository.IRepository[*model.APIKey, uint64]
+
+	DB *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		IRepository: repository.NewGenericRepository(&repository.Config[*model.APIKey, uint64]{
+			DB: db,
+			NewModel: func() *model.APIKey {
+				return new(model.APIKey)
+			},
+		}),
+		DB: db,
+	}
+}
+
+func (r *APIKeyRepository) FindByPrefix(prefix string) (*model.APIKey, error) {
+	var key model.APIKey
+	if err := r.DB.Preload("User").Where("prefix = ?", prefix).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// TouchLastUsed records a use of key, at most once per lastUsedResolution.
+func (r *APIKeyRepository) TouchLastUsed(key *model.APIKey, now time.Time) error {
+	if key.LastUsedAt != nil && now.Sub(*key.LastUsedAt) < lastUsedResolution {
+		return nil
+	}
+	key.LastUsedAt = &now
+	return r.DB.Model(key).UpdateColumn("last_used_at", now).Error
+}
+
+func (r *APIKeyRepository) Revoke(key *model.APIKey, now time.Time) error {
+	key.RevokedAt = &now
+	return r.DB.Model(key).UpdateColumn("revoked_at", now).Error
+}
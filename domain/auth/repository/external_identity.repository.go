@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"grf/domain/auth/model"
+
+	"gorm.io/gorm"
+)
+
+// ExternalIdentityRepository stores the links between users and the
+// accounts of OpenID Connect providers.
+type ExternalIdentityRepository struct {
+	DB *gorm.DB
+}
+
+func NewExternalIdentityRepository(db *gorm.DB) *ExternalIdentityRepository {
+	return &ExternalIdentityRepository{DB: db}
+}
+
+func (r *ExternalIdentityRepository) Create(identity *model.ExternalIdentity) error {
+	return r.DB.Create(identity).Error
+}
+
+// FindBySubject looks a link up, with its user and the groups the provider
+// added. A soft-deleted user isn't preloaded.
+func (r *ExternalIdentityRepository) FindBySubject(issuer string, subject string) (*model.ExternalIdentity, error) {
+	var identity model.ExternalIdentity
+	err := r.DB.Preload("User").Preload("Groups").
+		Where("issuer = ? AND subject = ?", issuer, subject).
+		First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
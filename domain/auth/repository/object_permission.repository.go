@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"grf/core/models"
+	"grf/core/repository"
+	"grf/domain/auth/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ObjectPermissionRepository struct {
+	repository.IRepository[*model.ObjectPermission, uint64]
+
+	DB *gorm.DB
+}
+
+func NewObjectPermissionRepository(
+	db *gorm.DB,
+) *ObjectPermissionRepository {
+	return &ObjectPermissionRepository{
+		IRepository: repository.NewGenericRepository(&repository.Config[*model.ObjectPermission, uint64]{
+			DB: db,
+			NewModel: func() *model.ObjectPermission {
+				return new(model.ObjectPermission)
+			},
+		}),
+		DB: db,
+	}
+}
+
+// FindObjectPKs returns the primary keys of the objectModule records on which
+// the user holds module.action, directly or through one of their groups.
+func (r *ObjectPermissionRepository) FindObjectPKs(
+	user *model.User,
+	module string,
+	action string,
+	objectModule string,
+) ([]string, error) {
+	userGroups := r.DB.Table("auth_user_groups").Select("group_id").Where("user_id = ?", user.ID)
+
+	var pks []string
+	err := r.DB.Model(&model.ObjectPermission{}).
+		Distinct("auth_object_permission.object_pk").
+		Joins("INNER JOIN auth_permission ON auth_permission.id = auth_object_permission.permission_id").
+		Where("auth_permission.module = ? AND auth_permission.action = ?", module, action).
+		Where("auth_object_permission.object_module = ?", objectModule).
+		Where("auth_object_permission.user_id = ? OR auth_object_permission.group_id IN (?)", user.ID, userGroups).
+		Pluck("auth_object_permission.object_pk", &pks).Error
+	return pks, err
+}
+
+// ObjectsWithPerm narrows a query on objectModel to the records the user may
+// module.action. Users holding the model-wide permission see every record.
+func (r *ObjectPermissionRepository) ObjectsWithPerm(
+	user *model.User,
+	module string,
+	action string,
+	objectModel models.IModel,
+) repository.Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		if user == nil || !user.IsActive {
+			return db.Where("1 = 0")
+		}
+		if user.HasPerm(r.DB, module, action) {
+			return db
+		}
+
+		pks, err := r.FindObjectPKs(user, module, action, objectModel.ModuleName())
+		if err != nil {
+			_ = db.AddError(err)
+			return db
+		}
+		values := make([]interface{}, len(pks))
+		for i, pk := range pks {
+			values[i] = pk
+		}
+		return db.Where(clause.IN{
+			Column: clause.Column{Table: clause.CurrentTable, Name: clause.PrimaryKey},
+			Values: values,
+		})
+	}
+}
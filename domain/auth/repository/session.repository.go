@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"grf/domain/auth/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepository is the database session store.
+type SessionRepository struct {
+	DB *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{DB: db}
+}
+
+func (r *SessionRepository) Create(session *model.Session) error {
+	return r.DB.Create(session).Error
+}
+
+// FindByKey looks a session up by the hash of its key, with its user.
+func (r *SessionRepository) FindByKey(hashedKey string) (*model.Session, error) {
+	var session model.Session
+	if err := r.DB.Preload("User").Where("hashed_key = ?", hashedKey).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) Delete(session *model.Session) error {
+	return r.DB.Delete(session).Error
+}
+
+func (r *SessionRepository) DeleteUser(userID uint64) error {
+	return r.DB.Where("user_id = ?", userID).Delete(&model.Session{}).Error
+}
+
+func (r *SessionRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.DB.Where("expires_at <= ?", now).Delete(&model.Session{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"grf/core/models"
+	"grf/domain/auth/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenRepository stores the outstanding refresh tokens and their blacklist.
+// RevokeUser also runs UserRevokers, for the credentials of other modules.
+type TokenRepository struct {
+	DB           *gorm.DB
+	UserRevokers []models.UserRevoker
+}
+
+func NewTokenRepository(db *gorm.DB, userRevokers ...models.UserRevoker) *TokenRepository {
+	return &TokenRepository{DB: db, UserRevokers: userRevokers}
+}
+
+func (r *TokenRepository) Create(token *model.OutstandingToken) error {
+	return r.DB.Create(token).Error
+}
+
+func (r *TokenRepository) FindByJTI(jti string) (*model.OutstandingToken, error) {
+	var token model.OutstandingToken
+	if err := r.DB.Preload("Blacklisted").Where("jti = ?", jti).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Blacklist reports false when the token was already blacklisted, which
+// lets two concurrent refreshes with the same token be told apart.
+func (r *TokenRepository) Blacklist(token *model.OutstandingToken, reason string) (bool, error) {
+	result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.BlacklistedToken{TokenID: token.ID, Reason: reason})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *TokenRepository) RevokeFamily(family string, reason string) error {
+	return r.revoke(r.DB.Where("family = ?", family), reason)
+}
+
+// RevokeUser ends every refresh token of the user, and the credentials of
+// UserRevokers, e.g. after a password change.
+func (r *TokenRepository) RevokeUser(userID uint64, reason string) error {
+	now := time.Now()
+	if err := r.revoke(r.DB.Where("user_id = ? AND expires_at > ?", userID, now), reason); err != nil {
+		return err
+	}
+	for _, revoker := range r.UserRevokers {
+		if err := revoker(r.DB, userID, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *TokenRepository) revoke(scope *gorm.DB, reason string) error {
+	var ids []uint64
+	err := scope.Model(&model.OutstandingToken{}).
+		Where("id NOT IN (?)", r.DB.Model(&model.BlacklistedToken{}).Select("token_id")).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	blacklist := make([]model.BlacklistedToken, len(ids))
+	for i, id := range ids {
+		blacklist[i] = model.BlacklistedToken{TokenID: id, Reason: reason}
+	}
+	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&blacklist).Error
+}
+
+// FamilyRevoked reports whether the session behind family was ended. Tokens
+// blacklisted by rotation don't count, the family lives on in their successor.
+func (r *TokenRepository) FamilyRevoked(family string) (bool, error) {
+	var count int64
+	err := r.DB.Model(&model.BlacklistedToken{}).
+		Joins("JOIN auth_outstanding_token ON auth_outstanding_token.id = auth_blacklisted_token.token_id").
+		Where("auth_outstanding_token.family = ? AND auth_blacklisted_token.reason <> ?", family, model.TokenRotated).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// DeleteExpired removes the tokens expired before now, blacklisted or not.
+func (r *TokenRepository) DeleteExpired(now time.Time) (int64, error) {
+	var deleted int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		expired := tx.Model(&model.OutstandingToken{}).Select("id").Where("expires_at <= ?", now)
+		if err := tx.Where("token_id IN (?)", expired).Delete(&model.BlacklistedToken{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("expires_at <= ?", now).Delete(&model.OutstandingToken{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
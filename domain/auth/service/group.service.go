@@ -2,6 +2,7 @@ package service
 
 import (
 	"grf/core/exceptions"
+	"grf/core/models"
 	generic_repository "grf/core/repository"
 	"grf/core/service"
 	"grf/domain/auth/dto"
@@ -31,6 +32,23 @@ func NewGroupService(
 	}
 }
 
+func (s *GroupService) WithScopes(scopes ...generic_repository.Scope) service.IService[*model.Group, *dto.GroupCreateDTO, *dto.GroupUpdateDTO, *dto.GroupPatchDTO, *dto.GroupResponseDTO, *filter.GroupFilterSet, uint64] {
+	if len(scopes) == 0 {
+		return s
+	}
+	return &GroupService{
+		IService: s.IService.WithScopes(scopes...),
+		DB:       s.DB,
+	}
+}
+
+func (s *GroupService) ForUser(user models.IUser) service.IService[*model.Group, *dto.GroupCreateDTO, *dto.GroupUpdateDTO, *dto.GroupPatchDTO, *dto.GroupResponseDTO, *filter.GroupFilterSet, uint64] {
+	return &GroupService{
+		IService: s.IService.ForUser(user),
+		DB:       s.DB,
+	}
+}
+
 func (s *GroupService) Create(dto *dto.GroupCreateDTO) (*model.Group, error) {
 	newRecord := mapper.MapCreateToGroup(dto)
 
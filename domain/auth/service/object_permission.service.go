@@ -0,0 +1,77 @@
+package service
+
+import (
+	"grf/core/exceptions"
+	"grf/core/models"
+	"grf/domain/auth/model"
+	"grf/domain/auth/repository"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ObjectPermissionService assigns and removes per-object grants. Codenames
+// take the "module.action" form, e.g. "group.update".
+type ObjectPermissionService struct {
+	Repo *repository.ObjectPermissionRepository
+	DB   *gorm.DB
+}
+
+func NewObjectPermissionService(db *gorm.DB) *ObjectPermissionService {
+	return &ObjectPermissionService{
+		Repo: repository.NewObjectPermissionRepository(db),
+		DB:   db,
+	}
+}
+
+func (s *ObjectPermissionService) AssignToUser(user *model.User, codename string, obj models.IModel) error {
+	return s.assign(&model.ObjectPermission{UserID: &user.ID}, codename, obj)
+}
+
+func (s *ObjectPermissionService) AssignToGroup(group *model.Group, codename string, obj models.IModel) error {
+	return s.assign(&model.ObjectPermission{GroupID: &group.ID}, codename, obj)
+}
+
+func (s *ObjectPermissionService) RemoveFromUser(user *model.User, codename string, obj models.IModel) error {
+	return s.remove(&model.ObjectPermission{UserID: &user.ID}, codename, obj)
+}
+
+func (s *ObjectPermissionService) RemoveFromGroup(group *model.Group, codename string, obj models.IModel) error {
+	return s.remove(&model.ObjectPermission{GroupID: &group.ID}, codename, obj)
+}
+
+func (s *ObjectPermissionService) assign(grant *model.ObjectPermission, codename string, obj models.IModel) error {
+	if err := s.resolve(grant, codename, obj); err != nil {
+		return err
+	}
+	return s.DB.Where(grant).FirstOrCreate(grant).Error
+}
+
+func (s *ObjectPermissionService) remove(grant *model.ObjectPermission, codename string, obj models.IModel) error {
+	if err := s.resolve(grant, codename, obj); err != nil {
+		return err
+	}
+	return s.DB.Where(grant).Delete(&model.ObjectPermission{}).Error
+}
+
+func (s *ObjectPermissionService) resolve(grant *model.ObjectPermission, codename string, obj models.IModel) error {
+	module, action, ok := strings.Cut(codename, ".")
+	if !ok {
+		return exceptions.NewBadRequest("error_query_permissions", nil)
+	}
+
+	var permission model.Permission
+	if err := s.DB.Where("module = ? AND action = ?", module, action).First(&permission).Error; err != nil {
+		return exceptions.NewBadRequest("error_query_permissions", err)
+	}
+
+	objectPK, err := models.PrimaryKey(s.DB, obj)
+	if err != nil {
+		return exceptions.NewInternal(err)
+	}
+
+	grant.PermissionID = permission.ID
+	grant.ObjectModule = obj.ModuleName()
+	grant.ObjectPK = objectPK
+	return nil
+}
@@ -0,0 +1,242 @@
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"grf/core/config"
+	"grf/core/keyring"
+	"grf/domain/auth/model"
+	"grf/domain/auth/repository"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOIDCTokenInvalid = errors.New("invalid_oidc_token")
+	ErrOIDCUserNotFound = errors.New("oidc_user_not_found")
+)
+
+// oidcMethods are the algorithms accepted from the provider. HMAC is left
+// out, as the provider's secret is never shared.
+var oidcMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// oidcLeeway absorbs the clock skew between the provider and the API.
+const oidcLeeway = 30 * time.Second
+
+// unusablePassword never matches a bcrypt comparison, so users created from
+// the provider can't log in with a password until one is set.
+const unusablePassword = "!"
+
+// OIDCService authenticates the JWTs of an external OpenID Connect provider
+// and maps them to local users through their ExternalIdentity.
+type OIDCService struct {
+	Config     *config.Config
+	Keys       *keyring.JWKSource
+	UserRepo   *repository.UserRepository
+	Identities *repository.ExternalIdentityRepository
+	DB         *gorm.DB
+}
+
+func NewOIDCService(db *gorm.DB, config *config.Config, keys *keyring.JWKSource) *OIDCService {
+	if config.OIDCIssuer == "" {
+		panic("OIDCService requer OIDC_ISSUER")
+	}
+	// Without an audience, tokens the provider issued to any of its clients
+	// would be accepted.
+	if config.OIDCAudience == "" {
+		panic("OIDCService requer OIDC_AUDIENCE")
+	}
+	return &OIDCService{
+		Config:     config,
+		Keys:       keys,
+		UserRepo:   repository.NewUserRepository(db),
+		Identities: repository.NewExternalIdentityRepository(db),
+		DB:         db,
+	}
+}
+
+// Issues tells whether tokenString claims to come from the provider. The
+// signature isn't checked, it only routes the token to this service.
+func (s *OIDCService) Issues(tokenString string) bool {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return false
+	}
+	issuer, err := claims.GetIssuer()
+	return err == nil && issuer == s.Config.OIDCIssuer
+}
+
+// Authenticate validates tokenString and returns the user linked to its
+// subject, created or with its groups synced as configured.
+func (s *OIDCService) Authenticate(tokenString string) (*model.User, error) {
+	claims, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	subject, _ := claims.GetSubject()
+	if subject == "" {
+		return nil, fmt.Errorf("%w: missing claim %q", ErrOIDCTokenInvalid, "sub")
+	}
+
+	identity, err := s.Identities.FindBySubject(s.Config.OIDCIssuer, subject)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if !s.Config.OIDCCreateUsers {
+			return nil, ErrOIDCUserNotFound
+		}
+		identity, err = s.createUser(subject, claims)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// A deleted user isn't brought back by its next request.
+	if identity.User == nil {
+		return nil, ErrOIDCUserNotFound
+	}
+
+	if s.Config.OIDCGroupsClaim != "" {
+		if err := s.syncGroups(identity, groupNames(claims[s.Config.OIDCGroupsClaim])); err != nil {
+			return nil, err
+		}
+	}
+	return identity.User, nil
+}
+
+func (s *OIDCService) parseToken(tokenString string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, s.Keys.Keyfunc,
+		jwt.WithValidMethods(oidcMethods),
+		jwt.WithIssuer(s.Config.OIDCIssuer),
+		jwt.WithAudience(s.Config.OIDCAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(oidcLeeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrOIDCTokenInvalid, err)
+	}
+	return claims, nil
+}
+
+// createUser provisions the user of subject on its first request and links
+// it. The username and email are required, as local users are unique by
+// both.
+func (s *OIDCService) createUser(subject string, claims jwt.MapClaims) (*model.ExternalIdentity, error) {
+	username, _ := claims[s.Config.OIDCUsernameClaim].(string)
+	if username == "" {
+		return nil, fmt.Errorf("%w: missing claim %q", ErrOIDCTokenInvalid, s.Config.OIDCUsernameClaim)
+	}
+	email, _ := claims["email"].(string)
+	if email == "" {
+		return nil, fmt.Errorf("%w: missing claim %q", ErrOIDCTokenInvalid, "email")
+	}
+	// Matching local accounts by username or email would let the provider
+	// take them over; they are linked explicitly instead.
+	if s.DB.Unscoped().Where("username = ? OR email = ?", username, email).First(&model.User{}).Error == nil {
+		return nil, fmt.Errorf("%w: %q or %q belongs to another user", ErrOIDCUserNotFound, username, email)
+	}
+	firstName, _ := claims["given_name"].(string)
+	lastName, _ := claims["family_name"].(string)
+
+	user := &model.User{
+		Username:  username,
+		Email:     email,
+		FirstName: firstName,
+		LastName:  lastName,
+		Password:  unusablePassword + rand.Text(),
+		IsActive:  true,
+	}
+	identity := &model.ExternalIdentity{Issuer: s.Config.OIDCIssuer, Subject: subject}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Omit("User", "Groups").Create(identity).Error
+	})
+	if err != nil {
+		// A concurrent first request may have created it already.
+		if existing, findErr := s.Identities.FindBySubject(s.Config.OIDCIssuer, subject); findErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+	identity.User = user
+	return identity, nil
+}
+
+// syncGroups adds the user to the existing groups named, and removes it from
+// those an earlier claim added that this one no longer names. Groups
+// assigned locally are left alone, and unknown names are ignored: groups and
+// their permissions are managed locally. Nothing is written while the
+// membership is unchanged, which would clear the permission cache on every
+// request.
+func (s *OIDCService) syncGroups(identity *model.ExternalIdentity, names []string) error {
+	var wanted []*model.Group
+	if len(names) > 0 {
+		if err := s.DB.Where("name IN ?", names).Order("id").Find(&wanted).Error; err != nil {
+			return err
+		}
+	}
+	var current []uint64
+	if err := s.DB.Table("auth_user_groups").Where("user_id = ?", identity.UserID).Pluck("group_id", &current).Error; err != nil {
+		return err
+	}
+
+	var added, removed []*model.Group
+	for _, group := range wanted {
+		if !slices.Contains(current, group.ID) {
+			added = append(added, group)
+		}
+	}
+	for _, group := range identity.Groups {
+		named := slices.ContainsFunc(wanted, func(g *model.Group) bool { return g.ID == group.ID })
+		if !named {
+			removed = append(removed, group)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if len(added) > 0 {
+			if err := tx.Model(identity.User).Association("Groups").Append(added); err != nil {
+				return err
+			}
+			if err := tx.Model(identity).Association("Groups").Append(added); err != nil {
+				return err
+			}
+		}
+		if len(removed) > 0 {
+			if err := tx.Model(identity.User).Association("Groups").Delete(removed); err != nil {
+				return err
+			}
+			if err := tx.Model(identity).Association("Groups").Delete(removed); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// groupNames reads a groups claim, a list of names or a single
+// space-separated string.
+func groupNames(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		names := make([]string, 0, len(value))
+		for _, item := range value {
+			if name, ok := item.(string); ok && name != "" {
+				names = append(names, name)
+			}
+		}
+		return names
+	}
+	return nil
+}
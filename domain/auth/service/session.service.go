@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"grf/core/config"
+	"grf/domain/auth/model"
+	"grf/domain/auth/repository"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrSessionInvalid = errors.New("session invalid or expired")
+
+// ISessionStore keeps the sessions server-side. FindByKey returns
+// gorm.ErrRecordNotFound for unknown keys. repository.SessionRepository is
+// the database store used by default.
+type ISessionStore interface {
+	Create(session *model.Session) error
+	FindByKey(hashedKey string) (*model.Session, error)
+	Delete(session *model.Session) error
+	DeleteUser(userID uint64) error
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type SessionService struct {
+	Config *config.Config
+	Store  ISessionStore
+}
+
+func NewSessionService(db *gorm.DB, config *config.Config) *SessionService {
+	return &SessionService{
+		Config: config,
+		Store:  repository.NewSessionRepository(db),
+	}
+}
+
+// Login opens a session for user; its Key is the cookie value.
+func (s *SessionService) Login(user *model.User) (*model.Session, error) {
+	session := &model.Session{
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(time.Duration(s.Config.SessionCookieAge) * time.Second),
+	}
+	session.GenerateKey()
+	if err := s.Store.Create(session); err != nil {
+		return nil, err
+	}
+	session.User = user
+	return session, nil
+}
+
+// Validate returns the session of a cookie value, with its user. Whether the
+// user is active is left to permission.IsAuthenticated.
+func (s *SessionService) Validate(key string) (*model.Session, error) {
+	session, err := s.Store.FindByKey(model.HashSessionKey(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionInvalid
+		}
+		return nil, err
+	}
+	// A soft-deleted user isn't preloaded.
+	if session.User == nil || session.Expired(time.Now()) {
+		return nil, ErrSessionInvalid
+	}
+	return session, nil
+}
+
+func (s *SessionService) Logout(session *model.Session) error {
+	return s.Store.Delete(session)
+}
+
+// RevokeUserSessions logs the user out of every browser.
+func (s *SessionService) RevokeUserSessions(user *model.User) error {
+	return s.Store.DeleteUser(user.ID)
+}
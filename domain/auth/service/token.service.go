@@ -1,9 +1,11 @@
 package service
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"grf/core/config"
+	"grf/core/keyring"
 	"grf/domain/auth/model"
 	"grf/domain/auth/repository"
 	"time"
@@ -12,74 +14,173 @@ import (
 	"gorm.io/gorm"
 )
 
+var (
+	ErrTokenRevoked = errors.New("token has been revoked")
+	ErrTokenReused  = errors.New("refresh token reuse detected, session revoked")
+)
+
 type TokenService struct {
-	Config   *config.Config
-	UserRepo *repository.UserRepository
+	Config    *config.Config
+	Keys      *keyring.Keyring
+	UserRepo  *repository.UserRepository
+	TokenRepo *repository.TokenRepository
 }
 
+// CustomClaims carries the token id in jti and the login it descends from in
+// fam. Access and refresh tokens of a pair share the family.
 type CustomClaims struct {
 	UserID uint64 `json:"user_id"`
 	Type   string `json:"type"`
+	Family string `json:"fam"`
 	jwt.RegisteredClaims
 }
 
-func NewTokenService(db *gorm.DB, config *config.Config) *TokenService {
+func NewTokenService(db *gorm.DB, config *config.Config, keys *keyring.Keyring) *TokenService {
 	return &TokenService{
-		Config: config, UserRepo: repository.NewUserRepository(db),
+		Config:    config,
+		Keys:      keys,
+		UserRepo:  repository.NewUserRepository(db),
+		TokenRepo: repository.NewTokenRepository(db),
 	}
 }
 
+// GenerateTokenPair starts a new token family, as a login does.
 func (s *TokenService) GenerateTokenPair(user *model.User) (accessToken string, refreshToken string, err error) {
-	accessExp := time.Now().Add(time.Minute * time.Duration(s.Config.JWTExpiresInMinutes))
-	accessClaims := CustomClaims{
-		UserID: user.ID,
-		Type:   "access",
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(accessExp),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   fmt.Sprintf("%d", user.ID),
-		},
+	return s.generateTokenPair(user, rand.Text())
+}
+
+func (s *TokenService) generateTokenPair(user *model.User, family string) (accessToken string, refreshToken string, err error) {
+	now := time.Now()
+	accessExp := now.Add(time.Minute * time.Duration(s.Config.JWTExpiresInMinutes))
+	accessToken, err = s.sign(user, "access", family, rand.Text(), now, accessExp)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshExp := now.Add(time.Hour * 24 * time.Duration(s.Config.JWTRefreshExpiresInDays))
+	jti := rand.Text()
+	refreshToken, err = s.sign(user, "refresh", family, jti, now, refreshExp)
+	if err != nil {
+		return "", "", err
 	}
-	accessToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims).
-		SignedString([]byte(s.Config.JWTSecret))
+	err = s.TokenRepo.Create(&model.OutstandingToken{
+		UserID:    user.ID,
+		JTI:       jti,
+		Family:    family,
+		ExpiresAt: refreshExp,
+	})
 	if err != nil {
 		return "", "", err
 	}
 
-	refreshExp := time.Now().Add(time.Hour * 24 * time.Duration(s.Config.JWTRefreshExpiresInDays))
-	refreshClaims := CustomClaims{
+	return accessToken, refreshToken, nil
+}
+
+func (s *TokenService) sign(user *model.User, tokenType, family, jti string, issuedAt, expiresAt time.Time) (string, error) {
+	claims := CustomClaims{
 		UserID: user.ID,
-		Type:   "refresh",
+		Type:   tokenType,
+		Family: family,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(refreshExp),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
 			Subject:   fmt.Sprintf("%d", user.ID),
 		},
 	}
-	refreshToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, refreshClaims).
-		SignedString([]byte(s.Config.JWTSecret))
+	return s.Keys.Sign(claims)
+}
+
+// RotateRefreshToken exchanges a refresh token for a new pair of the same
+// family and blacklists it. Presenting a rotated token again means it leaked,
+// so the whole family is revoked.
+func (s *TokenService) RotateRefreshToken(tokenString string) (*model.User, string, string, error) {
+	claims, err := s.parseToken(tokenString, "refresh")
+	if err != nil {
+		return nil, "", "", err
+	}
+	outstanding, err := s.TokenRepo.FindByJTI(claims.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, "", "", ErrTokenRevoked
+	}
 	if err != nil {
-		return "", "", err
+		return nil, "", "", err
 	}
 
-	return accessToken, refreshToken, nil
+	if outstanding.Blacklisted != nil {
+		if outstanding.Blacklisted.Reason != model.TokenRotated {
+			return nil, "", "", ErrTokenRevoked
+		}
+		return nil, "", "", s.revokeReused(outstanding)
+	}
+	rotated, err := s.TokenRepo.Blacklist(outstanding, model.TokenRotated)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if !rotated {
+		// A concurrent refresh won the race with the same token.
+		return nil, "", "", s.revokeReused(outstanding)
+	}
+
+	user, err := s.activeUser(claims.UserID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	access, refresh, err := s.generateTokenPair(user, outstanding.Family)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return user, access, refresh, nil
+}
+
+func (s *TokenService) revokeReused(outstanding *model.OutstandingToken) error {
+	if err := s.TokenRepo.RevokeFamily(outstanding.Family, model.TokenReused); err != nil {
+		return err
+	}
+	return ErrTokenReused
+}
+
+// Logout revokes the family of a refresh token, ending that session only.
+func (s *TokenService) Logout(tokenString string) error {
+	claims, err := s.parseToken(tokenString, "refresh")
+	if err != nil {
+		return err
+	}
+	return s.TokenRepo.RevokeFamily(claims.Family, model.TokenLogout)
+}
+
+// RevokeUserTokens ends every session of the user, e.g. after a password
+// change.
+func (s *TokenService) RevokeUserTokens(user *model.User) error {
+	return s.TokenRepo.RevokeUser(user.ID, model.TokenRevoked)
 }
 
 func (s *TokenService) ValidateToken(tokenString string, expectedType string) (*model.User, error) {
-	claims := &CustomClaims{}
+	claims, err := s.parseToken(tokenString, expectedType)
+	if err != nil {
+		return nil, err
+	}
 
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signature method: %v", token.Header["alg"])
-		}
-		return []byte(s.Config.JWTSecret), nil
-	})
+	revoked, err := s.TokenRepo.FamilyRevoked(claims.Family)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
 
+	return s.activeUser(claims.UserID)
+}
+
+func (s *TokenService) parseToken(tokenString string, expectedType string) (*CustomClaims, error) {
+	claims := &CustomClaims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.Keys.Keyfunc, jwt.WithValidMethods(s.Keys.Methods()))
 	if err != nil {
 		return nil, errors.New("token invalid or expired")
 	}
 
-	if !token.Valid || claims.UserID == 0 {
+	if !token.Valid || claims.UserID == 0 || claims.ID == "" || claims.Family == "" {
 		return nil, errors.New("invalid token")
 	}
 
@@ -87,8 +188,12 @@ func (s *TokenService) ValidateToken(tokenString string, expectedType string) (*
 		return nil, fmt.Errorf("invalid token type: expected '%s', received '%s'", expectedType, claims.Type)
 	}
 
-	var user *model.User
-	if user, err = s.UserRepo.FindById(claims.UserID); err != nil {
+	return claims, nil
+}
+
+func (s *TokenService) activeUser(id uint64) (*model.User, error) {
+	user, err := s.UserRepo.FindById(id)
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("user not found")
 		}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"grf/core/models"
+	"grf/core/repository"
+	"grf/domain/auth/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserScope lets superusers and staff see every user; everyone else only
+// sees their own row.
+func UserScope(user models.IUser) repository.Scope {
+	return func(db *gorm.DB) *gorm.DB {
+		if user != nil && user.Admin() {
+			return db
+		}
+		current, ok := user.(*model.User)
+		if !ok {
+			return db.Where("1 = 0")
+		}
+		return db.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "id"}, Value: current.ID})
+	}
+}
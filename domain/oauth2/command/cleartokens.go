@@ -0,0 +1,33 @@
+package command
+
+import (
+	"fmt"
+	"grf/core/management"
+	"grf/domain/oauth2/repository"
+	"time"
+)
+
+func NewClearTokensCommand() *management.Command {
+	return &management.Command{
+		Name:        "cleartokens",
+		Description: "Delete expired OAuth2 authorization codes and tokens",
+		Run:         clearTokens,
+	}
+}
+
+func clearTokens(ctx *management.Context, args []string) error {
+	if err := ctx.FlagSet("cleartokens").Parse(args); err != nil {
+		return err
+	}
+
+	db, err := ctx.DB()
+	if err != nil {
+		return err
+	}
+	codes, tokens, err := repository.NewTokenRepository(db).DeleteExpired(time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(ctx.Out, "%d expired codes and %d expired tokens deleted.\n", codes, tokens)
+	return nil
+}
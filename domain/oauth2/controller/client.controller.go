@@ -0,0 +1,58 @@
+package controller
+
+import (
+	controllers "grf/core/controller"
+	"grf/core/filterset"
+	"grf/core/pagination"
+	"grf/core/service"
+	"grf/domain/oauth2/dto"
+	"grf/domain/oauth2/filter"
+	"grf/domain/oauth2/mapper"
+	"grf/domain/oauth2/model"
+	"grf/domain/oauth2/repository"
+	services "grf/domain/oauth2/service"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+)
+
+func NewDefaultClientController(
+	db *gorm.DB,
+	validate *validator.Validate,
+) *controllers.GenericController[
+	*model.Client, *dto.ClientCreateDTO, *dto.ClientUpdateDTO, *dto.ClientPatchDTO, *dto.ClientResponseDTO, *filter.ClientFilterSet, uint64,
+] {
+	clientRepo := repository.NewClientRepository(db)
+
+	clientService := services.NewClientService(
+		&service.Config[*model.Client, *dto.ClientCreateDTO, *dto.ClientUpdateDTO, *dto.ClientPatchDTO, *dto.ClientResponseDTO, *filter.ClientFilterSet, uint64]{
+			Repo:             clientRepo,
+			MapCreateToModel: mapper.MapCreateToClient,
+			MapUpdateToModel: mapper.MapUpdateToClient,
+		},
+		clientRepo,
+	)
+
+	clientPaginator := pagination.NewLimitOffsetPagination[*model.Client](10, 100)
+	clientConfig := &controllers.Config[
+		*model.Client, *dto.ClientCreateDTO, *dto.ClientUpdateDTO, *dto.ClientPatchDTO, *dto.ClientResponseDTO, *filter.ClientFilterSet, uint64,
+	]{
+		Service:       clientService,
+		Validator:     validate,
+		Paginator:     clientPaginator,
+		Ordering:      filterset.NewOrderingFilter([]string{"id", "name", "created_at"}, "id"),
+		MapToResponse: mapper.MapClientToResponse,
+		NewFilterSet:  func() *filter.ClientFilterSet { return new(filter.ClientFilterSet) },
+		NewSearchFilter: func() filterset.IFilterSet {
+			return filterset.NewSearchFilter("name", "client_id")
+		},
+		NewPatchDTO: func() *dto.ClientPatchDTO { return new(dto.ClientPatchDTO) },
+		ParseID: func(s string) (uint64, error) {
+			id, err := strconv.ParseUint(s, 10, 64)
+			return id, err
+		},
+	}
+
+	return controllers.NewGenericController(clientConfig)
+}
@@ -0,0 +1,114 @@
+package controller_test
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	tests2 "grf/core/tests"
+	authdto "grf/domain/auth/dto"
+	authmodel "grf/domain/auth/model"
+	"grf/domain/oauth2/dto"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+var oauth2Tables = []string{
+	"oauth2_token",
+	"oauth2_authorization_code",
+	"oauth2_client",
+	"auth_user",
+}
+
+func clearOAuth2Tables(db *gorm.DB) {
+	tests2.ClearTables(db, oauth2Tables)
+	testApp.PermissionResolver.Invalidate()
+}
+
+type TestFixtures struct {
+	AdminUser  *authmodel.User
+	NormalUser *authmodel.User
+}
+
+func createTestFixtures(db *gorm.DB) (*TestFixtures, error) {
+	adminUser := authmodel.User{Username: "admin", Email: "admin@test.com", IsActive: true, IsSuperuser: true}
+	if err := adminUser.SetPassword("admin123", testApp.Config.PasswordHashCost); err != nil {
+		return nil, err
+	}
+	if err := db.Create(&adminUser).Error; err != nil {
+		return nil, err
+	}
+
+	normalUser := authmodel.User{Username: "user", Email: "user@test.com", IsActive: true}
+	if err := normalUser.SetPassword("user123", testApp.Config.PasswordHashCost); err != nil {
+		return nil, err
+	}
+	if err := db.Create(&normalUser).Error; err != nil {
+		return nil, err
+	}
+
+	return &TestFixtures{AdminUser: &adminUser, NormalUser: &normalUser}, nil
+}
+
+func loginAs(t testing.TB, username, password string) string {
+	resp, body := tests2.MakeRequest(t, testApp.FiberApp, tests2.RequestOptions{
+		Method: http.MethodPost,
+		URL:    "/v1/auth/token",
+		Body:   authdto.ObtainTokenDTO{Login: username, Password: password},
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Falha ao logar como %s, status %d: %s", username, resp.StatusCode, body)
+	}
+	var tokenResp authdto.TokenResponseDTO
+	json.Unmarshal([]byte(body), &tokenResp)
+	return tokenResp.AccessToken
+}
+
+// createClient registers a client through the API as admin.
+func createClient(t testing.TB, adminToken string, input dto.ClientCreateDTO) dto.ClientResponseDTO {
+	resp, body := tests2.MakeRequest(t, testApp.FiberApp, tests2.RequestOptions{
+		Method: http.MethodPost, URL: "/v1/oauth2/clients", Token: adminToken, Body: input,
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Falha ao criar cliente %s, status %d: %s", input.Name, resp.StatusCode, body)
+	}
+	var client dto.ClientResponseDTO
+	json.Unmarshal([]byte(body), &client)
+	return client
+}
+
+// pkcePair returns a code verifier and its S256 challenge.
+func pkcePair(seed string) (verifier, challenge string) {
+	verifier = strings.Repeat(seed, 43/len(seed)+1)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// postForm sends a form-encoded request, as OAuth2 clients do.
+func postForm(t testing.TB, path string, form url.Values, header map[string]string) (*http.Response, string) {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	resp, err := testApp.FiberApp.Test(req, 10000)
+	if err != nil {
+		t.Fatalf("Falha ao executar requisição POST %s: %v", path, err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Falha ao ler corpo da resposta: %v", err)
+	}
+	t.Logf("\nResponse\nStatus: %d\nPath: %s\nResponse: %s", resp.StatusCode, path, body)
+	return resp, string(body)
+}
+
+func basicAuth(clientID, secret string) map[string]string {
+	credentials := url.QueryEscape(clientID) + ":" + url.QueryEscape(secret)
+	return map[string]string{"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))}
+}
@@ -0,0 +1,67 @@
+package controller_test
+
+import (
+	"grf/core/bootstrap"
+	"grf/core/config"
+	"grf/core/server"
+	"grf/domain/auth"
+	"grf/domain/oauth2"
+	"log"
+	"os"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testApp *server.App
+var err error
+
+func TestMain(m *testing.M) {
+	testApp, err = bootstrap.NewApp(config.Config{
+		DBName:               "file:memdb_oauth2?mode=memory&cache=shared",
+		DBVendor:             "sqlite",
+		DBMigrate:            true,
+		PermissionSync:       true,
+		DBLogLevel:           "info",
+		DBMaxIdle:            10,
+		DBMaxOpened:          30,
+		DBMaxLifeTimeSeconds: 600, // o banco em memória some quando todas as conexões fecham
+
+		AppName: "TestGRF",
+
+		Env: "development",
+
+		ServerPort:         "1234",
+		ServerIdleTimeout:  3,
+		ServerReadTimeout:  3,
+		ServerWriteTimeout: 3,
+
+		JWTSecret:               "test_super_secret_jwt_secret_do_not_verify",
+		JWTExpiresInMinutes:     30,
+		JWTRefreshExpiresInDays: 1,
+
+		PasswordHashCost: bcrypt.MinCost,
+
+		SessionCookieName:     "sessionid",
+		SessionCookieAge:      3600,
+		SessionCookieSameSite: "Lax",
+		CSRFCookieName:        "csrftoken",
+		CSRFHeaderName:        "X-CSRFToken",
+
+		OAuth2CodeSeconds:        60,
+		OAuth2AccessTokenSeconds: 3600,
+		OAuth2RefreshTokenDays:   30,
+	},
+		append(auth.GetModels(), oauth2.GetModels()...),
+		append(auth.GetMigrations(), oauth2.GetMigrations()...),
+		oauth2.RevokeUser,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	code := m.Run()
+
+	log.Println("Suíte de testes 'oauth2' concluída.")
+	os.Exit(code)
+}
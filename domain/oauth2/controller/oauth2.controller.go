@@ -0,0 +1,200 @@
+package controller
+
+import (
+	"encoding/base64"
+	"errors"
+	"grf/core/config"
+	"grf/core/exceptions"
+	"grf/core/permission"
+	authmodel "grf/domain/auth/model"
+	"grf/domain/oauth2/dto"
+	"grf/domain/oauth2/model"
+	"grf/domain/oauth2/service"
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type Controller struct {
+	Service *service.OAuth2Service
+}
+
+func NewOAuth2Controller(db *gorm.DB, config *config.Config) *Controller {
+	return &Controller{
+		Service: service.NewOAuth2Service(db, config),
+	}
+}
+
+// AuthorizeInfo validates an authorization request from the query and
+// describes it, for the front-end to ask the user's consent.
+func (oc *Controller) AuthorizeInfo(c *fiber.Ctx) error {
+	var input dto.AuthorizeDTO
+	if err := c.QueryParser(&input); err != nil {
+		return exceptions.NewBadRequest("invalid_payload", err)
+	}
+
+	req, err := oc.Service.ValidateAuthorization(&input)
+	if err != nil {
+		return oc.authorizeError(c, req, err)
+	}
+
+	return c.JSON(dto.AuthorizationRequestDTO{
+		ClientID:    req.Client.ClientID,
+		ClientName:  req.Client.Name,
+		RedirectURI: req.RedirectURI,
+		Scopes:      req.Scopes,
+		State:       req.State,
+	})
+}
+
+// Authorize records the decision of the authenticated user and returns where
+// to send them: the redirect URI with a code, or with access_denied.
+func (oc *Controller) Authorize(c *fiber.Ctx) error {
+	var input dto.AuthorizeDTO
+	if err := c.BodyParser(&input); err != nil {
+		return exceptions.NewBadRequest("invalid_payload", err)
+	}
+	user, ok := c.Locals("user").(*authmodel.User)
+	if !ok {
+		return exceptions.NewInternal(errors.New("c.Locals(\"user\") não encontrado"))
+	}
+	// Only the user's own session or JWT may approve, whatever the route
+	// permission: an API key or OAuth2 token must not grant new tokens.
+	if err := new(permission.IsFirstParty).Check(c); err != nil {
+		return err
+	}
+
+	req, err := oc.Service.ValidateAuthorization(&input)
+	if err != nil {
+		return oc.authorizeError(c, req, err)
+	}
+	if !input.Approve {
+		return c.JSON(dto.AuthorizationRedirectDTO{RedirectTo: req.ErrorRedirect(service.AccessDenied())})
+	}
+
+	redirectTo, err := oc.Service.Authorize(req, user)
+	if err != nil {
+		return exceptions.NewInternal(err)
+	}
+	return c.JSON(dto.AuthorizationRedirectDTO{RedirectTo: redirectTo})
+}
+
+// authorizeError answers an invalid authorization request. Once the redirect
+// URI is trusted the error also comes as redirect_to, for the front-end to
+// send it to the client.
+func (oc *Controller) authorizeError(c *fiber.Ctx, req *service.AuthorizationRequest, err error) error {
+	var oauthErr *service.OAuthError
+	if !errors.As(err, &oauthErr) {
+		return exceptions.NewInternal(err)
+	}
+	body := fiber.Map{"error": oauthErr.Code, "error_description": oauthErr.Description}
+	if req != nil {
+		body["redirect_to"] = req.ErrorRedirect(oauthErr)
+	}
+	return c.Status(oauthErr.Status).JSON(body)
+}
+
+// Token is the token endpoint (RFC 6749, section 3.2).
+func (oc *Controller) Token(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	c.Set(fiber.HeaderPragma, "no-cache")
+
+	var input dto.TokenRequestDTO
+	if err := c.BodyParser(&input); err != nil {
+		return exceptions.NewBadRequest("invalid_payload", err)
+	}
+	client, err := oc.authenticateClient(c, input.ClientID, input.ClientSecret)
+	if err != nil {
+		return oc.oauthError(c, err)
+	}
+
+	response, err := oc.Service.Token(client, &input)
+	if err != nil {
+		return oc.oauthError(c, err)
+	}
+	return c.JSON(response)
+}
+
+// Introspect is the introspection endpoint (RFC 7662).
+func (oc *Controller) Introspect(c *fiber.Ctx) error {
+	var input dto.TokenDTO
+	if err := c.BodyParser(&input); err != nil {
+		return exceptions.NewBadRequest("invalid_payload", err)
+	}
+	client, err := oc.authenticateClient(c, input.ClientID, input.ClientSecret)
+	if err != nil {
+		return oc.oauthError(c, err)
+	}
+
+	response, err := oc.Service.Introspect(client, input.Token)
+	if err != nil {
+		return oc.oauthError(c, err)
+	}
+	return c.JSON(response)
+}
+
+// Revoke is the revocation endpoint (RFC 7009). Revoking an unknown token
+// succeeds, so the answer says nothing about it.
+func (oc *Controller) Revoke(c *fiber.Ctx) error {
+	var input dto.TokenDTO
+	if err := c.BodyParser(&input); err != nil {
+		return exceptions.NewBadRequest("invalid_payload", err)
+	}
+	client, err := oc.authenticateClient(c, input.ClientID, input.ClientSecret)
+	if err != nil {
+		return oc.oauthError(c, err)
+	}
+
+	if err := oc.Service.Revoke(client, input.Token); err != nil {
+		return oc.oauthError(c, err)
+	}
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// authenticateClient reads the client credentials from HTTP Basic, whose
+// parts are form-encoded (RFC 6749, section 2.3.1), or else from the body.
+func (oc *Controller) authenticateClient(c *fiber.Ctx, clientID string, secret string) (*model.Client, error) {
+	if header := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(header, "Basic ") {
+		id, password, ok := parseBasicCredentials(header[6:])
+		if !ok {
+			return nil, &service.OAuthError{Status: fiber.StatusUnauthorized, Code: "invalid_client", Description: "malformed Basic credentials"}
+		}
+		clientID, secret = id, password
+	}
+	return oc.Service.AuthenticateClient(clientID, secret)
+}
+
+func parseBasicCredentials(encoded string) (clientID string, secret string, ok bool) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", "", false
+	}
+	id, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return "", "", false
+	}
+	if clientID, err = url.QueryUnescape(id); err != nil {
+		return "", "", false
+	}
+	if secret, err = url.QueryUnescape(password); err != nil {
+		return "", "", false
+	}
+	return clientID, secret, true
+}
+
+// oauthError answers err in the format of RFC 6749, section 5.2.
+func (oc *Controller) oauthError(c *fiber.Ctx, err error) error {
+	var oauthErr *service.OAuthError
+	if !errors.As(err, &oauthErr) {
+		return exceptions.NewInternal(err)
+	}
+	if oauthErr.Status == fiber.StatusUnauthorized && strings.HasPrefix(c.Get(fiber.HeaderAuthorization), "Basic ") {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth2"`)
+	}
+	return c.Status(oauthErr.Status).JSON(fiber.Map{
+		"error":             oauthErr.Code,
+		"error_description": oauthErr.Description,
+	})
+}
@@ -0,0 +1,558 @@
+package controller_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"grf/core/management"
+	"grf/core/middleware"
+	"grf/core/tests"
+	"grf/domain/auth"
+	authdto "grf/domain/auth/dto"
+	"grf/domain/oauth2"
+	"grf/domain/oauth2/dto"
+	"grf/domain/oauth2/model"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const redirectURI = "https://partner.example/callback"
+
+func TestClientController(t *testing.T) {
+	clearOAuth2Tables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+	adminToken := loginAs(t, "admin", "admin123")
+	userToken := loginAs(t, "user", "user123")
+
+	t.Run("POST /oauth2/clients (Admin 201 mostra o segredo)", func(t *testing.T) {
+		client := createClient(t, adminToken, dto.ClientCreateDTO{
+			Name: "parceiro", UserID: fixtures.NormalUser.ID, ClientType: model.ClientConfidential,
+			RedirectURIs: []string{redirectURI}, GrantTypes: []string{model.GrantAuthorizationCode}, Scopes: []string{"users:read"},
+		})
+		if client.ClientID == "" || client.ClientSecret == "" {
+			t.Fatalf("Esperado client_id e client_secret, obteve %+v", client)
+		}
+
+		var stored model.Client
+		testApp.DB.First(&stored, client.ID)
+		if stored.HashedSecret == "" || strings.Contains(stored.HashedSecret, client.ClientSecret) {
+			t.Error("Esperado apenas o hash do segredo armazenado")
+		}
+
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: fmt.Sprintf("/v1/oauth2/clients/%d", client.ID), Token: adminToken,
+		})
+		if resp.StatusCode != http.StatusOK || strings.Contains(body, "client_secret") {
+			t.Errorf("Esperado 200 sem o segredo, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("POST /oauth2/clients (Cliente público sem segredo)", func(t *testing.T) {
+		client := createClient(t, adminToken, dto.ClientCreateDTO{
+			Name: "spa", UserID: fixtures.NormalUser.ID, ClientType: model.ClientPublic,
+			RedirectURIs: []string{redirectURI}, GrantTypes: []string{model.GrantAuthorizationCode},
+		})
+		if client.ClientSecret != "" {
+			t.Errorf("Esperado cliente público sem segredo, obteve %+v", client)
+		}
+	})
+
+	invalid := []struct {
+		name   string
+		input  dto.ClientCreateDTO
+		status int
+	}{
+		{"Público com client_credentials", dto.ClientCreateDTO{
+			Name: "x", UserID: fixtures.NormalUser.ID, ClientType: model.ClientPublic, GrantTypes: []string{model.GrantClientCredentials},
+		}, http.StatusBadRequest},
+		{"Código sem redirect_uri", dto.ClientCreateDTO{
+			Name: "x", UserID: fixtures.NormalUser.ID, ClientType: model.ClientConfidential, GrantTypes: []string{model.GrantAuthorizationCode},
+		}, http.StatusBadRequest},
+		{"Usuário inexistente", dto.ClientCreateDTO{
+			Name: "x", UserID: 9999, ClientType: model.ClientConfidential, GrantTypes: []string{model.GrantClientCredentials},
+		}, http.StatusBadRequest},
+		{"redirect_uri com fragmento", dto.ClientCreateDTO{
+			Name: "x", UserID: fixtures.NormalUser.ID, ClientType: model.ClientConfidential,
+			RedirectURIs: []string{redirectURI + "#frag"}, GrantTypes: []string{model.GrantAuthorizationCode},
+		}, http.StatusUnprocessableEntity},
+		{"Grant desconhecido", dto.ClientCreateDTO{
+			Name: "x", UserID: fixtures.NormalUser.ID, ClientType: model.ClientConfidential, GrantTypes: []string{"password"},
+		}, http.StatusUnprocessableEntity},
+	}
+	for _, tc := range invalid {
+		t.Run("POST /oauth2/clients ("+tc.name+")", func(t *testing.T) {
+			resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+				Method: http.MethodPost, URL: "/v1/oauth2/clients", Token: adminToken, Body: tc.input,
+			})
+			if resp.StatusCode != tc.status {
+				t.Errorf("Esperado %d, obteve %d: %s", tc.status, resp.StatusCode, body)
+			}
+		})
+	}
+
+	t.Run("POST /oauth2/clients (Usuário comum 403)", func(t *testing.T) {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: "/v1/oauth2/clients", Token: userToken, Body: invalid[0].input,
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Esperado 403, obteve %d", resp.StatusCode)
+		}
+	})
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	clearOAuth2Tables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+	adminToken := loginAs(t, "admin", "admin123")
+	userToken := loginAs(t, "user", "user123")
+
+	scoped := func(c *fiber.Ctx) error { return c.SendStatus(http.StatusNoContent) }
+	testApp.FiberApp.Get("/v1/test-oauth2-read", middleware.Check(testApp.HasScope("users:read")), scoped)
+	testApp.FiberApp.Get("/v1/test-oauth2-write", middleware.Check(testApp.HasScope("users:write")), scoped)
+
+	client := createClient(t, adminToken, dto.ClientCreateDTO{
+		Name: "parceiro", UserID: fixtures.AdminUser.ID, ClientType: model.ClientConfidential,
+		RedirectURIs: []string{redirectURI},
+		GrantTypes:   []string{model.GrantAuthorizationCode, model.GrantRefreshToken},
+		Scopes:       []string{"users:read", "users:write"},
+	})
+	credentials := basicAuth(client.ClientID, client.ClientSecret)
+	verifier, challenge := pkcePair("verificador-")
+
+	authorizeQuery := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {client.ClientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {"users:read"},
+		"state":                 {"xyz"},
+		"code_challenge":        {challenge},
+		"code_challenge_method": {"S256"},
+	}
+	authorize := func(t *testing.T, query url.Values, approve bool) (*http.Response, dto.AuthorizationRedirectDTO) {
+		input := dto.AuthorizeDTO{
+			ResponseType: query.Get("response_type"), ClientID: query.Get("client_id"), RedirectURI: query.Get("redirect_uri"),
+			Scope: query.Get("scope"), State: query.Get("state"),
+			CodeChallenge: query.Get("code_challenge"), CodeChallengeMethod: query.Get("code_challenge_method"),
+			Approve: approve,
+		}
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: "/v1/oauth2/authorize", Token: userToken, Body: input,
+		})
+		var redirect dto.AuthorizationRedirectDTO
+		json.Unmarshal([]byte(body), &redirect)
+		return resp, redirect
+	}
+	obtainCode := func(t *testing.T) string {
+		resp, redirect := authorize(t, authorizeQuery, true)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d", resp.StatusCode)
+		}
+		location, _ := url.Parse(redirect.RedirectTo)
+		return location.Query().Get("code")
+	}
+
+	t.Run("GET /oauth2/authorize descreve o pedido", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/oauth2/authorize?" + authorizeQuery.Encode(), Token: userToken,
+		})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+		var info dto.AuthorizationRequestDTO
+		json.Unmarshal([]byte(body), &info)
+		if info.ClientName != "parceiro" || info.RedirectURI != redirectURI || strings.Join(info.Scopes, " ") != "users:read" {
+			t.Errorf("Pedido inesperado: %+v", info)
+		}
+
+		resp, _ = tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/oauth2/authorize?" + authorizeQuery.Encode(),
+		})
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Sem login: Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("GET /oauth2/authorize rejeita redirect_uri não registrada sem redirecionar", func(t *testing.T) {
+		query := url.Values{}
+		for key, values := range authorizeQuery {
+			query[key] = values
+		}
+		query.Set("redirect_uri", "https://evil.example/callback")
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/oauth2/authorize?" + query.Encode(), Token: userToken,
+		})
+		if resp.StatusCode != http.StatusBadRequest || strings.Contains(body, "redirect_to") {
+			t.Errorf("Esperado 400 sem redirect_to, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("POST /oauth2/authorize exige PKCE", func(t *testing.T) {
+		query := url.Values{}
+		for key, values := range authorizeQuery {
+			query[key] = values
+		}
+		query.Del("code_challenge")
+		resp, redirect := authorize(t, query, true)
+		if resp.StatusCode != http.StatusBadRequest || !strings.Contains(redirect.RedirectTo, "error=invalid_request") {
+			t.Errorf("Esperado 400 com redirect de erro, obteve %d: %+v", resp.StatusCode, redirect)
+		}
+	})
+
+	t.Run("POST /oauth2/authorize negado redireciona com access_denied", func(t *testing.T) {
+		resp, redirect := authorize(t, authorizeQuery, false)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d", resp.StatusCode)
+		}
+		location, _ := url.Parse(redirect.RedirectTo)
+		if location.Query().Get("error") != "access_denied" || location.Query().Get("state") != "xyz" {
+			t.Errorf("Redirect inesperado: %s", redirect.RedirectTo)
+		}
+	})
+
+	var tokens dto.TokenResponseDTO
+	t.Run("Troca o código com PKCE", func(t *testing.T) {
+		code := obtainCode(t)
+		if !strings.HasPrefix(code, model.CodePrefix) {
+			t.Fatalf("Código inesperado: %q", code)
+		}
+		form := url.Values{"grant_type": {"authorization_code"}, "code": {code}, "redirect_uri": {redirectURI}}
+
+		form.Set("code_verifier", strings.Repeat("x", 43))
+		resp, body := postForm(t, "/v1/oauth2/token", form, credentials)
+		if resp.StatusCode != http.StatusBadRequest || !strings.Contains(body, "invalid_grant") {
+			t.Errorf("Verificador errado: Esperado 400 invalid_grant, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		// Um código falho é consumido mesmo assim.
+		code = obtainCode(t)
+		form.Set("code", code)
+		form.Set("code_verifier", verifier)
+		resp, body = postForm(t, "/v1/oauth2/token", form, credentials)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+		if resp.Header.Get(fiber.HeaderCacheControl) != "no-store" {
+			t.Error("Esperado Cache-Control: no-store")
+		}
+		json.Unmarshal([]byte(body), &tokens)
+		if !strings.HasPrefix(tokens.AccessToken, model.AccessTokenPrefix) || !strings.HasPrefix(tokens.RefreshToken, model.RefreshTokenPrefix) ||
+			tokens.TokenType != "Bearer" || tokens.Scope != "users:read" {
+			t.Errorf("Tokens inesperados: %+v", tokens)
+		}
+
+		resp, body = postForm(t, "/v1/oauth2/token", form, credentials)
+		if resp.StatusCode != http.StatusBadRequest || !strings.Contains(body, "invalid_grant") {
+			t.Errorf("Código reutilizado: Esperado 400 invalid_grant, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("Código de outro cliente não é consumido", func(t *testing.T) {
+		intruder := createClient(t, adminToken, dto.ClientCreateDTO{
+			Name: "intruso", UserID: fixtures.AdminUser.ID, ClientType: model.ClientConfidential,
+			RedirectURIs: []string{redirectURI}, GrantTypes: []string{model.GrantAuthorizationCode},
+		})
+		form := url.Values{"grant_type": {"authorization_code"}, "code": {obtainCode(t)}, "redirect_uri": {redirectURI}, "code_verifier": {verifier}}
+		resp, body := postForm(t, "/v1/oauth2/token", form, basicAuth(intruder.ClientID, intruder.ClientSecret))
+		if resp.StatusCode != http.StatusBadRequest || !strings.Contains(body, "invalid_grant") {
+			t.Errorf("Outro cliente: Esperado 400 invalid_grant, obteve %d: %s", resp.StatusCode, body)
+		}
+		if resp, body := postForm(t, "/v1/oauth2/token", form, credentials); resp.StatusCode != http.StatusOK {
+			t.Errorf("Cliente do código: Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("redirect_uri informada na autorização é exigida na troca", func(t *testing.T) {
+		form := url.Values{"grant_type": {"authorization_code"}, "code": {obtainCode(t)}, "code_verifier": {verifier}}
+		resp, body := postForm(t, "/v1/oauth2/token", form, credentials)
+		if resp.StatusCode != http.StatusBadRequest || !strings.Contains(body, "invalid_grant") {
+			t.Errorf("Sem redirect_uri: Esperado 400 invalid_grant, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		// Sem redirect_uri na autorização vale a única registrada.
+		query := url.Values{}
+		for key, values := range authorizeQuery {
+			query[key] = values
+		}
+		query.Del("redirect_uri")
+		_, redirect := authorize(t, query, true)
+		location, _ := url.Parse(redirect.RedirectTo)
+		form.Set("code", location.Query().Get("code"))
+		if resp, body := postForm(t, "/v1/oauth2/token", form, credentials); resp.StatusCode != http.StatusOK {
+			t.Errorf("redirect_uri omitida nas duas: Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("Segredo errado 401 invalid_client", func(t *testing.T) {
+		resp, body := postForm(t, "/v1/oauth2/token", url.Values{"grant_type": {"refresh_token"}}, basicAuth(client.ClientID, "errado"))
+		if resp.StatusCode != http.StatusUnauthorized || !strings.Contains(body, "invalid_client") || resp.Header.Get(fiber.HeaderWWWAuthenticate) == "" {
+			t.Errorf("Esperado 401 invalid_client com WWW-Authenticate, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("Token autentica como o usuário e respeita os escopos", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: "/v1/auth/me", Token: tokens.AccessToken})
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"username":"user"`) {
+			t.Errorf("Esperado 200 como user, obteve %d: %s", resp.StatusCode, body)
+		}
+		if resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: "/v1/test-oauth2-read", Token: tokens.AccessToken}); resp.StatusCode != http.StatusNoContent {
+			t.Errorf("Escopo concedido: Esperado 204, obteve %d", resp.StatusCode)
+		}
+		if resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: "/v1/test-oauth2-write", Token: tokens.AccessToken}); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Escopo ausente: Esperado 403, obteve %d", resp.StatusCode)
+		}
+		if resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: "/v1/auth/me", Token: model.AccessTokenPrefix + "inexistente"}); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Token inválido: Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Token OAuth2 não aprova autorizações", func(t *testing.T) {
+		resp, body := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodGet, URL: "/v1/oauth2/authorize?" + authorizeQuery.Encode(), Token: tokens.AccessToken,
+		})
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("GET: Esperado 403, obteve %d: %s", resp.StatusCode, body)
+		}
+		resp, body = tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+			Method: http.MethodPost, URL: "/v1/oauth2/authorize", Token: tokens.AccessToken,
+			Body: dto.AuthorizeDTO{
+				ResponseType: "code", ClientID: client.ClientID, RedirectURI: redirectURI,
+				CodeChallenge: challenge, CodeChallengeMethod: "S256", Approve: true,
+			},
+		})
+		if resp.StatusCode != http.StatusForbidden || strings.Contains(body, "code=") {
+			t.Errorf("POST: Esperado 403 sem código, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("Introspecção", func(t *testing.T) {
+		resp, body := postForm(t, "/v1/oauth2/introspect", url.Values{"token": {tokens.AccessToken}}, credentials)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+		var info dto.IntrospectionResponseDTO
+		json.Unmarshal([]byte(body), &info)
+		if !info.Active || info.Username != "user" || info.ClientID != client.ClientID || info.Scope != "users:read" {
+			t.Errorf("Introspecção inesperada: %+v", info)
+		}
+
+		resp, body = postForm(t, "/v1/oauth2/introspect", url.Values{"token": {"desconhecido"}}, credentials)
+		if resp.StatusCode != http.StatusOK || body != `{"active":false}` {
+			t.Errorf("Token desconhecido: Esperado inativo, obteve %d: %s", resp.StatusCode, body)
+		}
+		if resp, _ := postForm(t, "/v1/oauth2/introspect", url.Values{"token": {tokens.AccessToken}}, nil); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Sem cliente: Esperado 401, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("Refresh rotaciona e restringe o escopo", func(t *testing.T) {
+		form := url.Values{"grant_type": {"refresh_token"}, "refresh_token": {tokens.RefreshToken}, "scope": {"users:write"}}
+		resp, body := postForm(t, "/v1/oauth2/token", form, credentials)
+		if resp.StatusCode != http.StatusBadRequest || !strings.Contains(body, "invalid_scope") {
+			t.Errorf("Escopo ampliado: Esperado 400 invalid_scope, obteve %d: %s", resp.StatusCode, body)
+		}
+
+		form.Del("scope")
+		resp, body = postForm(t, "/v1/oauth2/token", form, credentials)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+		var rotated dto.TokenResponseDTO
+		json.Unmarshal([]byte(body), &rotated)
+		if rotated.RefreshToken == "" || rotated.RefreshToken == tokens.RefreshToken || rotated.Scope != "users:read" {
+			t.Errorf("Rotação inesperada: %+v", rotated)
+		}
+
+		if resp, _ := postForm(t, "/v1/oauth2/token", form, credentials); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Refresh antigo: Esperado 400, obteve %d", resp.StatusCode)
+		}
+		if resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: "/v1/auth/me", Token: tokens.AccessToken}); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Access do par antigo: Esperado 401, obteve %d", resp.StatusCode)
+		}
+		tokens = rotated
+	})
+
+	t.Run("Revogação", func(t *testing.T) {
+		other := createClient(t, adminToken, dto.ClientCreateDTO{
+			Name: "outro", UserID: fixtures.AdminUser.ID, ClientType: model.ClientConfidential,
+			GrantTypes: []string{model.GrantClientCredentials},
+		})
+		resp, _ := postForm(t, "/v1/oauth2/revoke", url.Values{"token": {tokens.RefreshToken}}, basicAuth(other.ClientID, other.ClientSecret))
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Outro cliente: Esperado 400, obteve %d", resp.StatusCode)
+		}
+
+		if resp, _ := postForm(t, "/v1/oauth2/revoke", url.Values{"token": {"desconhecido"}}, credentials); resp.StatusCode != http.StatusOK {
+			t.Errorf("Token desconhecido: Esperado 200, obteve %d", resp.StatusCode)
+		}
+		if resp, _ := postForm(t, "/v1/oauth2/revoke", url.Values{"token": {tokens.RefreshToken}}, credentials); resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d", resp.StatusCode)
+		}
+		if resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: "/v1/auth/me", Token: tokens.AccessToken}); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Access revogado junto: Esperado 401, obteve %d", resp.StatusCode)
+		}
+		_, body := postForm(t, "/v1/oauth2/introspect", url.Values{"token": {tokens.RefreshToken}}, credentials)
+		if body != `{"active":false}` {
+			t.Errorf("Esperado refresh inativo, obteve %s", body)
+		}
+	})
+}
+
+func TestClientCredentials(t *testing.T) {
+	clearOAuth2Tables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+	adminToken := loginAs(t, "admin", "admin123")
+
+	client := createClient(t, adminToken, dto.ClientCreateDTO{
+		Name: "serviço", UserID: fixtures.NormalUser.ID, ClientType: model.ClientConfidential,
+		GrantTypes: []string{model.GrantClientCredentials}, Scopes: []string{"users:read"},
+	})
+
+	t.Run("Emite token sem refresh, agindo como o dono", func(t *testing.T) {
+		// Credenciais no corpo, em vez de HTTP Basic.
+		form := url.Values{"grant_type": {"client_credentials"}, "client_id": {client.ClientID}, "client_secret": {client.ClientSecret}}
+		resp, body := postForm(t, "/v1/oauth2/token", form, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Esperado 200, obteve %d: %s", resp.StatusCode, body)
+		}
+		var tokens dto.TokenResponseDTO
+		json.Unmarshal([]byte(body), &tokens)
+		if tokens.RefreshToken != "" || tokens.Scope != "users:read" {
+			t.Errorf("Tokens inesperados: %+v", tokens)
+		}
+
+		resp, body = tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: "/v1/auth/me", Token: tokens.AccessToken})
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, `"username":"user"`) {
+			t.Errorf("Esperado 200 como o dono, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("Grant não permitido 400 unauthorized_client", func(t *testing.T) {
+		form := url.Values{"grant_type": {"authorization_code"}, "code": {"x"}, "code_verifier": {"y"}}
+		resp, body := postForm(t, "/v1/oauth2/token", form, basicAuth(client.ClientID, client.ClientSecret))
+		if resp.StatusCode != http.StatusBadRequest || !strings.Contains(body, "unauthorized_client") {
+			t.Errorf("Esperado 400 unauthorized_client, obteve %d: %s", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("Dono inativo invalida os tokens", func(t *testing.T) {
+		form := url.Values{"grant_type": {"client_credentials"}}
+		_, body := postForm(t, "/v1/oauth2/token", form, basicAuth(client.ClientID, client.ClientSecret))
+		var tokens dto.TokenResponseDTO
+		json.Unmarshal([]byte(body), &tokens)
+
+		testApp.DB.Model(fixtures.NormalUser).Update("is_active", false)
+		defer testApp.DB.Model(fixtures.NormalUser).Update("is_active", true)
+
+		if resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: "/v1/auth/me", Token: tokens.AccessToken}); resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Esperado 401, obteve %d", resp.StatusCode)
+		}
+		if resp, _ := postForm(t, "/v1/oauth2/token", form, basicAuth(client.ClientID, client.ClientSecret)); resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("Novo token: Esperado 400, obteve %d", resp.StatusCode)
+		}
+	})
+
+	t.Run("cleartokens remove códigos e tokens expirados", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		expired := &model.Token{ClientID: client.ID, UserID: fixtures.NormalUser.ID, ExpiresAt: past}
+		expired.GenerateTokens(false)
+		testApp.DB.Create(expired)
+		code := &model.AuthorizationCode{ClientID: client.ID, UserID: fixtures.NormalUser.ID, RedirectURI: redirectURI, ExpiresAt: past}
+		code.GenerateCode()
+		testApp.DB.Create(code)
+
+		out := &bytes.Buffer{}
+		cli := management.New(*testApp.Config, auth.GetModule(), oauth2.GetModule())
+		cli.Context.SetApp(testApp)
+		cli.Context.Out = out
+		cli.Context.Err = out
+		if err := cli.Run([]string{"cleartokens"}); err != nil || !strings.Contains(out.String(), "1 expired codes and 1 expired tokens deleted.") {
+			t.Errorf("Esperado 1 código e 1 token removidos, obteve %v (%s)", err, out)
+		}
+	})
+}
+
+func TestUserRevocation(t *testing.T) {
+	clearOAuth2Tables(testApp.DB)
+	fixtures, err := createTestFixtures(testApp.DB)
+	if err != nil {
+		t.Fatalf("Falha ao criar fixtures: %v", err)
+	}
+	adminToken := loginAs(t, "admin", "admin123")
+
+	client := createClient(t, adminToken, dto.ClientCreateDTO{
+		Name: "app", UserID: fixtures.AdminUser.ID, ClientType: model.ClientConfidential,
+		RedirectURIs: []string{redirectURI}, GrantTypes: []string{model.GrantAuthorizationCode},
+	})
+	issue := func(t *testing.T) string {
+		token := &model.Token{ClientID: client.ID, UserID: fixtures.NormalUser.ID, ExpiresAt: time.Now().Add(time.Hour)}
+		token.GenerateTokens(false)
+		testApp.DB.Create(token)
+		return token.AccessToken
+	}
+	meStatus := func(t *testing.T, token string) int {
+		resp, _ := tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{Method: http.MethodGet, URL: "/v1/auth/me", Token: token})
+		return resp.StatusCode
+	}
+
+	cases := []struct {
+		name   string
+		revoke func(t *testing.T)
+	}{
+		{"logout-all", func(t *testing.T) {
+			tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+				Method: http.MethodPost, URL: "/v1/auth/logout-all", Token: loginAs(t, "user", "user123"),
+			})
+		}},
+		{"change-password", func(t *testing.T) {
+			tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+				Method: http.MethodPost, URL: "/v1/auth/change-password", Token: loginAs(t, "user", "user123"),
+				Body: authdto.ChangePasswordDTO{OldPassword: "user123", NewPassword: "user1234", RepeatNewPassword: "user1234"},
+			})
+			testApp.DB.Model(fixtures.NormalUser).Update("password", fixtures.NormalUser.Password)
+		}},
+		{"set-password", func(t *testing.T) {
+			tests.MakeRequest(t, testApp.FiberApp, tests.RequestOptions{
+				Method: http.MethodPost, URL: fmt.Sprintf("/v1/users/%d/set-password", fixtures.NormalUser.ID), Token: adminToken,
+				Body: authdto.SetPasswordDTO{Password: "user1234"},
+			})
+			testApp.DB.Model(fixtures.NormalUser).Update("password", fixtures.NormalUser.Password)
+		}},
+		{"changepassword", func(t *testing.T) {
+			cli := management.New(*testApp.Config, auth.GetModule(), oauth2.GetModule())
+			cli.Context.SetApp(testApp)
+			cli.Context.In = strings.NewReader("user1234\nuser1234\n")
+			cli.Context.Out = &bytes.Buffer{}
+			cli.Context.Err = cli.Context.Out
+			if err := cli.Run([]string{"changepassword", "user"}); err != nil {
+				t.Fatalf("Falha ao trocar a senha: %v", err)
+			}
+			testApp.DB.Model(fixtures.NormalUser).Update("password", fixtures.NormalUser.Password)
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name+" revoga os tokens OAuth2", func(t *testing.T) {
+			token := issue(t)
+			if status := meStatus(t, token); status != http.StatusOK {
+				t.Fatalf("Antes: Esperado 200, obteve %d", status)
+			}
+			tc.revoke(t)
+			if status := meStatus(t, token); status != http.StatusUnauthorized {
+				t.Errorf("Depois: Esperado 401, obteve %d", status)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"grf/core/dto"
+	"grf/domain/oauth2/model"
+	"time"
+)
+
+var _ dto.IPatchDTO = (*ClientPatchDTO)(nil)
+
+type ClientCreateDTO struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	UserID       uint64   `json:"user_id" validate:"required"`
+	ClientType   string   `json:"client_type" validate:"required,oneof=confidential public"`
+	RedirectURIs []string `json:"redirect_uris" validate:"max=20,dive,required,max=500,url,excludesall=# "`
+	GrantTypes   []string `json:"grant_types" validate:"required,min=1,dive,oneof=authorization_code client_credentials refresh_token"`
+	Scopes       []string `json:"scopes" validate:"max=50,dive,required,max=100,excludesall= "`
+}
+
+// ClientUpdateDTO and ClientPatchDTO can't change the type, grants or owner
+// of a client. Changing its scopes doesn't affect tokens already issued.
+type ClientUpdateDTO struct {
+	Name         string   `json:"name" validate:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris" validate:"max=20,dive,required,max=500,url,excludesall=# "`
+	Scopes       []string `json:"scopes" validate:"max=50,dive,required,max=100,excludesall= "`
+}
+
+type ClientPatchDTO struct {
+	Name         *string   `json:"name,omitempty" validate:"omitempty,max=100"`
+	RedirectURIs *[]string `json:"redirect_uris,omitempty" validate:"omitempty,max=20,dive,required,max=500,url,excludesall=# "`
+	Scopes       *[]string `json:"scopes,omitempty" validate:"omitempty,max=50,dive,required,max=100,excludesall= "`
+}
+
+func (dto *ClientPatchDTO) IsEmpty() bool {
+	return dto.Name == nil && dto.RedirectURIs == nil && dto.Scopes == nil
+}
+
+func (dto *ClientPatchDTO) ToPatchMap() map[string]interface{} {
+	updates := make(map[string]interface{})
+	if dto.Name != nil {
+		updates["name"] = *dto.Name
+	}
+	if dto.RedirectURIs != nil {
+		updates["redirect_uris"] = model.JoinScopes(*dto.RedirectURIs)
+	}
+	if dto.Scopes != nil {
+		updates["scopes"] = model.JoinScopes(*dto.Scopes)
+	}
+	return updates
+}
+
+type ClientResponseDTO struct {
+	ID           uint64    `json:"id"`
+	Name         string    `json:"name"`
+	UserID       uint64    `json:"user_id"`
+	ClientID     string    `json:"client_id"`
+	ClientType   string    `json:"client_type"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	GrantTypes   []string  `json:"grant_types"`
+	Scopes       []string  `json:"scopes"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	// ClientSecret is only returned by the request that created a
+	// confidential client.
+	ClientSecret string `json:"client_secret,omitempty"`
+}
@@ -0,0 +1,74 @@
+package dto
+
+// AuthorizeDTO is an authorization request (RFC 6749, section 4.1.1), read
+// from the query by GET and from the body by POST, where Approve carries the
+// user's decision.
+type AuthorizeDTO struct {
+	ResponseType        string `query:"response_type" json:"response_type"`
+	ClientID            string `query:"client_id" json:"client_id"`
+	RedirectURI         string `query:"redirect_uri" json:"redirect_uri"`
+	Scope               string `query:"scope" json:"scope"`
+	State               string `query:"state" json:"state"`
+	CodeChallenge       string `query:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `query:"code_challenge_method" json:"code_challenge_method"`
+
+	Approve bool `query:"-" json:"approve"`
+}
+
+// AuthorizationRequestDTO describes a valid authorization request, for the
+// front-end to ask the user's consent.
+type AuthorizationRequestDTO struct {
+	ClientID    string   `json:"client_id"`
+	ClientName  string   `json:"client_name"`
+	RedirectURI string   `json:"redirect_uri"`
+	Scopes      []string `json:"scopes"`
+	State       string   `json:"state,omitempty"`
+}
+
+// AuthorizationRedirectDTO holds where the front-end sends the user after
+// the decision, with the code or the error in the query.
+type AuthorizationRedirectDTO struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// TokenRequestDTO is a token request, form-encoded as RFC 6749 requires.
+// Clients may authenticate with HTTP Basic instead of ClientID and
+// ClientSecret.
+type TokenRequestDTO struct {
+	GrantType    string `form:"grant_type" json:"grant_type"`
+	Code         string `form:"code" json:"code"`
+	RedirectURI  string `form:"redirect_uri" json:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier" json:"code_verifier"`
+	RefreshToken string `form:"refresh_token" json:"refresh_token"`
+	Scope        string `form:"scope" json:"scope"`
+	ClientID     string `form:"client_id" json:"client_id"`
+	ClientSecret string `form:"client_secret" json:"client_secret"`
+}
+
+type TokenResponseDTO struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+// TokenDTO is an introspection (RFC 7662) or revocation (RFC 7009) request.
+type TokenDTO struct {
+	Token         string `form:"token" json:"token"`
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint"`
+	ClientID      string `form:"client_id" json:"client_id"`
+	ClientSecret  string `form:"client_secret" json:"client_secret"`
+}
+
+// IntrospectionResponseDTO only carries Active for tokens that aren't.
+type IntrospectionResponseDTO struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+}
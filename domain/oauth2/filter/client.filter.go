@@ -0,0 +1,17 @@
+package filter
+
+import (
+	"grf/core/filterset"
+)
+
+var _ filterset.IFilterSet = (*ClientFilterSet)(nil)
+
+type ClientFilterFields struct {
+	ID         uint64 `filter:"id,lookups=exact|in"`
+	UserID     uint64 `filter:"user_id,lookups=exact|in"`
+	Name       string `filter:"name,lookups=exact|icontains"`
+	ClientID   string `filter:"client_id"`
+	ClientType string `filter:"client_type"`
+}
+
+type ClientFilterSet = filterset.FilterSet[ClientFilterFields]
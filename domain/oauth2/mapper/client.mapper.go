@@ -0,0 +1,42 @@
+package mapper
+
+import (
+	"grf/domain/oauth2/dto"
+	"grf/domain/oauth2/model"
+)
+
+func MapClientToResponse(client *model.Client) *dto.ClientResponseDTO {
+	return &dto.ClientResponseDTO{
+		ID:           client.ID,
+		Name:         client.Name,
+		UserID:       client.UserID,
+		ClientID:     client.ClientID,
+		ClientType:   client.ClientType,
+		RedirectURIs: client.RedirectURIList(),
+		GrantTypes:   client.GrantTypeList(),
+		Scopes:       client.ScopeList(),
+		CreatedAt:    client.CreatedAt,
+		UpdatedAt:    client.UpdatedAt,
+		ClientSecret: client.Secret,
+	}
+}
+
+func MapCreateToClient(dto *dto.ClientCreateDTO) *model.Client {
+	client := &model.Client{
+		Name:       dto.Name,
+		UserID:     dto.UserID,
+		ClientType: dto.ClientType,
+	}
+	client.SetRedirectURIs(dto.RedirectURIs)
+	client.SetGrantTypes(dto.GrantTypes)
+	client.SetScopes(dto.Scopes)
+	client.GenerateCredentials()
+	return client
+}
+
+func MapUpdateToClient(dto *dto.ClientUpdateDTO, client *model.Client) *model.Client {
+	client.Name = dto.Name
+	client.SetRedirectURIs(dto.RedirectURIs)
+	client.SetScopes(dto.Scopes)
+	return client
+}
@@ -0,0 +1,58 @@
+CREATE TABLE IF NOT EXISTS `oauth2_client` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `name` varchar(100) NOT NULL,
+    `user_id` bigint unsigned NOT NULL,
+    `client_id` varchar(64) NOT NULL,
+    `hashed_secret` varchar(64) NOT NULL,
+    `client_type` varchar(20) NOT NULL,
+    `redirect_uris` varchar(2000) NOT NULL,
+    `grant_types` varchar(200) NOT NULL,
+    `scopes` varchar(1000) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_oauth2_client_user_id` (`user_id`),
+    UNIQUE INDEX `idx_oauth2_client_client_id` (`client_id`),
+    CONSTRAINT `fk_oauth2_client_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `oauth2_authorization_code` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `client_id` bigint unsigned NOT NULL,
+    `user_id` bigint unsigned NOT NULL,
+    `hashed_code` varchar(64) NOT NULL,
+    `redirect_uri` varchar(2000) NOT NULL,
+    `redirect_uri_given` boolean NOT NULL DEFAULT false,
+    `scopes` varchar(1000) NOT NULL,
+    `code_challenge` varchar(128) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_oauth2_authorization_code_client_id` (`client_id`),
+    INDEX `idx_oauth2_authorization_code_user_id` (`user_id`),
+    UNIQUE INDEX `idx_oauth2_authorization_code_hashed_code` (`hashed_code`),
+    INDEX `idx_oauth2_authorization_code_expires_at` (`expires_at`),
+    CONSTRAINT `fk_oauth2_authorization_code_client` FOREIGN KEY (`client_id`) REFERENCES `oauth2_client`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_oauth2_authorization_code_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS `oauth2_token` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `client_id` bigint unsigned NOT NULL,
+    `user_id` bigint unsigned NOT NULL,
+    `hashed_access_token` varchar(64) NOT NULL,
+    `hashed_refresh_token` varchar(64),
+    `scopes` varchar(1000) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    `refresh_expires_at` datetime(3) NULL,
+    `revoked_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_oauth2_token_client_id` (`client_id`),
+    INDEX `idx_oauth2_token_user_id` (`user_id`),
+    UNIQUE INDEX `idx_oauth2_token_hashed_access_token` (`hashed_access_token`),
+    UNIQUE INDEX `idx_oauth2_token_hashed_refresh_token` (`hashed_refresh_token`),
+    INDEX `idx_oauth2_token_expires_at` (`expires_at`),
+    CONSTRAINT `fk_oauth2_token_client` FOREIGN KEY (`client_id`) REFERENCES `oauth2_client`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_oauth2_token_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
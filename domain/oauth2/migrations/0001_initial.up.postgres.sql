@@ -0,0 +1,58 @@
+CREATE TABLE IF NOT EXISTS "oauth2_client" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "name" varchar(100) NOT NULL,
+    "user_id" bigint NOT NULL,
+    "client_id" varchar(64) NOT NULL,
+    "hashed_secret" varchar(64) NOT NULL,
+    "client_type" varchar(20) NOT NULL,
+    "redirect_uris" varchar(2000) NOT NULL,
+    "grant_types" varchar(200) NOT NULL,
+    "scopes" varchar(1000) NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_oauth2_client_user" FOREIGN KEY ("user_id") REFERENCES "auth_user"("id") ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_oauth2_client_client_id" ON "oauth2_client" ("client_id");
+CREATE INDEX IF NOT EXISTS "idx_oauth2_client_user_id" ON "oauth2_client" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "oauth2_authorization_code" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "client_id" bigint NOT NULL,
+    "user_id" bigint NOT NULL,
+    "hashed_code" varchar(64) NOT NULL,
+    "redirect_uri" varchar(2000) NOT NULL,
+    "redirect_uri_given" boolean NOT NULL DEFAULT false,
+    "scopes" varchar(1000) NOT NULL,
+    "code_challenge" varchar(128) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_oauth2_authorization_code_client" FOREIGN KEY ("client_id") REFERENCES "oauth2_client"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_oauth2_authorization_code_user" FOREIGN KEY ("user_id") REFERENCES "auth_user"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_oauth2_authorization_code_client_id" ON "oauth2_authorization_code" ("client_id");
+CREATE INDEX IF NOT EXISTS "idx_oauth2_authorization_code_expires_at" ON "oauth2_authorization_code" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_oauth2_authorization_code_hashed_code" ON "oauth2_authorization_code" ("hashed_code");
+CREATE INDEX IF NOT EXISTS "idx_oauth2_authorization_code_user_id" ON "oauth2_authorization_code" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "oauth2_token" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "client_id" bigint NOT NULL,
+    "user_id" bigint NOT NULL,
+    "hashed_access_token" varchar(64) NOT NULL,
+    "hashed_refresh_token" varchar(64),
+    "scopes" varchar(1000) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "refresh_expires_at" timestamptz,
+    "revoked_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_oauth2_token_client" FOREIGN KEY ("client_id") REFERENCES "oauth2_client"("id") ON DELETE CASCADE,
+    CONSTRAINT "fk_oauth2_token_user" FOREIGN KEY ("user_id") REFERENCES "auth_user"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_oauth2_token_client_id" ON "oauth2_token" ("client_id");
+CREATE INDEX IF NOT EXISTS "idx_oauth2_token_expires_at" ON "oauth2_token" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_oauth2_token_hashed_access_token" ON "oauth2_token" ("hashed_access_token");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_oauth2_token_hashed_refresh_token" ON "oauth2_token" ("hashed_refresh_token");
+CREATE INDEX IF NOT EXISTS "idx_oauth2_token_user_id" ON "oauth2_token" ("user_id");
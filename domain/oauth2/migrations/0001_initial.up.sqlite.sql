@@ -0,0 +1,55 @@
+CREATE TABLE IF NOT EXISTS `oauth2_client` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `name` text NOT NULL,
+    `user_id` integer NOT NULL,
+    `client_id` text NOT NULL,
+    `hashed_secret` text NOT NULL,
+    `client_type` text NOT NULL,
+    `redirect_uris` text NOT NULL,
+    `grant_types` text NOT NULL,
+    `scopes` text NOT NULL,
+    CONSTRAINT `fk_oauth2_client_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_oauth2_client_client_id` ON `oauth2_client`(`client_id`);
+CREATE INDEX IF NOT EXISTS `idx_oauth2_client_user_id` ON `oauth2_client`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `oauth2_authorization_code` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `client_id` integer NOT NULL,
+    `user_id` integer NOT NULL,
+    `hashed_code` text NOT NULL,
+    `redirect_uri` text NOT NULL,
+    `redirect_uri_given` numeric NOT NULL DEFAULT false,
+    `scopes` text NOT NULL,
+    `code_challenge` text NOT NULL,
+    `expires_at` datetime NOT NULL,
+    CONSTRAINT `fk_oauth2_authorization_code_client` FOREIGN KEY (`client_id`) REFERENCES `oauth2_client`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_oauth2_authorization_code_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS `idx_oauth2_authorization_code_client_id` ON `oauth2_authorization_code`(`client_id`);
+CREATE INDEX IF NOT EXISTS `idx_oauth2_authorization_code_expires_at` ON `oauth2_authorization_code`(`expires_at`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_oauth2_authorization_code_hashed_code` ON `oauth2_authorization_code`(`hashed_code`);
+CREATE INDEX IF NOT EXISTS `idx_oauth2_authorization_code_user_id` ON `oauth2_authorization_code`(`user_id`);
+
+CREATE TABLE IF NOT EXISTS `oauth2_token` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `client_id` integer NOT NULL,
+    `user_id` integer NOT NULL,
+    `hashed_access_token` text NOT NULL,
+    `hashed_refresh_token` text,
+    `scopes` text NOT NULL,
+    `expires_at` datetime NOT NULL,
+    `refresh_expires_at` datetime,
+    `revoked_at` datetime,
+    CONSTRAINT `fk_oauth2_token_client` FOREIGN KEY (`client_id`) REFERENCES `oauth2_client`(`id`) ON DELETE CASCADE,
+    CONSTRAINT `fk_oauth2_token_user` FOREIGN KEY (`user_id`) REFERENCES `auth_user`(`id`) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS `idx_oauth2_token_client_id` ON `oauth2_token`(`client_id`);
+CREATE INDEX IF NOT EXISTS `idx_oauth2_token_expires_at` ON `oauth2_token`(`expires_at`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_oauth2_token_hashed_access_token` ON `oauth2_token`(`hashed_access_token`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_oauth2_token_hashed_refresh_token` ON `oauth2_token`(`hashed_refresh_token`);
+CREATE INDEX IF NOT EXISTS `idx_oauth2_token_user_id` ON `oauth2_token`(`user_id`);
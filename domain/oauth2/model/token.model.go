@@ -0,0 +1,115 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	authmodel "grf/domain/auth/model"
+	"strings"
+	"time"
+)
+
+// Prefixes of the opaque values issued, so a bearer token can be told apart
+// from a JWT without looking it up.
+const (
+	AccessTokenPrefix  = "oat_"
+	RefreshTokenPrefix = "ort_"
+	CodePrefix         = "oac_"
+)
+
+// AuthorizationCode is a grant waiting to be exchanged at the token endpoint.
+// It is deleted when exchanged, so it works once.
+type AuthorizationCode struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	ClientID uint64          `gorm:"not null;index"`
+	Client   *Client         `gorm:"constraint:OnDelete:CASCADE;"`
+	UserID   uint64          `gorm:"not null;index"`
+	User     *authmodel.User `gorm:"constraint:OnDelete:CASCADE;"`
+
+	HashedCode  string `gorm:"size:64;not null;uniqueIndex"`
+	RedirectURI string `gorm:"size:2000;not null"`
+	// RedirectURIGiven is set when the authorization request named
+	// RedirectURI, which the token request must then repeat (RFC 6749,
+	// section 4.1.3).
+	RedirectURIGiven bool   `gorm:"not null;default:false"`
+	Scopes           string `gorm:"size:1000;not null"`
+	// CodeChallenge is the S256 PKCE challenge (RFC 7636).
+	CodeChallenge string    `gorm:"size:128;not null"`
+	ExpiresAt     time.Time `gorm:"not null;index"`
+
+	// Code is the code itself, only set on the instance that generated it.
+	Code string `gorm:"-"`
+}
+
+func (AuthorizationCode) TableName() string { return "oauth2_authorization_code" }
+
+func (AuthorizationCode) ModuleName() string { return "oauth2_authorization_code" }
+
+func (a *AuthorizationCode) GenerateCode() string {
+	a.Code = CodePrefix + rand.Text() + rand.Text()
+	a.HashedCode = HashToken(a.Code)
+	return a.Code
+}
+
+func (a *AuthorizationCode) ScopeList() []string { return strings.Fields(a.Scopes) }
+
+// Token is an issued access token and, for grants that allow it, its refresh
+// token. Refreshing revokes the row and issues a new one.
+type Token struct {
+	ID        uint64 `gorm:"primarykey"`
+	CreatedAt time.Time
+
+	ClientID uint64          `gorm:"not null;index"`
+	Client   *Client         `gorm:"constraint:OnDelete:CASCADE;"`
+	UserID   uint64          `gorm:"not null;index"`
+	User     *authmodel.User `gorm:"constraint:OnDelete:CASCADE;"`
+
+	HashedAccessToken  string    `gorm:"size:64;not null;uniqueIndex"`
+	HashedRefreshToken *string   `gorm:"size:64;uniqueIndex"`
+	Scopes             string    `gorm:"size:1000;not null"`
+	ExpiresAt          time.Time `gorm:"not null;index"`
+	RefreshExpiresAt   *time.Time
+	RevokedAt          *time.Time
+
+	// AccessToken and RefreshToken are only set on the instance that
+	// generated them.
+	AccessToken  string `gorm:"-"`
+	RefreshToken string `gorm:"-"`
+}
+
+func (Token) TableName() string { return "oauth2_token" }
+
+func (Token) ModuleName() string { return "oauth2_token" }
+
+// GenerateTokens sets a new access token and, if refresh is true, a new
+// refresh token.
+func (t *Token) GenerateTokens(refresh bool) {
+	t.AccessToken = AccessTokenPrefix + rand.Text() + rand.Text()
+	t.HashedAccessToken = HashToken(t.AccessToken)
+	if refresh {
+		t.RefreshToken = RefreshTokenPrefix + rand.Text() + rand.Text()
+		hashed := HashToken(t.RefreshToken)
+		t.HashedRefreshToken = &hashed
+	}
+}
+
+func (t *Token) ScopeList() []string { return strings.Fields(t.Scopes) }
+
+// AccessActive reports whether the access token can be used at now.
+func (t *Token) AccessActive(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// RefreshActive reports whether the refresh token can be used at now.
+func (t *Token) RefreshActive(now time.Time) bool {
+	return t.RevokedAt == nil && t.RefreshExpiresAt != nil && now.Before(*t.RefreshExpiresAt)
+}
+
+// HashToken is the form tokens, codes and secrets are stored and looked up
+// by. They are random, so a plain SHA-256 is enough.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,57 @@
+package oauth2
+
+import (
+	"embed"
+	"grf/core/management"
+	"grf/core/migrations"
+	"grf/domain/oauth2/command"
+	"grf/domain/oauth2/model"
+	"grf/domain/oauth2/repository"
+	"io/fs"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+func GetModels() []interface{} {
+	return []interface{}{
+		&model.Client{},
+		&model.AuthorizationCode{},
+		&model.Token{},
+	}
+}
+
+func GetMigrations() []*migrations.Migration {
+	files, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		panic(err)
+	}
+	return migrations.MustFromFS("oauth2", files)
+}
+
+func GetCommands() []*management.Command {
+	return []*management.Command{
+		command.NewClearTokensCommand(),
+	}
+}
+
+// RevokeUser revokes the OAuth2 tokens of a user whose auth credentials are
+// revoked, e.g. on a password change or a logout from every device.
+func RevokeUser(db *gorm.DB, userID uint64, now time.Time) error {
+	return repository.NewTokenRepository(db).RevokeUser(userID, now)
+}
+
+// GetModule is registered after the auth module, whose users it references.
+func GetModule() *management.Module {
+	return &management.Module{
+		Name:       "oauth2",
+		Models:     GetModels(),
+		Migrations: GetMigrations(),
+		Commands:   GetCommands(),
+
+		UserRevoker: RevokeUser,
+	}
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"grf/core/repository"
+	"grf/domain/oauth2/model"
+
+	"gorm.io/gorm"
+)
+
+type ClientRepository struct {
+	repository.IRepository[*model.Client, uint64]
+
+	DB *gorm.DB
+}
+
+func NewClientRepository(db *gorm.DB) *ClientRepository {
+	return &ClientRepository{
+		IRepository: repository.NewGenericRepository(&repository.Config[*model.Client, uint64]{
+			DB: db,
+			NewModel: func() *model.Client {
+				return new(model.Client)
+			},
+		}),
+		DB: db,
+	}
+}
+
+// FindByClientID looks a client up by its public client_id, with its owner.
+func (r *ClientRepository) FindByClientID(clientID string) (*model.Client, error) {
+	var client model.Client
+	if err := r.DB.Preload("User").Where("client_id = ?", clientID).First(&client).Error; err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
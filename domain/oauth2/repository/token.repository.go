@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"grf/domain/oauth2/model"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TokenRepository stores the authorization codes and the issued tokens.
+type TokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *TokenRepository {
+	return &TokenRepository{DB: db}
+}
+
+func (r *TokenRepository) CreateCode(code *model.AuthorizationCode) error {
+	return r.DB.Create(code).Error
+}
+
+// ConsumeCode deletes the code issued to the client and returns it, with
+// its user. Of two concurrent exchanges of the same code only one finds it,
+// and another client's attempt leaves it in place.
+func (r *TokenRepository) ConsumeCode(hashedCode string, clientID uint64) (*model.AuthorizationCode, error) {
+	var code model.AuthorizationCode
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Preload("User").
+			Where("hashed_code = ? AND client_id = ?", hashedCode, clientID).
+			First(&code).Error
+		if err != nil {
+			return err
+		}
+		result := tx.Delete(&model.AuthorizationCode{}, code.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+func (r *TokenRepository) Create(token *model.Token) error {
+	return r.DB.Create(token).Error
+}
+
+// FindByAccessToken looks a token up by the hash of its access token, with
+// its user and client.
+func (r *TokenRepository) FindByAccessToken(hashed string) (*model.Token, error) {
+	return r.find("hashed_access_token = ?", hashed)
+}
+
+func (r *TokenRepository) FindByRefreshToken(hashed string) (*model.Token, error) {
+	return r.find("hashed_refresh_token = ?", hashed)
+}
+
+func (r *TokenRepository) find(query string, hashed string) (*model.Token, error) {
+	var token model.Token
+	if err := r.DB.Preload("User").Preload("Client").Where(query, hashed).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Revoke reports false when the token was already revoked, which lets two
+// concurrent refreshes with the same token be told apart.
+func (r *TokenRepository) Revoke(token *model.Token, now time.Time) (bool, error) {
+	result := r.DB.Model(&model.Token{}).
+		Where("id = ? AND revoked_at IS NULL", token.ID).
+		UpdateColumn("revoked_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false, result.Error
+	}
+	token.RevokedAt = &now
+	return true, nil
+}
+
+// RevokeUser revokes every token of the user not revoked yet.
+func (r *TokenRepository) RevokeUser(userID uint64, now time.Time) error {
+	return r.DB.Model(&model.Token{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		UpdateColumn("revoked_at", now).Error
+}
+
+// DeleteExpired removes the codes expired before now and the tokens whose
+// access and refresh tokens both are.
+func (r *TokenRepository) DeleteExpired(now time.Time) (codes int64, tokens int64, err error) {
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("expires_at <= ?", now).Delete(&model.AuthorizationCode{})
+		if result.Error != nil {
+			return result.Error
+		}
+		codes = result.RowsAffected
+
+		result = tx.Where("expires_at <= ? AND (refresh_expires_at IS NULL OR refresh_expires_at <= ?)", now, now).
+			Delete(&model.Token{})
+		tokens = result.RowsAffected
+		return result.Error
+	})
+	return codes, tokens, err
+}
@@ -0,0 +1,469 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"grf/core/config"
+	authmodel "grf/domain/auth/model"
+	"grf/domain/oauth2/dto"
+	"grf/domain/oauth2/model"
+	"grf/domain/oauth2/repository"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+var ErrTokenInvalid = errors.New("oauth2 token invalid, expired or revoked")
+
+// OAuthError is answered in the format of RFC 6749, section 5.2, rather than
+// through the app's error handler, so standard clients can read it.
+type OAuthError struct {
+	Status      int
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func newOAuthError(status int, code string, description string) *OAuthError {
+	return &OAuthError{Status: status, Code: code, Description: description}
+}
+
+func invalidRequest(description string) *OAuthError {
+	return newOAuthError(fiber.StatusBadRequest, "invalid_request", description)
+}
+
+func invalidClient(description string) *OAuthError {
+	return newOAuthError(fiber.StatusUnauthorized, "invalid_client", description)
+}
+
+func invalidGrant(description string) *OAuthError {
+	return newOAuthError(fiber.StatusBadRequest, "invalid_grant", description)
+}
+
+func unauthorizedClient(description string) *OAuthError {
+	return newOAuthError(fiber.StatusBadRequest, "unauthorized_client", description)
+}
+
+// AccessDenied is the error sent to the client when the user declines.
+func AccessDenied() *OAuthError {
+	return newOAuthError(fiber.StatusForbidden, "access_denied", "the user denied the request")
+}
+
+var (
+	// scopeToken is the scope-token syntax of RFC 6749, section 3.3.
+	scopeToken = regexp.MustCompile(`^[\x21\x23-\x5B\x5D-\x7E]+$`)
+	// pkceValue is the syntax of PKCE verifiers, which S256 challenges also
+	// fit: 43 to 128 unreserved characters (RFC 7636, section 4.1).
+	pkceValue = regexp.MustCompile(`^[A-Za-z0-9\-._~]{43,128}$`)
+)
+
+// OAuth2Service implements the authorization and token endpoints. Clients
+// get opaque tokens, stored hashed, that auth.OAuth2AuthBackend accepts.
+type OAuth2Service struct {
+	Config  *config.Config
+	Clients *repository.ClientRepository
+	Tokens  *repository.TokenRepository
+}
+
+func NewOAuth2Service(db *gorm.DB, config *config.Config) *OAuth2Service {
+	return &OAuth2Service{
+		Config:  config,
+		Clients: repository.NewClientRepository(db),
+		Tokens:  repository.NewTokenRepository(db),
+	}
+}
+
+// AuthenticateClient checks the credentials of a client. Confidential
+// clients need their secret, public clients identify with client_id alone.
+func (s *OAuth2Service) AuthenticateClient(clientID string, secret string) (*model.Client, error) {
+	if clientID == "" {
+		return nil, invalidClient("client authentication required")
+	}
+	client, err := s.Clients.FindByClientID(clientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidClient("unknown client")
+		}
+		return nil, err
+	}
+	// A soft-deleted owner isn't preloaded.
+	if client.User == nil {
+		return nil, invalidClient("unknown client")
+	}
+	if client.IsPublic() {
+		if secret != "" {
+			return nil, invalidClient("public clients have no secret")
+		}
+	} else if !client.CheckSecret(secret) {
+		return nil, invalidClient("invalid client secret")
+	}
+	return client, nil
+}
+
+// AuthorizationRequest is an authorization request whose client and
+// redirect URI were checked.
+type AuthorizationRequest struct {
+	Client      *model.Client
+	RedirectURI string
+	// RedirectURIGiven tells whether the request named RedirectURI, rather
+	// than falling back to the only one registered.
+	RedirectURIGiven bool
+	Scopes           []string
+	State            string
+	CodeChallenge    string
+}
+
+// ValidateAuthorization checks an authorization request. Errors about the
+// client or redirect URI come with a nil request and must be shown to the
+// user; later errors come with the request, to be sent to its redirect URI.
+func (s *OAuth2Service) ValidateAuthorization(input *dto.AuthorizeDTO) (*AuthorizationRequest, error) {
+	if input.ClientID == "" {
+		return nil, invalidRequest("client_id is required")
+	}
+	client, err := s.Clients.FindByClientID(input.ClientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidRequest("unknown client_id")
+		}
+		return nil, err
+	}
+
+	redirectURI := input.RedirectURI
+	if redirectURI == "" {
+		uris := client.RedirectURIList()
+		if len(uris) != 1 {
+			return nil, invalidRequest("redirect_uri is required")
+		}
+		redirectURI = uris[0]
+	} else if !client.AllowsRedirectURI(redirectURI) {
+		return nil, invalidRequest("redirect_uri is not registered for the client")
+	}
+
+	req := &AuthorizationRequest{
+		Client:           client,
+		RedirectURI:      redirectURI,
+		RedirectURIGiven: input.RedirectURI != "",
+		State:            input.State,
+	}
+	if input.ResponseType != "code" {
+		return req, newOAuthError(fiber.StatusBadRequest, "unsupported_response_type", "response_type must be code")
+	}
+	if !client.AllowsGrant(model.GrantAuthorizationCode) {
+		return req, unauthorizedClient("the client can't use the authorization code grant")
+	}
+	if req.Scopes, err = resolveScopes(client.ScopeList(), input.Scope); err != nil {
+		return req, err
+	}
+	if input.CodeChallenge == "" {
+		return req, invalidRequest("code_challenge is required (PKCE)")
+	}
+	if input.CodeChallengeMethod != "S256" {
+		return req, invalidRequest("code_challenge_method must be S256")
+	}
+	if !pkceValue.MatchString(input.CodeChallenge) {
+		return req, invalidRequest("malformed code_challenge")
+	}
+	req.CodeChallenge = input.CodeChallenge
+	return req, nil
+}
+
+// Authorize issues a code for the user who approved req and returns the
+// redirect carrying it.
+func (s *OAuth2Service) Authorize(req *AuthorizationRequest, user *authmodel.User) (string, error) {
+	code := &model.AuthorizationCode{
+		ClientID:         req.Client.ID,
+		UserID:           user.ID,
+		RedirectURI:      req.RedirectURI,
+		RedirectURIGiven: req.RedirectURIGiven,
+		Scopes:           model.JoinScopes(req.Scopes),
+		CodeChallenge:    req.CodeChallenge,
+		ExpiresAt:        time.Now().Add(time.Duration(s.Config.OAuth2CodeSeconds) * time.Second),
+	}
+	code.GenerateCode()
+	if err := s.Tokens.CreateCode(code); err != nil {
+		return "", err
+	}
+	return req.Redirect(url.Values{"code": {code.Code}}), nil
+}
+
+// Redirect returns the redirect URI with params and the state added to its
+// query.
+func (r *AuthorizationRequest) Redirect(params url.Values) string {
+	if r.State != "" {
+		params.Set("state", r.State)
+	}
+	// Redirect URIs are validated as URLs when registered.
+	u, _ := url.Parse(r.RedirectURI)
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// ErrorRedirect returns the redirect reporting err to the client.
+func (r *AuthorizationRequest) ErrorRedirect(err *OAuthError) string {
+	params := url.Values{"error": {err.Code}}
+	if err.Description != "" {
+		params.Set("error_description", err.Description)
+	}
+	return r.Redirect(params)
+}
+
+// Token answers a token request of an authenticated client.
+func (s *OAuth2Service) Token(client *model.Client, input *dto.TokenRequestDTO) (*dto.TokenResponseDTO, error) {
+	switch input.GrantType {
+	case "":
+		return nil, invalidRequest("grant_type is required")
+	case model.GrantAuthorizationCode, model.GrantClientCredentials, model.GrantRefreshToken:
+	default:
+		return nil, newOAuthError(fiber.StatusBadRequest, "unsupported_grant_type", fmt.Sprintf("grant_type %q is not supported", input.GrantType))
+	}
+	if !client.AllowsGrant(input.GrantType) {
+		return nil, unauthorizedClient(fmt.Sprintf("the client can't use the %s grant", input.GrantType))
+	}
+
+	switch input.GrantType {
+	case model.GrantAuthorizationCode:
+		return s.exchangeCode(client, input)
+	case model.GrantClientCredentials:
+		return s.clientCredentials(client, input)
+	default:
+		return s.refresh(client, input)
+	}
+}
+
+func (s *OAuth2Service) exchangeCode(client *model.Client, input *dto.TokenRequestDTO) (*dto.TokenResponseDTO, error) {
+	if input.Code == "" || input.CodeVerifier == "" {
+		return nil, invalidRequest("code and code_verifier are required")
+	}
+	code, err := s.Tokens.ConsumeCode(model.HashToken(input.Code), client.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidGrant("code invalid or already used")
+		}
+		return nil, err
+	}
+	if !time.Now().Before(code.ExpiresAt) {
+		return nil, invalidGrant("code invalid or expired")
+	}
+	// A redirect_uri named in the authorization request must be repeated
+	// exactly (RFC 6749, section 4.1.3).
+	if (code.RedirectURIGiven || input.RedirectURI != "") && input.RedirectURI != code.RedirectURI {
+		return nil, invalidGrant("redirect_uri does not match the authorization request")
+	}
+	if !verifyCodeChallenge(input.CodeVerifier, code.CodeChallenge) {
+		return nil, invalidGrant("code_verifier does not match the code_challenge")
+	}
+	if code.User == nil || !code.User.IsActive {
+		return nil, invalidGrant("the user is not active")
+	}
+	return s.issue(client, code.UserID, code.ScopeList(), client.AllowsGrant(model.GrantRefreshToken))
+}
+
+// clientCredentials issues a token acting as the client's owner, without a
+// refresh token: the client can always ask for a new one.
+func (s *OAuth2Service) clientCredentials(client *model.Client, input *dto.TokenRequestDTO) (*dto.TokenResponseDTO, error) {
+	if client.IsPublic() {
+		return nil, unauthorizedClient("public clients can't use the client_credentials grant")
+	}
+	scopes, err := resolveScopes(client.ScopeList(), input.Scope)
+	if err != nil {
+		return nil, err
+	}
+	if !client.User.IsActive {
+		return nil, invalidGrant("the client owner is not active")
+	}
+	return s.issue(client, client.UserID, scopes, false)
+}
+
+// refresh rotates a refresh token. The scope may only narrow the original
+// grant.
+func (s *OAuth2Service) refresh(client *model.Client, input *dto.TokenRequestDTO) (*dto.TokenResponseDTO, error) {
+	if input.RefreshToken == "" {
+		return nil, invalidRequest("refresh_token is required")
+	}
+	token, err := s.Tokens.FindByRefreshToken(model.HashToken(input.RefreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidGrant("refresh token invalid, expired or revoked")
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if token.ClientID != client.ID || !token.RefreshActive(now) {
+		return nil, invalidGrant("refresh token invalid, expired or revoked")
+	}
+	if token.User == nil || !token.User.IsActive {
+		return nil, invalidGrant("the user is not active")
+	}
+	scopes, err := resolveScopes(token.ScopeList(), input.Scope)
+	if err != nil {
+		return nil, err
+	}
+	revoked, err := s.Tokens.Revoke(token, now)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		// A concurrent refresh won the race with the same token.
+		return nil, invalidGrant("refresh token invalid, expired or revoked")
+	}
+	return s.issue(client, token.UserID, scopes, true)
+}
+
+func (s *OAuth2Service) issue(client *model.Client, userID uint64, scopes []string, refresh bool) (*dto.TokenResponseDTO, error) {
+	now := time.Now()
+	lifetime := time.Duration(s.Config.OAuth2AccessTokenSeconds) * time.Second
+	token := &model.Token{
+		ClientID:  client.ID,
+		UserID:    userID,
+		Scopes:    model.JoinScopes(scopes),
+		ExpiresAt: now.Add(lifetime),
+	}
+	if refresh {
+		refreshExp := now.Add(time.Hour * 24 * time.Duration(s.Config.OAuth2RefreshTokenDays))
+		token.RefreshExpiresAt = &refreshExp
+	}
+	token.GenerateTokens(refresh)
+	if err := s.Tokens.Create(token); err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenResponseDTO{
+		AccessToken:  token.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(lifetime.Seconds()),
+		RefreshToken: token.RefreshToken,
+		Scope:        token.Scopes,
+	}, nil
+}
+
+// ValidateAccessToken returns the token of a bearer access token, with its
+// user. Whether the user is active is left to permission.IsAuthenticated.
+func (s *OAuth2Service) ValidateAccessToken(accessToken string) (*model.Token, error) {
+	token, err := s.Tokens.FindByAccessToken(model.HashToken(accessToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTokenInvalid
+		}
+		return nil, err
+	}
+	// The user or client may have been deleted since.
+	if token.User == nil || token.Client == nil || !token.AccessActive(time.Now()) {
+		return nil, ErrTokenInvalid
+	}
+	return token, nil
+}
+
+// Introspect describes a token to a resource server (RFC 7662). Any
+// confidential client may introspect, as resource servers are registered as
+// such. Unknown, expired and revoked tokens are all just inactive.
+func (s *OAuth2Service) Introspect(client *model.Client, value string) (*dto.IntrospectionResponseDTO, error) {
+	if client.IsPublic() {
+		return nil, invalidClient("public clients can't introspect tokens")
+	}
+	if value == "" {
+		return nil, invalidRequest("token is required")
+	}
+	token, isRefresh, err := s.lookup(value)
+	if err != nil || token == nil {
+		return &dto.IntrospectionResponseDTO{Active: false}, err
+	}
+
+	now := time.Now()
+	active := token.AccessActive(now)
+	exp := token.ExpiresAt
+	tokenType := "Bearer"
+	if isRefresh {
+		active = token.RefreshActive(now)
+		exp = *token.RefreshExpiresAt
+		tokenType = model.GrantRefreshToken
+	}
+	if !active || token.User == nil || !token.User.IsActive || token.Client == nil {
+		return &dto.IntrospectionResponseDTO{Active: false}, nil
+	}
+
+	return &dto.IntrospectionResponseDTO{
+		Active:    true,
+		Scope:     token.Scopes,
+		ClientID:  token.Client.ClientID,
+		Username:  token.User.Username,
+		TokenType: tokenType,
+		Exp:       exp.Unix(),
+		Iat:       token.CreatedAt.Unix(),
+		Sub:       fmt.Sprintf("%d", token.UserID),
+	}, nil
+}
+
+// Revoke revokes an access or refresh token of client (RFC 7009), and with
+// it the other token of the pair. Unknown tokens are ignored.
+func (s *OAuth2Service) Revoke(client *model.Client, value string) error {
+	if value == "" {
+		return invalidRequest("token is required")
+	}
+	token, _, err := s.lookup(value)
+	if err != nil || token == nil {
+		return err
+	}
+	if token.ClientID != client.ID {
+		return unauthorizedClient("the token was issued to another client")
+	}
+	_, err = s.Tokens.Revoke(token, time.Now())
+	return err
+}
+
+// lookup finds a token by its value. The prefix tells access and refresh
+// tokens apart, so the token_type_hint isn't needed.
+func (s *OAuth2Service) lookup(value string) (token *model.Token, isRefresh bool, err error) {
+	switch {
+	case strings.HasPrefix(value, model.AccessTokenPrefix):
+		token, err = s.Tokens.FindByAccessToken(model.HashToken(value))
+	case strings.HasPrefix(value, model.RefreshTokenPrefix):
+		isRefresh = true
+		token, err = s.Tokens.FindByRefreshToken(model.HashToken(value))
+	default:
+		return nil, false, nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	}
+	return token, isRefresh, err
+}
+
+// resolveScopes checks the requested scope against those allowed. An empty
+// request gets all of them.
+func resolveScopes(allowed []string, requested string) ([]string, error) {
+	scopes := strings.Fields(requested)
+	if len(scopes) == 0 {
+		return allowed, nil
+	}
+	for _, scope := range scopes {
+		if !scopeToken.MatchString(scope) || !slices.Contains(allowed, scope) {
+			return nil, newOAuthError(fiber.StatusBadRequest, "invalid_scope", fmt.Sprintf("scope %q is not allowed", scope))
+		}
+	}
+	return strings.Fields(model.JoinScopes(scopes)), nil
+}
+
+// verifyCodeChallenge checks a PKCE verifier against its S256 challenge.
+func verifyCodeChallenge(verifier string, challenge string) bool {
+	if !pkceValue.MatchString(verifier) {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
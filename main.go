@@ -1,10 +1,12 @@
 package main
 
 import (
-	"grf/core/bootstrap"
 	"grf/core/config"
+	"grf/core/management"
 	"grf/domain/auth"
+	"grf/domain/oauth2"
 	"log"
+	"os"
 )
 
 func main() {
@@ -13,12 +15,11 @@ func main() {
 		log.Fatal(err)
 	}
 
-	var allModels []interface{}
-	allModels = append(allModels, auth.GetModels()...)
-
-	app, err := bootstrap.NewApp(cfg, allModels)
-	if err != nil {
+	cli := management.New(cfg,
+		auth.GetModule(),
+		oauth2.GetModule(),
+	)
+	if err := cli.Run(os.Args[1:]); err != nil {
 		log.Fatal(err)
 	}
-	log.Fatal(app.Start())
 }